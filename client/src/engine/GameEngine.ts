@@ -1,6 +1,6 @@
 import * as THREE from 'three';
 import { BACKEND } from '../config';
-import { ErrorMessage, GameState, PlayerAction } from '../types/game';
+import { ErrorMessage, GameState, KillEvent, StartFailedPayload, HandshakePayload, MatchEndPayload, PlayerAction, PlayerUpdatePayload, PROTOCOL_VERSION } from '../types/game';
 import { GameMap } from './GameMap';
 import { HUD, HUDConfig } from './HUD';
 import { LODManager } from './LODManager';
@@ -15,6 +15,7 @@ export class GameEngine {
   private renderer: THREE.WebGLRenderer;
   private socket!: WebSocket;
   private gameState: GameState;
+  private playerInfo: Map<string, PlayerUpdatePayload> = new Map();
   private lastFrameTime: number;
   private isRunning: boolean;
   private playerId: string | null;
@@ -305,17 +306,83 @@ export class GameEngine {
         this.playerId = initPayload.id || null;
         break;
         
-      case 'playerId':
-        initPayload = data.payload as { id: string };
-        this.playerId = initPayload.id || null;
+      case 'playerId': {
+        const handshake = data.payload as HandshakePayload;
+        this.playerId = handshake.id || null;
+        this.sendMessage('hello', { protocolVersion: PROTOCOL_VERSION });
         if (this.playerId) {
           console.log('Received player ID:', this.playerId);
           this.playerControls.enableControls();
         }
         break;
+      }
         
+      case 'motd': {
+        const motd = data.payload as { message: string };
+        this.hud.showMessage(motd.message, 8000);
+        break;
+      }
+
+      case 'death': {
+        const death = data.payload as KillEvent;
+        let message = death.killerName ? `Killed by ${death.killerName}` : 'Killed by the zone';
+        if (death.cause === 'effect' && !death.killerName) {
+          message = 'You succumbed to your wounds';
+        } else if (death.cause === 'fall' && !death.killerName) {
+          message = 'You fell to your death';
+        } else if (death.cause === 'explosion' && !death.killerName) {
+          message = 'You blew yourself up';
+        }
+        if (!death.killerName && death.lastDamagedBy) {
+          const attacker = this.playerInfo.get(death.lastDamagedBy.attackerId)?.displayName;
+          if (attacker) {
+            message += ` after ${attacker} wounded you`;
+          }
+        }
+        this.hud.showMessage(message, 5000);
+        break;
+      }
+
+      case 'startFailed': {
+        const failed = data.payload as StartFailedPayload;
+        this.hud.showMessage(`Waiting for players: ${failed.current}/${failed.required}`, 5000);
+        break;
+      }
+
+      case 'matchEnd': {
+        const matchEnd = data.payload as MatchEndPayload;
+        if (matchEnd.teams) {
+          const myTeam = this.playerInfo.get(this.playerId ?? '')?.team;
+          const result = !matchEnd.winningTeam ? 'Draw!' : matchEnd.winningTeam === myTeam ? 'Your team wins!' : 'Your team lost';
+          this.hud.showMessage(result, 8000);
+          break;
+        }
+        const placement = matchEnd.standings.find((standing) => standing.id === this.playerId)?.placement;
+        if (placement) {
+          this.hud.showMessage(placement === 1 ? 'Winner winner!' : `You placed #${placement}`, 8000);
+        }
+        break;
+      }
+
+      case 'playerUpdate': {
+        const update = data.payload as PlayerUpdatePayload;
+        this.playerInfo.set(update.playerId, update);
+        break;
+      }
+
       case 'gameState':
         gameStatePayload = data.payload as GameState;
+        // Names arrive in playerUpdate messages, merge them back into the state
+        for (const [id, player] of Object.entries(gameStatePayload.players)) {
+          player.displayName = this.playerInfo.get(id)?.displayName ?? player.displayName;
+        }
+        // Players the server skipped this time are still around, keep them as they last were
+        for (const id of gameStatePayload.deferred ?? []) {
+          const previous = this.gameState.players[id];
+          if (previous) {
+            gameStatePayload.players[id] = previous;
+          }
+        }
         this.gameState = gameStatePayload;
         
         // Synchronize player position with server-side position on initial spawn
@@ -364,6 +431,11 @@ export class GameEngine {
     
     if (error.code === 'NETWORK_ERROR' || error.code === 'CONNECTION_ERROR') {
       this.hud.showError(`Network Error: ${error.message}`);
+    } else if (error.code === 'RATE_LIMITED') {
+      // A shot fired faster than the weapon allows was dropped, nothing the player needs to see
+      return;
+    } else if (error.code === 'unsupportedVersion') {
+      this.hud.showError(`Please reload, this client is out of date: ${error.message}`);
     } else {
       this.hud.showError(`Error: ${error.message}`);
     }
@@ -1,6 +1,6 @@
 import * as THREE from 'three';
 import { Vector3 } from 'three';
-import { PlayerAction } from '../types/game';
+import { PlayerAction, Stance, stanceSpeedMultipliers } from '../types/game';
 import { ShotInfo, WeaponType } from '../types/weapons';
 import { SoundManager } from './SoundManager';
 import { WeaponSystem } from './WeaponSystem';
@@ -15,7 +15,7 @@ interface CollisionInfo {
 
 export class PlayerControls {
   private moveSpeed: number = 6;
-  private sprintSpeed: number = 12;
+  private stance: Stance = 'stand'; // Crouching and going prone slow the player down, sprinting speeds them up
   private superSpeed: number = 30; // Super speed value
   private isSuperSpeed: boolean = false; // Super speed state
   private jumpForce: number = 6.5;
@@ -185,6 +185,14 @@ export class PlayerControls {
     return PlayerControls.rotationVector.set(this.cameraRotation.x, this.cameraRotation.y, 0);
   }
 
+  private setStance(stance: Stance): void {
+    this.stance = stance;
+    this.onAction({
+      type: 'setStance',
+      data: { stance },
+    });
+  }
+
   private onKeyDown(event: KeyboardEvent): void {
     if (!this.pointerLocked || !this.controlsEnabled) return;
     
@@ -222,6 +230,10 @@ export class PlayerControls {
           this.shiftPressCount = 0;
         }
         this.isSprinting = true;
+        // Only standing players break into a sprint, the server checks move speeds by stance
+        if (this.stance === 'stand') {
+          this.setStance('sprint');
+        }
         break;
       case 'Space':
         if (this.canJump) {
@@ -236,6 +248,12 @@ export class PlayerControls {
           });
         }
         break;
+      case 'KeyC':
+        this.setStance(this.stance === 'crouch' ? 'stand' : 'crouch');
+        break;
+      case 'KeyZ':
+        this.setStance(this.stance === 'prone' ? 'stand' : 'prone');
+        break;
       case 'KeyR':
         this.weaponSystem.startReload();
         break;
@@ -282,6 +300,9 @@ export class PlayerControls {
       case 'ShiftLeft':
       case 'ShiftRight':
         this.isSprinting = false;
+        if (this.stance === 'sprint') {
+          this.setStance('stand');
+        }
         break;
       case 'Space':
         this.isJumping = false;
@@ -473,9 +494,8 @@ export class PlayerControls {
     let currentSpeed = this.moveSpeed;
     if (this.isSuperSpeed) {
       currentSpeed = this.superSpeed;
-    } else if (this.isSprinting) {
-      currentSpeed = this.sprintSpeed;
     }
+    currentSpeed *= stanceSpeedMultipliers[this.stance];
     
     // Calculate target velocity based on input
     const targetVelocityX = PlayerControls.moveDirection.x * currentSpeed;
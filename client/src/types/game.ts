@@ -7,17 +7,163 @@ export interface Player {
   position: Vector3;
   rotation: Vector3;
   health: number;
+  // Takes damage from attacks before health, shown as a second bar
+  shield?: number;
   weapon?: WeaponType;
   kills: number;
   deaths: number;
+  score?: number;
   isAlive: boolean;
+  stance?: Stance;
+  effects?: StatusEffect[];
+  // Rounds left in the magazine and in reserve per weapon ID, tracked by the server
+  ammo?: Record<string, number>;
+  reserveAmmo?: Record<string, number>;
+  reloadingFor?: number;
+  // Set by the server while the player is mid-jump, rising or falling at verticalVelocity
+  airborne?: boolean;
+  verticalVelocity?: number;
 }
 
+export type Stance = 'stand' | 'crouch' | 'prone' | 'sprint';
+
+// Move speed of each stance relative to standing - matches stanceProfiles in stance.go
+export const stanceSpeedMultipliers: Record<Stance, number> = {
+  stand: 1.0,
+  crouch: 0.5,
+  prone: 0.25,
+  sprint: 2.0,
+};
+
+export interface StatusEffect {
+  type: 'burn' | 'poison' | string;
+  sourceId?: string;
+  remaining: number;
+  damagePerSecond: number;
+}
+
+// Slow-changing player fields, sent in playerUpdate messages instead of every game state
+// PROTOCOL_VERSION is the wire protocol version this client speaks
+export const PROTOCOL_VERSION = 1;
+
+export interface HandshakePayload {
+  id: string;
+  protocolVersion: number;
+  minProtocolVersion: number;
+  features: string[];
+  // Only on ranked servers, connect with ?reconnect=<token> to reclaim the player after a dropped connection
+  reconnectToken?: string;
+}
+
+// Sent after every server ping to clients that list 'latency' in their hello's features
+export interface LatencyPayload {
+  rttMs: number;
+}
+
+export interface WhoAmIPayload {
+  playerId: string;
+  displayName: string;
+  team?: string;
+  isAlive: boolean;
+}
+
+export interface PlayerUpdatePayload {
+  playerId: string;
+  displayName: string;
+  team?: string;
+  weapons: string[];
+  currentWeapon: string;
+}
+
+export interface MatchStanding {
+  id: string;
+  displayName: string;
+  kills: number;
+  deaths: number;
+  assists: number;
+  score: number;
+  placement?: number;
+}
+
+export type DamageCause = 'weapon' | 'headshot' | 'zone' | 'effect' | 'explosion' | 'fall';
+
+export interface KillEvent {
+  killerId?: string;
+  killerName?: string;
+  victimId: string;
+  victimName: string;
+  weaponId?: string;
+  headshot?: boolean;
+  cause: DamageCause;
+  lastDamagedBy?: { attackerId: string; gameTime: number };
+  assistIds?: string[];
+  gameTime: number;
+}
+
+export interface StartFailedPayload {
+  code: string;
+  message: string;
+  current: number;
+  required: number;
+}
+
+export interface ChatPayload {
+  playerId: string;
+  playerName: string;
+  text: string;
+}
+
+export interface TeamScore {
+  kills: number;
+  score: number;
+}
+
+export interface MatchEndPayload {
+  matchId: string;
+  winnerId?: string;
+  standings: MatchStanding[];
+  winningTeam?: string;
+  mvpId?: string;
+  teams?: Record<string, TeamScore>;
+}
+
+export interface Projectile {
+  id: string;
+  ownerId: string;
+  weaponId: string;
+  position: Vector3;
+  velocity: Vector3;
+}
+
+export type MatchState = 'lobby' | 'warmup' | 'active' | 'ending' | 'ended';
+
 export interface GameState {
   players: { [id: string]: Player };
   gameTime: number;
+  matchState?: MatchState;
+  // Mirrors matchState === 'active'
   isGameActive: boolean;
   matchId: string;
+  projectiles?: { [id: string]: Projectile };
+  teams?: Record<string, TeamScore>;
+  // ID of the last match's winner, until the next match starts
+  winner?: string;
+  // Players left out of this state who are still in the game
+  deferred?: string[];
+  zone?: SafeZone;
+}
+
+// The circle players have to stay in, closing in from startRadius to targetRadius between the
+// shrinkStart and shrinkEnd game times
+export interface SafeZone {
+  center: Vector3;
+  radius: number;
+  phase: number;
+  damagePerSecond: number;
+  startRadius: number;
+  targetRadius: number;
+  shrinkStart: number;
+  shrinkEnd: number;
 }
 
 export interface PlayerActionData {
@@ -31,9 +177,10 @@ export interface PlayerActionData {
   hitPoint?: Vector3;
   hitDistance?: number;
   damage?: number;     // Weapon damage amount
+  stance?: Stance;
 }
 
-export type PlayerActionType = 'move' | 'jump' | 'shoot' | 'reload' | 'switchWeapon';
+export type PlayerActionType = 'move' | 'jump' | 'shoot' | 'reload' | 'switchWeapon' | 'setStance';
 
 export interface PlayerAction {
   type: PlayerActionType;
@@ -47,6 +194,9 @@ export type GameMessageType =
   | 'gameState'
   | 'playerAction'
   | 'setName'
+  | 'hello'
+  | 'whoAmI'
+  | 'latency'
   | 'error';
 
 export interface GameMessage {
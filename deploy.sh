@@ -13,7 +13,9 @@ echo "===== Final Circle Backend Deployment ====="
 # Step 1: Build the Go application locally
 echo "Building Go application locally..."
 cd $LOCAL_SERVER_DIR
-GOOS=linux GOARCH=amd64 go build -o final-circle-server main.go
+GOOS=linux GOARCH=amd64 go build \
+  -ldflags "-X main.version=$(git describe --tags --always --dirty) -X main.build=$(git rev-parse --short HEAD)" \
+  -o final-circle-server .
 cd ..
 
 # Step 2: Check if remote directory exists, create if not
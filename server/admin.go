@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"finalcircle/server/game"
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// requireAdmin only lets requests carrying the admin token through. Without a configured token
+// the admin API is open in development and disabled in production.
+func (gs *GameServer) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := gs.config.AdminToken
+		if token == "" {
+			if !gs.config.IsDevelopment {
+				writeJSONError(w, http.StatusForbidden, "adminDisabled", "Admin API disabled, set ADMIN_TOKEN to enable it", nil)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logger.WarningLogger.Printf("Rejected admin request %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized", "Unauthorized", nil)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// registerPprof mounts the runtime profiling handlers under /debug/pprof behind the admin token
+func (gs *GameServer) registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", gs.requireAdmin(pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", gs.requireAdmin(pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", gs.requireAdmin(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", gs.requireAdmin(pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", gs.requireAdmin(pprof.Trace))
+	logger.InfoLogger.Printf("Profiling endpoints enabled under /debug/pprof")
+}
+
+// handleAdminPlayerAction runs an admin action on the player named in the path of the request
+func (gs *GameServer) handleAdminPlayerAction(action func(sm *game.StateManager, playerID string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		room, ok := gs.roomFromRequest(r)
+		if !ok {
+			writeAPIError(w, types.ErrRoomNotFound)
+			return
+		}
+
+		if err := action(room.stateManager, r.PathValue("id")); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		go gs.broadcastGameState(room)
+	}
+}
+
+// handleAdminTeleport moves a player to the position in the request body and responds with where they ended up
+func (gs *GameServer) handleAdminTeleport(w http.ResponseWriter, r *http.Request) {
+	room, ok := gs.roomFromRequest(r)
+	if !ok {
+		writeAPIError(w, types.ErrRoomNotFound)
+		return
+	}
+
+	var target types.Vector3
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		writeAPIError(w, types.ErrInvalidPosition)
+		return
+	}
+
+	position, err := room.stateManager.TeleportPlayer(r.PathValue("id"), target)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(position)
+	go gs.broadcastGameState(room)
+}
+
+// handleAdminMute mutes a player's chat, for the duration in the ?duration= query parameter (e.g. "10m")
+// or until unmuted when it is missing
+func (gs *GameServer) handleAdminMute(w http.ResponseWriter, r *http.Request) {
+	var duration time.Duration
+	if value := r.URL.Query().Get("duration"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalidDuration", "Invalid duration", nil)
+			return
+		}
+		duration = parsed
+	}
+
+	gs.handleAdminPlayerAction(func(sm *game.StateManager, playerID string) error {
+		return sm.MutePlayer(playerID, duration)
+	})(w, r)
+}
+
+// handleAdminDrain puts the server in drain mode, see drain
+func (gs *GameServer) handleAdminDrain(w http.ResponseWriter, r *http.Request) {
+	gs.drain()
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleDebugState dumps a room's full state, including what is never broadcast, as indented JSON
+func (gs *GameServer) handleDebugState(w http.ResponseWriter, r *http.Request) {
+	room, ok := gs.roomFromRequest(r)
+	if !ok {
+		writeAPIError(w, types.ErrRoomNotFound)
+		return
+	}
+
+	data, err := json.MarshalIndent(room.stateManager.DebugState(), "", "  ")
+	if err != nil {
+		logger.ErrorLogger.Printf("Error encoding debug state of room %s: %v", room.ID, err)
+		writeJSONError(w, http.StatusInternalServerError, "internalError", "Failed to encode state", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
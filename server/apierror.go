@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"finalcircle/server/types"
+)
+
+// apiErrors maps the errors API handlers run into to their HTTP status and error code
+var apiErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{types.ErrPlayerNotFound, http.StatusNotFound, "playerNotFound"},
+	{types.ErrRoomNotFound, http.StatusNotFound, "roomNotFound"},
+	{types.ErrMatchNotFound, http.StatusNotFound, "matchNotFound"},
+	{types.ErrPlayerDead, http.StatusConflict, "playerDead"},
+	{types.ErrRoomExists, http.StatusConflict, "roomExists"},
+	{types.ErrNotEnoughPlayers, http.StatusConflict, "notEnoughPlayers"},
+	{types.ErrInvalidPosition, http.StatusBadRequest, "invalidPosition"},
+	{types.ErrInvalidRoomConfig, http.StatusBadRequest, "invalidRoomConfig"},
+	{types.ErrTooManyRooms, http.StatusServiceUnavailable, "tooManyRooms"},
+}
+
+// writeJSONError responds with the {code, message, details} envelope the WebSocket error messages use
+func writeJSONError(w http.ResponseWriter, status int, code, message string, details interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(types.ErrorMessage{Code: code, Message: message, Details: details})
+}
+
+// writeAPIError responds to err with its status and code, and the player counts when a match
+// couldn't start for lack of players. Unknown errors are bad requests.
+func writeAPIError(w http.ResponseWriter, err error) {
+	status, code := http.StatusBadRequest, "badRequest"
+	for _, known := range apiErrors {
+		if errors.Is(err, known.err) {
+			status, code = known.status, known.code
+			break
+		}
+	}
+
+	var details interface{}
+	var notEnough *types.NotEnoughPlayersError
+	if errors.As(err, &notEnough) {
+		details = map[string]int{"current": notEnough.Current, "required": notEnough.Required}
+	}
+	writeJSONError(w, status, code, err.Error(), details)
+}
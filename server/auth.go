@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"finalcircle/server/config"
+)
+
+var (
+	errMissingToken = errors.New("missing auth token")
+	errInvalidToken = errors.New("invalid auth token")
+	errExpiredToken = errors.New("auth token expired")
+)
+
+// tokenVerifier validates the JWTs players connect with, signed either with a shared secret (HS256)
+// or with the private half of a configured RSA public key (RS256)
+type tokenVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	now       func() time.Time
+}
+
+// newTokenVerifier creates a verifier from the configured secret or public key, or returns nil
+// when neither is set and anonymous play is allowed
+func newTokenVerifier(cfg *config.Config) (*tokenVerifier, error) {
+	if cfg.JWTSecret == "" && cfg.JWTPublicKeyFile == "" {
+		return nil, nil
+	}
+
+	verifier := &tokenVerifier{secret: []byte(cfg.JWTSecret), now: time.Now}
+	if cfg.JWTPublicKeyFile != "" {
+		key, err := loadRSAPublicKey(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.publicKey = key
+	}
+	return verifier, nil
+}
+
+// loadRSAPublicKey reads a PEM encoded RSA public key
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s holds no PEM block", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an RSA public key", path)
+	}
+	return rsaKey, nil
+}
+
+// tokenFromRequest takes the token from the Authorization header, or from the token query
+// parameter for browsers that can't set headers on a WebSocket
+func tokenFromRequest(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// verify checks a token's signature and expiry and returns its subject, the player's account ID
+func (v *tokenVerifier) verify(token string) (string, error) {
+	if token == "" {
+		return "", errMissingToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return "", errInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errInvalidToken
+	}
+	if !v.validSignature(header.Alg, parts[0]+"."+parts[1], signature) {
+		return "", errInvalidToken
+	}
+
+	var claims struct {
+		Subject   string `json:"sub"`
+		ExpiresAt *int64 `json:"exp"`
+		NotBefore *int64 `json:"nbf"`
+	}
+	if err := decodeTokenPart(parts[1], &claims); err != nil || claims.Subject == "" {
+		return "", errInvalidToken
+	}
+	now := v.now().Unix()
+	if claims.ExpiresAt != nil && now >= *claims.ExpiresAt {
+		return "", errExpiredToken
+	}
+	if claims.NotBefore != nil && now < *claims.NotBefore {
+		return "", errInvalidToken
+	}
+	return claims.Subject, nil
+}
+
+// validSignature checks the signature with the key matching alg. Only algorithms the server has
+// a key for are accepted, so a token can't pick "none" or switch to the shared secret
+func (v *tokenVerifier) validSignature(alg, signed string, signature []byte) bool {
+	switch alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return false
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(signature, mac.Sum(nil))
+	case "RS256":
+		if v.publicKey == nil {
+			return false
+		}
+		digest := sha256.Sum256([]byte(signed))
+		return rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// decodeTokenPart decodes a base64url encoded JSON part of a token into v
+func decodeTokenPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync/atomic"
+)
+
+// connectionStats counts the traffic of one connection, it goes away with the client when it disconnects
+type connectionStats struct {
+	bytesSent        atomic.Int64
+	bytesReceived    atomic.Int64
+	messagesSent     atomic.Int64
+	messagesReceived atomic.Int64
+}
+
+// recordSent counts messages written to the connection in a frame of the given size
+func (s *connectionStats) recordSent(bytes, messages int) {
+	s.bytesSent.Add(int64(bytes))
+	s.messagesSent.Add(int64(messages))
+}
+
+// recordReceived counts a message read from the connection
+func (s *connectionStats) recordReceived(bytes int) {
+	s.bytesReceived.Add(int64(bytes))
+	s.messagesReceived.Add(1)
+}
+
+// ClientBandwidth is the traffic of a connected player or observer
+type ClientBandwidth struct {
+	ID               string `json:"id"`
+	Room             string `json:"room"`
+	Observer         bool   `json:"observer"`
+	BytesSent        int64  `json:"bytesSent"`
+	BytesReceived    int64  `json:"bytesReceived"`
+	MessagesSent     int64  `json:"messagesSent"`
+	MessagesReceived int64  `json:"messagesReceived"`
+}
+
+// bandwidthOf reports a client's traffic so far
+func bandwidthOf(client *WebsocketClient) ClientBandwidth {
+	return ClientBandwidth{
+		ID:               client.ID,
+		Room:             client.GameID,
+		Observer:         client.IsObserver,
+		BytesSent:        client.stats.bytesSent.Load(),
+		BytesReceived:    client.stats.bytesReceived.Load(),
+		MessagesSent:     client.stats.messagesSent.Load(),
+		MessagesReceived: client.stats.messagesReceived.Load(),
+	}
+}
+
+// clientBandwidth lists the traffic of every connection, the most expensive clients first
+func (gs *GameServer) clientBandwidth() []ClientBandwidth {
+	usage := []ClientBandwidth{}
+
+	gs.clientsMu.RLock()
+	for _, client := range gs.clients {
+		usage = append(usage, bandwidthOf(client))
+	}
+	gs.clientsMu.RUnlock()
+
+	gs.observersMu.RLock()
+	for _, observer := range gs.observers {
+		usage = append(usage, bandwidthOf(observer))
+	}
+	gs.observersMu.RUnlock()
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].BytesSent != usage[j].BytesSent {
+			return usage[i].BytesSent > usage[j].BytesSent
+		}
+		return usage[i].ID < usage[j].ID
+	})
+	return usage
+}
+
+// handleClientBandwidth reports per-connection byte and message counts, to spot clients that cost the most
+func (gs *GameServer) handleClientBandwidth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gs.clientBandwidth())
+}
@@ -1,7 +1,37 @@
 package config
 
 import (
+	"log"
+	"math"
 	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxPlayers is the player capacity used when MAX_PLAYERS is not set
+	DefaultMaxPlayers = 50
+	// MaxPlayersCeiling is the highest player capacity a server may be configured with
+	MaxPlayersCeiling = 500
+	// DefaultTickRate is the number of game updates per second used when TICK_RATE is not set
+	DefaultTickRate = 20
+	// MaxTickRate is the highest tick rate a server or room may be configured with
+	MaxTickRate = 120
+
+	// DefaultMaxMessageSize is the largest client message in bytes accepted when MAX_MESSAGE_SIZE is not set
+	DefaultMaxMessageSize = 512 * 1024
+
+	// SlowClientDisconnect and SlowClientDrop are the policies for clients that can't keep up with broadcasts
+	SlowClientDisconnect = "disconnect"
+	SlowClientDrop       = "drop"
+
+	// DefaultWorldRadius is the play area radius used when WORLD_RADIUS is not set - matches the client's GameMap.ts
+	DefaultWorldRadius = 800.0
+	// MinWorldRadius is the smallest play area radius a server may be configured with, anything smaller
+	// leaves no room for players to move inside the ring wall
+	MinWorldRadius = 10.0
+	// DefaultMaxMoveSpeed is the move speed limit used when MAX_MOVE_SPEED is not set - matches the game package
+	DefaultMaxMoveSpeed = 8.0
 )
 
 // Config holds all server configuration
@@ -11,6 +41,169 @@ type Config struct {
 	UseTLS        bool
 	CertFile      string
 	KeyFile       string
+
+	// MaxPlayers is the number of player slots on the server
+	MaxPlayers int
+	// WorldRadius is the radius of the play area, it should match the ring wall of the client's map
+	WorldRadius float64
+	// TickRate is the number of game updates per second
+	TickRate int
+	// MinPlayers is the number of players needed to start a match
+	MinPlayers int
+	// AutoStart starts matches once MinPlayers are connected instead of waiting for /api/game/start
+	AutoStart bool
+	// AutoStartWarmup is how long enough players wait in the lobby before an auto-started match begins
+	AutoStartWarmup time.Duration
+	// NameCollisionPolicy decides what happens to names already in use ("allow", "reject" or "suffix")
+	NameCollisionPolicy string
+	// NameChangeCooldown is the minimum time between two name changes of a player
+	NameChangeCooldown time.Duration
+	// GameMode is the ruleset matches are played with ("elimination", "deathmatch" or "duel")
+	GameMode string
+	// Ranked holds the place of players whose connection drops for DisconnectGrace so they can reconnect,
+	// casual servers remove them right away
+	Ranked          bool
+	DisconnectGrace time.Duration
+	// MaxViewDistance leaves players farther away than this out of the game state each player receives, 0 sends everyone
+	MaxViewDistance float64
+	// PriorityRadius sends players within it, or fighting the receiving player, in every broadcast and everyone
+	// else only every DistantUpdateEvery broadcasts, 0 sends everyone every time
+	PriorityRadius     float64
+	DistantUpdateEvery int
+	// LateJoin decides whether players joining mid-round "spawn" right away or "spectate" until the next round,
+	// deathmatch always spawns them
+	LateJoin string
+	// TeamCount splits players into that many teams, 0 for free-for-all. TeamWinTarget ends team matches once
+	// a team reaches that many kills (or points when ranking by score) and TeamTimeLimit once it runs out.
+	TeamCount     int
+	TeamWinTarget int
+	TeamTimeLimit time.Duration
+	// MaxMatchDuration force-ends matches that run longer as a safety net, 0 disables it
+	MaxMatchDuration time.Duration
+	// RankBy decides whether the leaderboard and match winner go by "kills" or "score"
+	RankBy string
+	// ScoreKill, ScoreHeadshotBonus, ScoreAssist, ScoreObjective and ScoreZoneKill are the scoring table
+	ScoreKill          int
+	ScoreHeadshotBonus int
+	ScoreAssist        int
+	ScoreObjective     int
+	ScoreZoneKill      int
+	// RespawnSeconds is how long dead players wait to respawn in respawn modes, 0 respawns instantly
+	RespawnSeconds float64
+	// RespawnWaveSeconds respawns dead players together in waves this many seconds apart instead, 0 disables waves
+	RespawnWaveSeconds float64
+	// SpawnProtectionSeconds is how long respawned players are invulnerable
+	SpawnProtectionSeconds float64
+	// SpectatorView decides whether spectators and observers see every player ("all") or only the
+	// players near whoever they follow ("nearby")
+	SpectatorView string
+	// HitRegMode is "server" to raycast every shot on the server, or "client" to accept the hits clients
+	// report after a looser server check
+	HitRegMode string
+	// HeadshotMultiplier and LegshotMultiplier scale the damage of shots hitting the head or the legs
+	HeadshotMultiplier float64
+	LegshotMultiplier  float64
+	// MaxAimDeviationDegrees rejects shots pointing further than that from where the shooter faces, 0 disables it
+	MaxAimDeviationDegrees float64
+	// SelfDamage lets players' own explosives hurt them
+	SelfDamage bool
+	// FallDamage hurts players who land faster than FallDamageSpeed units per second, FallDamageScale
+	// damage for each unit per second beyond it. A 4 unit drop lands at about 10 units per second.
+	FallDamage      bool
+	FallDamageSpeed float64
+	FallDamageScale float64
+	// HealthRegen heals players who go HealthRegenDelay without taking damage, HealthRegenRate points
+	// per second. It defaults to on in deathmatch only, elimination and duels play without it.
+	HealthRegen      bool
+	HealthRegenDelay time.Duration
+	HealthRegenRate  float64
+	// EnforceFireRate rejects shots fired faster than the weapon allows, FireRateTolerance earlier
+	// than its fire interval still passing to allow for network jitter
+	EnforceFireRate   bool
+	FireRateTolerance time.Duration
+	// SpawnCampWindow is how far back repeated deaths near one spot push a player's respawn away from it
+	SpawnCampWindow time.Duration
+	// SupplyDropInterval is how often a supply drop is announced during a match, 0 disables them
+	SupplyDropInterval time.Duration
+	// SupplyDropShield is the shield a supply drop carries besides its weapon
+	SupplyDropShield int
+	// StartingShield is the shield players spawn with and MaxShield the most they can carry, shields
+	// take damage from attacks before health. Only deathmatch and duels hand out a starting shield by
+	// default, elimination players pick theirs up from supply drops
+	StartingShield int
+	MaxShield      int
+
+	// MaxFrameTime is the longest time step one game update simulates after the process stalls
+	MaxFrameTime time.Duration
+	// MaxCatchUpSteps is how many MaxFrameTime steps an update may take to catch up after a stall
+	MaxCatchUpSteps int
+
+	// MaxRooms is the most rooms, the default room included, that may exist at once, 0 for no limit
+	MaxRooms int
+	// EmptyRoomTTL is how long a created room may stay empty before it is removed, 0 keeps empty rooms
+	EmptyRoomTTL time.Duration
+
+	// IdleTickRate is how many updates per second a room runs while it is empty and has no match, 0 keeps the full rate
+	IdleTickRate int
+
+	// AdaptiveBroadcast lowers a room's broadcast rate while its ticks can't keep up with the tick rate
+	AdaptiveBroadcast bool
+	// MinBroadcastRate is the fewest state broadcasts per second adaptive broadcasting goes down to
+	MinBroadcastRate int
+	// MinClientStateInterval is the shortest time between two game states sent to any one client, clients
+	// may ask for a longer one in their hello. Broadcasts in between are skipped for that client. 0 sends every one.
+	MinClientStateInterval time.Duration
+
+	// CoalesceMoves applies only each player's latest move per tick instead of every move as it arrives
+	CoalesceMoves bool
+
+	// MOTD is the message of the day sent to clients when they connect, empty sends nothing
+	MOTD string
+	// MOTDFile is a file holding the message of the day instead, read on every connect so edits apply right away
+	MOTDFile string
+
+	// MapName is the name of the map the server runs, reported to clients and tooling
+	MapName string
+	// ObstaclesFile is a JSON file with the map's obstacle boxes, empty for an open map
+	ObstaclesFile string
+	// SpawnPointsFile is a JSON file with the map's spawn points and their weights, empty to generate spawn points
+	SpawnPointsFile string
+	// ZonePhasesFile is a JSON file with the safe zone schedule, empty for the default five phases
+	ZonePhasesFile string
+	// ObstacleCollision stops players from moving through obstacles
+	ObstacleCollision bool
+	// MaxMoveSpeed is the fastest in units per second standing players may move horizontally, sprinters
+	// twice that and crouching or prone players less. Faster moves are snapped back, 0 disables the check.
+	MaxMoveSpeed float64
+
+	// SlowClientPolicy decides what happens to clients whose send buffer is full:
+	// "disconnect" drops the client, "drop" skips the state frame and keeps the client
+	SlowClientPolicy string
+
+	// PingInterval is how often connections are pinged to keep them alive and measure their latency
+	PingInterval time.Duration
+
+	// MaxMessageSize is the largest message in bytes a client may send before being disconnected
+	MaxMessageSize int
+
+	// BannedWordsFile lists words masked in names and chat, BannedIPsFile addresses refused a connection,
+	// one per line. POST /api/admin/reload re-reads both.
+	BannedWordsFile string
+	BannedIPsFile   string
+
+	// AdminToken is the bearer token required by the /api/admin endpoints. When empty the admin API
+	// is open in development and disabled in production.
+	AdminToken string
+	// EnablePprof mounts the runtime profiling handlers under /debug/pprof, behind the admin token
+	EnablePprof bool
+
+	// JWTSecret (HS256) or JWTPublicKeyFile (RS256, PEM encoded) make connecting require a signed JWT whose
+	// subject becomes the player ID. With neither set anyone may connect anonymously.
+	JWTSecret        string
+	JWTPublicKeyFile string
+
+	// MaxClockSkew is how far a client message timestamp may drift from server time, 0 disables the check
+	MaxClockSkew time.Duration
 }
 
 // LoadConfig loads the server configuration from environment variables
@@ -29,11 +222,199 @@ func LoadConfig() *Config {
 	keyFile := os.Getenv("TLS_KEY_FILE")
 	useTLS := certFile != "" && keyFile != ""
 
+	gameMode := getEnvOneOf("GAME_MODE", "elimination", "elimination", "deathmatch", "duel")
+
 	return &Config{
-		IsDevelopment: isDevelopment,
-		Port:          port,
-		UseTLS:        useTLS,
-		CertFile:      certFile,
-		KeyFile:       keyFile,
+		IsDevelopment:    isDevelopment,
+		Port:             port,
+		UseTLS:           useTLS,
+		CertFile:         certFile,
+		KeyFile:          keyFile,
+		MaxPlayers:       getEnvIntInRange("MAX_PLAYERS", DefaultMaxPlayers, 1, MaxPlayersCeiling),
+		MaxClockSkew:     getEnvDuration("MAX_CLOCK_SKEW", time.Minute),
+		SlowClientPolicy: getEnvOneOf("SLOW_CLIENT_POLICY", SlowClientDisconnect, SlowClientDisconnect, SlowClientDrop),
+		MaxMessageSize:   getEnvIntInRange("MAX_MESSAGE_SIZE", DefaultMaxMessageSize, 1024, 16*1024*1024),
+		PingInterval:     getEnvDuration("PING_INTERVAL", 30*time.Second),
+		WorldRadius:      getEnvFloatAtLeast("WORLD_RADIUS", DefaultWorldRadius, MinWorldRadius),
+
+		TickRate:               getEnvIntInRange("TICK_RATE", DefaultTickRate, 1, MaxTickRate),
+		MinPlayers:             getEnvIntInRange("MIN_PLAYERS", 2, 2, MaxPlayersCeiling),
+		AutoStart:              getEnvBool("AUTO_START", false),
+		AutoStartWarmup:        getEnvDuration("AUTO_START_WARMUP", 10*time.Second),
+		NameCollisionPolicy:    getEnvOneOf("NAME_COLLISION_POLICY", "allow", "allow", "reject", "suffix"),
+		NameChangeCooldown:     getEnvDuration("NAME_CHANGE_COOLDOWN", 5*time.Second),
+		GameMode:               gameMode,
+		Ranked:                 getEnvBool("RANKED", false),
+		DisconnectGrace:        getEnvDuration("DISCONNECT_GRACE", 30*time.Second),
+		LateJoin:               getEnvOneOf("LATE_JOIN", "spawn", "spawn", "spectate"),
+		MaxViewDistance:        getEnvFloat("MAX_VIEW_DISTANCE", 0),
+		PriorityRadius:         getEnvFloat("PRIORITY_RADIUS", 0),
+		DistantUpdateEvery:     getEnvIntInRange("DISTANT_UPDATE_EVERY", 3, 1, 60),
+		TeamCount:              getEnvIntInRange("TEAM_COUNT", 0, 0, 8),
+		TeamWinTarget:          getEnvIntInRange("TEAM_WIN_TARGET", 0, 0, 10000),
+		TeamTimeLimit:          getEnvDuration("TEAM_TIME_LIMIT", 0),
+		MaxMatchDuration:       getEnvDuration("MAX_MATCH_DURATION", time.Hour),
+		RankBy:                 getEnvOneOf("RANK_BY", "kills", "kills", "score"),
+		ScoreKill:              getEnvIntInRange("SCORE_KILL", 100, 0, 100000),
+		ScoreHeadshotBonus:     getEnvIntInRange("SCORE_HEADSHOT_BONUS", 50, 0, 100000),
+		ScoreAssist:            getEnvIntInRange("SCORE_ASSIST", 50, 0, 100000),
+		ScoreObjective:         getEnvIntInRange("SCORE_OBJECTIVE", 200, 0, 100000),
+		ScoreZoneKill:          getEnvIntInRange("SCORE_ZONE_KILL", 75, 0, 100000),
+		RespawnSeconds:         getEnvFloat("RESPAWN_SECONDS", 3.0),
+		RespawnWaveSeconds:     getEnvFloat("RESPAWN_WAVE_SECONDS", 0),
+		SpawnProtectionSeconds: getEnvFloat("SPAWN_PROTECTION_SECONDS", 2.0),
+		SpectatorView:          getEnvOneOf("SPECTATOR_VIEW", "nearby", "nearby", "all"),
+		HitRegMode:             getEnvOneOf("HIT_REG_MODE", "server", "server", "client"),
+		HeadshotMultiplier:     getEnvFloat("HEADSHOT_MULTIPLIER", 2),
+		LegshotMultiplier:      getEnvFloat("LEGSHOT_MULTIPLIER", 0.75),
+		MaxAimDeviationDegrees: getEnvFloat("MAX_AIM_DEVIATION_DEGREES", 0),
+		SelfDamage:             getEnvBool("SELF_DAMAGE", true),
+		FallDamage:             getEnvBool("FALL_DAMAGE", false),
+		FallDamageSpeed:        getEnvFloat("FALL_DAMAGE_SPEED", 10),
+		FallDamageScale:        getEnvFloat("FALL_DAMAGE_SCALE", 10),
+		HealthRegen:            getEnvBool("HEALTH_REGEN", gameMode == "deathmatch"),
+		HealthRegenDelay:       getEnvDuration("HEALTH_REGEN_DELAY", 5*time.Second),
+		HealthRegenRate:        getEnvFloat("HEALTH_REGEN_RATE", 10),
+		EnforceFireRate:        getEnvBool("ENFORCE_FIRE_RATE", true),
+		FireRateTolerance:      getEnvDuration("FIRE_RATE_TOLERANCE", 30*time.Millisecond),
+		SpawnCampWindow:        getEnvDuration("SPAWN_CAMP_WINDOW", 30*time.Second),
+		SupplyDropInterval:     getEnvDuration("SUPPLY_DROP_INTERVAL", 90*time.Second),
+		SupplyDropShield:       getEnvIntInRange("SUPPLY_DROP_SHIELD", 50, 0, 1000),
+		StartingShield:         getEnvIntInRange("STARTING_SHIELD", map[string]int{"deathmatch": 50, "duel": 50}[gameMode], 0, 1000),
+		MaxShield:              getEnvIntInRange("MAX_SHIELD", 100, 0, 1000),
+
+		MaxFrameTime:    getEnvDuration("MAX_FRAME_TIME", 250*time.Millisecond),
+		MaxCatchUpSteps: getEnvIntInRange("MAX_CATCH_UP_STEPS", 1, 1, 100),
+
+		MaxRooms:     getEnvIntInRange("MAX_ROOMS", 50, 0, 10000),
+		EmptyRoomTTL: getEnvDuration("EMPTY_ROOM_TTL", 5*time.Minute),
+
+		IdleTickRate: getEnvIntInRange("IDLE_TICK_RATE", 1, 0, MaxTickRate),
+
+		AdaptiveBroadcast: getEnvBool("ADAPTIVE_BROADCAST", false),
+		MinBroadcastRate:  getEnvIntInRange("MIN_BROADCAST_RATE", 5, 1, MaxTickRate),
+
+		MinClientStateInterval: getEnvDuration("MIN_CLIENT_STATE_INTERVAL", 0),
+
+		CoalesceMoves: getEnvBool("COALESCE_MOVES", true),
+
+		AdminToken:  os.Getenv("ADMIN_TOKEN"),
+		EnablePprof: getEnvBool("ENABLE_PPROF", false),
+
+		BannedWordsFile: os.Getenv("BANNED_WORDS_FILE"),
+		BannedIPsFile:   os.Getenv("BANNED_IPS_FILE"),
+
+		JWTSecret:        os.Getenv("JWT_SECRET"),
+		JWTPublicKeyFile: os.Getenv("JWT_PUBLIC_KEY_FILE"),
+
+		MOTD:     os.Getenv("MOTD"),
+		MOTDFile: os.Getenv("MOTD_FILE"),
+
+		MapName:           getEnvString("MAP_NAME", "default"),
+		ObstaclesFile:     os.Getenv("OBSTACLES_FILE"),
+		SpawnPointsFile:   os.Getenv("SPAWN_POINTS_FILE"),
+		ZonePhasesFile:    os.Getenv("ZONE_PHASES_FILE"),
+		ObstacleCollision: getEnvBool("OBSTACLE_COLLISION", true),
+		MaxMoveSpeed:      getEnvFloat("MAX_MOVE_SPEED", DefaultMaxMoveSpeed),
+	}
+}
+
+// getEnvString reads a string from the environment, falling back to the default when it's unset
+func getEnvString(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool reads a boolean ("true", "false", "1", "0", ...) from the environment, falling back to the default
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value for %s: %q, using default %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvFloat reads a non-negative number from the environment, falling back to the default
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		log.Printf("Invalid value for %s: %q, using default %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvFloatAtLeast reads a float of at least min from the environment, falling back to the default
+func getEnvFloatAtLeast(key string, defaultValue, min float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < min || math.IsInf(parsed, 0) || math.IsNaN(parsed) {
+		log.Printf("Invalid value for %s: %q (must be at least %v), using default %v", key, value, min, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvOneOf reads one of the allowed values from the environment, falling back to the default
+func getEnvOneOf(key string, defaultValue string, allowed ...string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	for _, option := range allowed {
+		if value == option {
+			return value
+		}
+	}
+
+	log.Printf("Invalid value for %s: %q (must be one of %v), using default %s", key, value, allowed, defaultValue)
+	return defaultValue
+}
+
+// getEnvIntInRange reads an integer in [min, max] from the environment, falling back to the default
+func getEnvIntInRange(key string, defaultValue, min, max int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min || parsed > max {
+		log.Printf("Invalid value for %s: %q (must be %d-%d), using default %d", key, value, min, max, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// getEnvDuration reads a duration (e.g. "30s") from the environment, falling back to the default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Printf("Invalid duration for %s: %q, using default %v", key, value, defaultValue)
+		return defaultValue
 	}
+	return duration
 }
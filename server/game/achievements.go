@@ -0,0 +1,56 @@
+package game
+
+import (
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// Achievement identifiers sent in achievement messages
+const (
+	AchievementKillstreak = "killstreak"
+	AchievementRevenge    = "revenge"
+)
+
+// awardKillAchievements checks the killer for killstreak milestones and revenge, the caller must hold sm.mu
+func (sm *StateManager) awardKillAchievements(killer, victim *types.Player) {
+	for _, milestone := range sm.settings.StreakMilestones {
+		if killer.Killstreak == milestone {
+			sm.awardAchievement(killer, types.AchievementPayload{
+				Achievement: AchievementKillstreak,
+				Streak:      killer.Killstreak,
+			})
+			break
+		}
+	}
+
+	// Killing whoever last killed you is a revenge kill
+	if killer.LastKilledBy != "" && killer.LastKilledBy == victim.ID {
+		killer.LastKilledBy = ""
+		sm.awardAchievement(killer, types.AchievementPayload{
+			Achievement: AchievementRevenge,
+			VictimID:    victim.ID,
+		})
+	}
+}
+
+// awardAchievement announces an achievement unless the player earned the same one too recently
+func (sm *StateManager) awardAchievement(player *types.Player, achievement types.AchievementPayload) {
+	if player.AchievementTimes == nil {
+		player.AchievementTimes = make(map[string]float64)
+	}
+
+	if lastEarned, ok := player.AchievementTimes[achievement.Achievement]; ok &&
+		sm.state.GameTime-lastEarned < sm.settings.AchievementCooldown.Seconds() {
+		logger.DebugLogger.Printf("Achievement %s for player %s suppressed by cooldown", achievement.Achievement, player.ID)
+		return
+	}
+	player.AchievementTimes[achievement.Achievement] = sm.state.GameTime
+
+	achievement.PlayerID = player.ID
+	achievement.PlayerName = player.DisplayName
+	logger.InfoLogger.Printf("ACHIEVEMENT: Player %s (%s) earned %s (streak: %d)",
+		player.ID, player.DisplayName, achievement.Achievement, player.Killstreak)
+
+	// Broadcast so everyone sees it, the payload tells the earning player it's theirs
+	sm.emit(types.MessageTypeAchievement, "", achievement)
+}
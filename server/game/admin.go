@@ -0,0 +1,81 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// RespawnPlayer brings a player back to life at a fresh spawn point right away,
+// eliminated players rejoin the match
+func (sm *StateManager) RespawnPlayer(playerID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+
+	player.IsSpectator = false
+	player.SpectatingID = ""
+	player.Placement = 0
+	sm.respawnPlayer(player)
+	sm.emit(types.MessageTypePositionCorrection, player.ID, types.PositionCorrectionPayload{Position: player.Position})
+
+	logger.InfoLogger.Printf("Player %s respawned by an admin", playerID)
+	return nil
+}
+
+// HealPlayer restores a living player's health to full
+func (sm *StateManager) HealPlayer(playerID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+	if !player.IsAlive {
+		return types.ErrPlayerDead
+	}
+
+	player.Health = 100
+	logger.InfoLogger.Printf("Player %s healed by an admin", playerID)
+	return nil
+}
+
+// TeleportPlayer moves a player to a position, clamped inside the world, and returns where they ended up
+func (sm *StateManager) TeleportPlayer(playerID string, position types.Vector3) (types.Vector3, error) {
+	if !isFinite(position) {
+		return types.Vector3{}, types.ErrInvalidPosition
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.Vector3{}, types.ErrPlayerNotFound
+	}
+
+	player.Position = sm.clampToWorld(position)
+	player.LastMoveTime = time.Now()
+	sm.emit(types.MessageTypePositionCorrection, player.ID, types.PositionCorrectionPayload{Position: player.Position})
+
+	logger.InfoLogger.Printf("Player %s teleported by an admin to (%.2f, %.2f, %.2f)",
+		playerID, player.Position.X, player.Position.Y, player.Position.Z)
+	return player.Position, nil
+}
+
+// isFinite reports whether every component of a vector is a real number
+func isFinite(v types.Vector3) bool {
+	for _, c := range []float64{v.X, v.Y, v.Z} {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return false
+		}
+	}
+	return true
+}
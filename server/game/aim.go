@@ -0,0 +1,46 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// checkAim rejects a shot whose direction or target points away from where the shooter is facing by
+// more than the configured deviation, which a legitimate client can't produce. Only the heading is
+// compared, so looking up or down never trips it. The caller must hold sm.mu.
+func (sm *StateManager) checkAim(shooter *types.Player, data types.PlayerActionData) error {
+	if sm.settings.MaxAimDeviation <= 0 {
+		return nil
+	}
+
+	var aim types.Vector3
+	switch {
+	case data.Direction != nil:
+		aim = *data.Direction
+	case data.Target != nil:
+		eye := eyePosition(shooter)
+		aim = types.Vector3{X: data.Target.X - eye.X, Y: data.Target.Y - eye.Y, Z: data.Target.Z - eye.Z}
+	default:
+		return nil
+	}
+
+	// Shots straight up or down have no heading to compare
+	if math.Hypot(aim.X, aim.Z) < 1e-6 {
+		return nil
+	}
+
+	// A yaw of 0 faces -Z, like the client's camera
+	aimYaw := math.Atan2(-aim.X, -aim.Z)
+	deviation := math.Mod(math.Abs(aimYaw-shooter.Rotation.Y), 2*math.Pi)
+	if deviation > math.Pi {
+		deviation = 2*math.Pi - deviation
+	}
+	if deviation > sm.settings.MaxAimDeviation {
+		logger.WarningLogger.Printf("Rejected shot from player %s: aimed %.0f° away from where they face (allowed %.0f°)",
+			shooter.ID, deviation*180/math.Pi, sm.settings.MaxAimDeviation*180/math.Pi)
+		return types.ErrShotMisaligned
+	}
+	return nil
+}
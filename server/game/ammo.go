@@ -0,0 +1,105 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// reserveMagazines is how many magazines' worth of spare rounds a weapon comes with, like the client's
+const reserveMagazines = 3
+
+// usesAmmo reports whether a weapon fires from a magazine, melee weapons never run dry
+func usesAmmo(weapon Weapon) bool {
+	return weapon.MagazineSize > 0
+}
+
+// loadWeapon gives a player a full magazine and reserve for a weapon
+func loadWeapon(player *types.Player, weaponID string) {
+	weapon, ok := LookupWeapon(weaponID)
+	if !ok || !usesAmmo(weapon) {
+		return
+	}
+	if player.Ammo == nil {
+		player.Ammo = make(map[string]int)
+		player.ReserveAmmo = make(map[string]int)
+	}
+	player.Ammo[weaponID] = weapon.MagazineSize
+	player.ReserveAmmo[weaponID] = weapon.MagazineSize * reserveMagazines
+}
+
+// refillAmmo loads every weapon in a player's inventory and cancels a reload in progress,
+// for players (re)spawning
+func refillAmmo(player *types.Player) {
+	player.Ammo = nil
+	player.ReserveAmmo = nil
+	player.ReloadingFor = 0
+	for _, weaponID := range player.Weapons {
+		loadWeapon(player, weaponID)
+	}
+}
+
+// useAmmo takes the round a shot fires from the magazine, refusing shots mid-reload or from an empty one
+func useAmmo(player *types.Player, weapon Weapon) error {
+	if !usesAmmo(weapon) {
+		return nil
+	}
+	if player.ReloadingFor > 0 {
+		return types.ErrReloading
+	}
+	if player.Ammo[weapon.ID] <= 0 {
+		return types.ErrOutOfAmmo
+	}
+	player.Ammo[weapon.ID]--
+	return nil
+}
+
+// startReload begins reloading the player's current weapon, which takes the weapon's reload time
+func startReload(player *types.Player) error {
+	if player.ReloadingFor > 0 {
+		return types.ErrReloading
+	}
+
+	weapon, ok := LookupWeapon(player.CurrentWeapon)
+	if !ok {
+		return types.ErrUnknownWeapon
+	}
+	if !usesAmmo(weapon) || player.Ammo[weapon.ID] >= weapon.MagazineSize || player.ReserveAmmo[weapon.ID] <= 0 {
+		return types.ErrNothingToReload
+	}
+
+	player.ReloadingFor = weapon.ReloadTime
+	logger.DebugLogger.Printf("Player %s started reloading %s (%d in magazine, %d in reserve)",
+		player.ID, weapon.ID, player.Ammo[weapon.ID], player.ReserveAmmo[weapon.ID])
+	return nil
+}
+
+// cancelReload stops a reload in progress without moving any rounds, e.g. when switching weapons
+func cancelReload(player *types.Player) {
+	if player.ReloadingFor > 0 {
+		logger.DebugLogger.Printf("Player %s cancelled reloading %s", player.ID, player.CurrentWeapon)
+		player.ReloadingFor = 0
+	}
+}
+
+// updateReload counts a reload down and, once it's done, moves rounds from the reserve into the magazine
+func updateReload(player *types.Player, deltaTime float64) {
+	if player.ReloadingFor <= 0 {
+		return
+	}
+	player.ReloadingFor = math.Max(0, player.ReloadingFor-deltaTime)
+	if player.ReloadingFor > 0 {
+		return
+	}
+
+	weapon, ok := LookupWeapon(player.CurrentWeapon)
+	if !ok {
+		return
+	}
+	rounds := min(weapon.MagazineSize-player.Ammo[weapon.ID], player.ReserveAmmo[weapon.ID])
+	player.Ammo[weapon.ID] += rounds
+	player.ReserveAmmo[weapon.ID] -= rounds
+	logger.DebugLogger.Printf("Player %s reloaded %s (%d in magazine, %d in reserve)",
+		player.ID, weapon.ID, player.Ammo[weapon.ID], player.ReserveAmmo[weapon.ID])
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// SendChat broadcasts a chat message from a player. Messages from muted players are only
+// echoed back to the sender, so they can't tell they've been muted.
+func (sm *StateManager) SendChat(playerID, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > types.MaxChatLength {
+		return types.ErrInvalidChatMessage
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+
+	payload := types.ChatPayload{PlayerID: player.ID, PlayerName: player.DisplayName, Text: text}
+	if player.Muted {
+		logger.DebugLogger.Printf("Dropped chat message from muted player %s", playerID)
+		sm.emit(types.MessageTypeChat, player.ID, payload)
+		return nil
+	}
+
+	sm.emit(types.MessageTypeChat, "", payload)
+	return nil
+}
+
+// MutePlayer stops a player's chat messages from reaching anyone else, for the given duration or
+// until unmuted when it is 0
+func (sm *StateManager) MutePlayer(playerID string, duration time.Duration) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+
+	player.Muted = true
+	player.MutedFor = duration.Seconds()
+	logger.InfoLogger.Printf("Player %s muted (for: %v)", playerID, duration)
+	return nil
+}
+
+// UnmutePlayer lets a muted player's chat messages through again
+func (sm *StateManager) UnmutePlayer(playerID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+
+	player.Muted = false
+	player.MutedFor = 0
+	logger.InfoLogger.Printf("Player %s unmuted", playerID)
+	return nil
+}
+
+// updateMute counts down a timed mute, lifting it when it runs out
+func updateMute(player *types.Player, deltaTime float64) {
+	if !player.Muted || player.MutedFor <= 0 {
+		return
+	}
+
+	player.MutedFor -= deltaTime
+	if player.MutedFor <= 0 {
+		player.Muted = false
+		player.MutedFor = 0
+		logger.InfoLogger.Printf("Mute of player %s expired", player.ID)
+	}
+}
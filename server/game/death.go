@@ -0,0 +1,219 @@
+package game
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// maxKillFeedEntries is the number of recent kills kept in the kill feed
+const maxKillFeedEntries = 10
+
+// damagePlayer takes damage off a player's health, killing them when it runs out.
+// attacker is nil for environmental damage.
+func (sm *StateManager) damagePlayer(victim, attacker *types.Player, damage int, cause types.DamageCause) {
+	// Freshly respawned and disconnected players can't be damaged
+	if victim.InvulnerableFor > 0 || victim.Disconnected || damage <= 0 {
+		return
+	}
+
+	victim.Health -= absorbDamage(victim, damage, cause)
+	victim.LastDamageTime = sm.state.GameTime
+	victim.RegenProgress = 0
+	if attacker != nil && attacker != victim {
+		if victim.Attackers == nil {
+			victim.Attackers = make(map[string]bool)
+		}
+		victim.Attackers[attacker.ID] = true
+		victim.LastDamagedBy = &types.DamageRecord{AttackerID: attacker.ID, GameTime: sm.state.GameTime}
+	}
+
+	if victim.Health <= 0 {
+		sm.killPlayer(victim, attacker, cause)
+	}
+}
+
+// killPlayer handles a player's death, crediting the killer if there is one
+func (sm *StateManager) killPlayer(victim, killer *types.Player, cause types.DamageCause) {
+	victim.IsAlive = false
+	victim.Health = 0
+	victim.Deaths++
+	victim.Killstreak = 0
+	victim.Effects = nil
+	sm.recordDeath(victim)
+
+	headshot := cause == types.DamageCauseHeadshot
+	event := types.KillEvent{
+		VictimID:      victim.ID,
+		VictimName:    victim.DisplayName,
+		Cause:         cause,
+		LastDamagedBy: victim.LastDamagedBy,
+		GameTime:      sm.state.GameTime,
+	}
+
+	if killer != nil {
+		killer.Kills++
+		killer.Killstreak++
+		killer.Score += sm.settings.Scoring.Kill
+		if headshot {
+			killer.Score += sm.settings.Scoring.HeadshotBonus
+		}
+		sm.awardKillAchievements(killer, victim)
+		victim.LastKilledBy = killer.ID
+
+		event.KillerID = killer.ID
+		event.KillerName = killer.DisplayName
+		event.WeaponID = killer.CurrentWeapon
+		event.Headshot = headshot
+		logger.InfoLogger.Printf("Player %s killed by %s (kills: %d, deaths: %d)",
+			victim.ID, killer.ID, killer.Kills, victim.Deaths)
+	} else {
+		logger.InfoLogger.Printf("Player %s died to %s (deaths: %d)", victim.ID, cause, victim.Deaths)
+	}
+
+	event.AssistIDs = sm.creditAssists(victim, killer)
+	sm.recordKill(event)
+	sm.recordTimeline(types.TimelineKill, event)
+	sm.emit(types.MessageTypeDeath, victim.ID, event)
+	sm.retargetSpectators(victim, killer)
+
+	// Eliminated players keep watching the match as spectators, everyone else respawns
+	switch sm.settings.GameMode {
+	case types.GameModeElimination:
+		sm.makeSpectator(victim, killer)
+		sm.recordElimination(victim)
+		return
+	case types.GameModeDuel:
+		sm.endDuel(victim, killer)
+		return
+	}
+
+	// In wave mode the dead wait for the next wave instead of their own timer
+	if sm.settings.RespawnWaveInterval > 0 {
+		victim.RespawnIn = sm.nextRespawnWave - sm.state.GameTime
+		sm.respawnQueue = append(sm.respawnQueue, victim.ID)
+		return
+	}
+
+	victim.RespawnIn = sm.settings.RespawnDelay.Seconds()
+	if victim.RespawnIn <= 0 {
+		sm.respawnPlayer(victim)
+	}
+}
+
+// creditAssists gives an assist to everyone else who damaged the victim during this life
+// and returns their IDs
+func (sm *StateManager) creditAssists(victim, killer *types.Player) []string {
+	var assists []string
+	for id := range victim.Attackers {
+		if killer != nil && id == killer.ID {
+			continue
+		}
+		if assister, ok := sm.state.Players[id]; ok {
+			assists = append(assists, id)
+			assister.Assists++
+			// Whoever wore down a player the zone finished off gets the credit for the kill
+			if killer == nil {
+				assister.Score += sm.settings.Scoring.ZoneKill
+			} else {
+				assister.Score += sm.settings.Scoring.Assist
+			}
+		}
+	}
+	victim.Attackers = nil
+	sort.Strings(assists)
+	return assists
+}
+
+// updateRespawn counts down a dead player's respawn timer, respawning them when it runs out
+func (sm *StateManager) updateRespawn(player *types.Player, deltaTime float64) {
+	if player.IsSpectator || player.RespawnIn <= 0 {
+		return
+	}
+
+	// A wave's countdown only tells the client when it comes back, updateRespawnWaves respawns everyone together
+	if sm.settings.RespawnWaveInterval > 0 {
+		player.RespawnIn = math.Max(0, player.RespawnIn-deltaTime)
+		return
+	}
+
+	player.RespawnIn -= deltaTime
+	if player.RespawnIn <= 0 {
+		sm.respawnPlayer(player)
+	}
+}
+
+// resetRespawnWaves schedules the first respawn wave of a new match, everyone is alive again so nobody waits for it
+func (sm *StateManager) resetRespawnWaves() {
+	sm.nextRespawnWave = sm.settings.RespawnWaveInterval.Seconds()
+	sm.respawnQueue = nil
+}
+
+// updateRespawnWaves respawns every queued dead player at once whenever a respawn wave comes round
+func (sm *StateManager) updateRespawnWaves() {
+	interval := sm.settings.RespawnWaveInterval.Seconds()
+	if interval <= 0 || sm.state.GameTime < sm.nextRespawnWave {
+		return
+	}
+	sm.nextRespawnWave = (math.Floor(sm.state.GameTime/interval) + 1) * interval
+
+	respawned := 0
+	for _, id := range sm.respawnQueue {
+		// Players who left or already came back with a new round have nothing to wait for
+		if player, ok := sm.state.Players[id]; ok && !player.IsAlive && !player.IsSpectator {
+			sm.respawnPlayer(player)
+			respawned++
+		}
+	}
+	sm.respawnQueue = nil
+
+	if respawned > 0 {
+		logger.InfoLogger.Printf("Respawn wave brought back %d players at %.1fs", respawned, sm.state.GameTime)
+	}
+}
+
+// respawnPlayer brings a dead player back at a fresh spawn point with spawn protection
+func (sm *StateManager) respawnPlayer(player *types.Player) {
+	player.Health = 100
+	player.Shield = sm.startingShield()
+	player.IsAlive = true
+	player.RespawnIn = 0
+	player.InvulnerableFor = sm.settings.SpawnProtection.Seconds()
+	player.ZoneDamage = 0
+	player.Attackers = nil
+	player.LastDamagedBy = nil
+	player.Effects = nil
+	player.Stance = types.StanceStand
+	player.Spread = 0
+	player.FallingFrom = 0
+	player.Airborne = false
+	player.VerticalVelocity = 0
+	refillAmmo(player)
+	player.Position = sm.respawnPoint(player)
+	player.LastMoveTime = time.Now()
+
+	logger.InfoLogger.Printf("Player %s respawned at position (%.2f, %.2f, %.2f)",
+		player.ID, player.Position.X, player.Position.Y, player.Position.Z)
+}
+
+// recordKill adds a kill to the kill feed, dropping the oldest entries beyond the limit
+func (sm *StateManager) recordKill(event types.KillEvent) {
+	sm.state.KillFeed = append(sm.state.KillFeed, event)
+	if len(sm.state.KillFeed) > maxKillFeedEntries {
+		sm.state.KillFeed = sm.state.KillFeed[len(sm.state.KillFeed)-maxKillFeedEntries:]
+	}
+}
+
+// activePlayerCount returns the number of players occupying a player slot
+func (sm *StateManager) activePlayerCount() int {
+	count := 0
+	for _, player := range sm.state.Players {
+		if !player.IsSpectator {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// DebugState returns a deep copy of the game state and the state manager's internal bookkeeping, including
+// the fields that are never broadcast to clients, for diagnosing bugs. It is safe to encode after returning.
+func (sm *StateManager) DebugState() map[string]interface{} {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	drops := make([]interface{}, 0, len(sm.pendingDrops))
+	for _, drop := range sm.pendingDrops {
+		drops = append(drops, map[string]interface{}{
+			"pickup":  debugValue(reflect.ValueOf(drop.pickup)),
+			"landsAt": drop.landsAt,
+		})
+	}
+
+	sm.movesMu.Lock()
+	pendingMoves := debugValue(reflect.ValueOf(sm.pendingMoves))
+	sm.movesMu.Unlock()
+
+	return map[string]interface{}{
+		"state":           debugValue(reflect.ValueOf(sm.state)),
+		"settings":        debugValue(reflect.ValueOf(sm.settings)),
+		"lastUpdate":      sm.lastUpdate,
+		"warmupElapsed":   sm.warmupElapsed,
+		"nextSupplyDrop":  sm.nextSupplyDrop,
+		"nextRespawnWave": sm.nextRespawnWave,
+		"respawnQueue":    append([]string{}, sm.respawnQueue...),
+		"pendingDrops":    drops,
+		"dropCount":       sm.dropCount,
+		"projectileCount": sm.projectileCount,
+		"peakPlayers":     sm.peakPlayers,
+		"pendingMoves":    pendingMoves,
+	}
+}
+
+// debugValue deep copies v into maps, slices and plain values. Unlike encoding/json it keeps struct
+// fields tagged json:"-", named by their tag when they have one and by their lower-cased name otherwise.
+func debugValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return debugValue(v.Elem())
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface()
+		}
+		fields := make(map[string]interface{})
+		debugFields(v, fields)
+		return fields
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries[iter.Key().String()] = debugValue(iter.Value())
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = debugValue(v.Index(i))
+		}
+		return items
+	default:
+		if v.Type() == durationType {
+			return time.Duration(v.Int()).String()
+		}
+		return v.Interface()
+	}
+}
+
+// debugFields adds the exported fields of struct v to fields, flattening embedded structs like encoding/json
+func debugFields(v reflect.Value, fields map[string]interface{}) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			debugFields(v.Field(i), fields)
+			continue
+		}
+		fields[debugFieldName(field)] = debugValue(v.Field(i))
+	}
+}
+
+// debugFieldName is the field's JSON name, or its name starting lower-case when it isn't encoded
+func debugFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	runes := []rune(field.Name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
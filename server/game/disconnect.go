@@ -0,0 +1,35 @@
+package game
+
+import (
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// DisconnectPlayer keeps a player whose connection dropped in the game, frozen where they are and out of
+// reach of shots and damage, until they reconnect or are removed. They keep their slot meanwhile.
+func (sm *StateManager) DisconnectPlayer(id string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[id]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+	player.Disconnected = true
+	logger.InfoLogger.Printf("Player %s disconnected, holding their place", id)
+	return nil
+}
+
+// ReconnectPlayer brings a disconnected player back into play as they were
+func (sm *StateManager) ReconnectPlayer(id string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[id]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+	player.Disconnected = false
+	logger.InfoLogger.Printf("Player %s reconnected", id)
+	return nil
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+const (
+	// EffectBurn is fire damage, a new burn refreshes the current one instead of stacking
+	EffectBurn = "burn"
+	// EffectPoison is poison damage, a few doses stack
+	EffectPoison = "poison"
+)
+
+// effectMaxStacks is how many effects of a type a player can carry at once, types not listed don't stack
+var effectMaxStacks = map[string]int{
+	EffectBurn:   1,
+	EffectPoison: 3,
+}
+
+// ApplyEffect puts a damage-over-time effect on a player. Once a player carries the most effects
+// of a type allowed, the one closest to running out is replaced.
+func (sm *StateManager) ApplyEffect(playerID string, effect types.StatusEffect) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+	if !player.IsAlive {
+		return types.ErrPlayerDead
+	}
+	if effect.Type == "" || effect.Remaining <= 0 || effect.DamagePerSecond < 0 {
+		return types.ErrInvalidPayload
+	}
+
+	sm.applyEffect(player, effect)
+	return nil
+}
+
+// applyEffect adds an effect to a player following the stacking rules, the caller must hold sm.mu
+func (sm *StateManager) applyEffect(player *types.Player, effect types.StatusEffect) {
+	effect.PendingDamage = 0
+
+	maxStacks := effectMaxStacks[effect.Type]
+	if maxStacks < 1 {
+		maxStacks = 1
+	}
+
+	stacks := 0
+	weakest := -1
+	for i, active := range player.Effects {
+		if active.Type != effect.Type {
+			continue
+		}
+		stacks++
+		if weakest < 0 || active.Remaining < player.Effects[weakest].Remaining {
+			weakest = i
+		}
+	}
+
+	if stacks >= maxStacks {
+		player.Effects[weakest] = effect
+	} else {
+		player.Effects = append(player.Effects, effect)
+	}
+
+	logger.DebugLogger.Printf("Player %s got %s (%.1f damage per second for %.1fs)",
+		player.ID, effect.Type, effect.DamagePerSecond, effect.Remaining)
+}
+
+// effectHit is the damage one effect deals in a tick
+type effectHit struct {
+	sourceID string
+	damage   int
+}
+
+// updateEffects deals the damage of every active effect and drops the ones that ran out
+func (sm *StateManager) updateEffects(deltaTime float64) {
+	for _, player := range sm.sortedPlayers() {
+		if !player.IsAlive || len(player.Effects) == 0 {
+			continue
+		}
+
+		var hits []effectHit
+		active := player.Effects[:0]
+		for _, effect := range player.Effects {
+			tick := math.Min(deltaTime, effect.Remaining)
+			effect.PendingDamage += effect.DamagePerSecond * tick
+			damage := int(effect.PendingDamage)
+			effect.PendingDamage -= float64(damage)
+			if damage > 0 {
+				hits = append(hits, effectHit{sourceID: effect.SourceID, damage: damage})
+			}
+
+			effect.Remaining -= deltaTime
+			if effect.Remaining > 0 {
+				active = append(active, effect)
+			}
+		}
+		player.Effects = active
+
+		// Effects deal their damage through the same path as shots, so kills and assists go to whoever
+		// applied them. A death clears the remaining effects and ends the tick's damage.
+		deaths := player.Deaths
+		for _, hit := range hits {
+			if player.Deaths != deaths {
+				break
+			}
+			sm.damagePlayer(player, sm.state.Players[hit.sourceID], hit.damage, types.DamageCauseEffect)
+		}
+	}
+}
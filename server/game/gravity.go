@@ -0,0 +1,80 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+const (
+	// jumpSpeed is the upward speed in units per second a jump starts with, and gravity how much
+	// speed per second falling players gain - match jumpForce and the gravity in the client's PlayerControls.ts
+	jumpSpeed = 6.5
+	gravity   = 12.0
+)
+
+// jump launches a player off the ground, players already in the air can't jump again
+func (sm *StateManager) jump(player *types.Player) error {
+	if player.Airborne {
+		return types.ErrAlreadyAirborne
+	}
+
+	player.Airborne = true
+	player.VerticalVelocity = jumpSpeed
+	logger.DebugLogger.Printf("Player %s jumped from height %.2f", player.ID, player.Position.Y)
+	return nil
+}
+
+// updateGravity moves an airborne player along their jump arc, landing them once they come back
+// down to the ground or the top of an obstacle
+func (sm *StateManager) updateGravity(player *types.Player, deltaTime float64) {
+	if !player.Airborne {
+		return
+	}
+
+	ground := sm.groundHeight(player.Position)
+	player.VerticalVelocity -= gravity * deltaTime
+	player.Position.Y += player.VerticalVelocity * deltaTime
+	if player.Position.Y > ground || player.VerticalVelocity > 0 {
+		return
+	}
+
+	speed := -player.VerticalVelocity
+	player.Position.Y = ground
+	player.VerticalVelocity = 0
+	player.Airborne = false
+	logger.DebugLogger.Printf("Player %s landed at height %.2f (speed %.2f)", player.ID, ground, speed)
+
+	if sm.settings.FallDamage {
+		sm.landHard(player, speed)
+	}
+}
+
+// landHard hurts a player hitting the ground faster than FallDamageSpeed, in proportion to how much
+// faster. Falls have no killer, whoever hit the player last only shows up in the kill's LastDamagedBy.
+func (sm *StateManager) landHard(player *types.Player, speed float64) {
+	if speed <= sm.settings.FallDamageSpeed {
+		return
+	}
+
+	damage := int(math.Round((speed - sm.settings.FallDamageSpeed) * sm.settings.FallDamagePerSpeed))
+	logger.DebugLogger.Printf("Player %s landed at %.2f units per second and takes %d damage", player.ID, speed, damage)
+	sm.damagePlayer(player, nil, damage, types.DamageCauseFall)
+}
+
+// groundHeight is the height of whatever is below a position, the top of the highest obstacle under
+// it or the ground itself
+func (sm *StateManager) groundHeight(position types.Vector3) float64 {
+	ground := 0.0
+	for _, obstacle := range sm.settings.Obstacles {
+		if position.X < obstacle.Min.X || position.X > obstacle.Max.X ||
+			position.Z < obstacle.Min.Z || position.Z > obstacle.Max.Z {
+			continue
+		}
+		if obstacle.Max.Y <= position.Y && obstacle.Max.Y > ground {
+			ground = obstacle.Max.Y
+		}
+	}
+	return ground
+}
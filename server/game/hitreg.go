@@ -0,0 +1,83 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// HitRegMode decides how much the server trusts the hits clients report with their shots
+type HitRegMode string
+
+const (
+	// HitRegServer ignores what clients claim their shots hit and raycasts every shot on the server
+	HitRegServer HitRegMode = "server"
+	// HitRegClientAssisted accepts the player or obstacle a client reports its shot hit, after a looser
+	// server check that forgives the latency between what the client saw and the server state
+	HitRegClientAssisted HitRegMode = "client"
+)
+
+// claimedHitTolerance is how many times the server's hit threshold a claimed hit point may be from its target
+const claimedHitTolerance = 2.0
+
+// handleClaimedShot applies a shot whose result the client reported, in client-assisted mode. It returns
+// false when the shot carries no claim or the claim doesn't hold up, so the server raycasts it instead.
+// Shots of several pellets are always raycast, a single claimed hit can't stand for all of them.
+func (sm *StateManager) handleClaimedShot(shooter *types.Player, data types.PlayerActionData, weapon Weapon) bool {
+	if sm.settings.HitRegMode != HitRegClientAssisted || data.HitPoint == nil || weapon.Pellets > 1 {
+		return false
+	}
+
+	// The client saw its shot stop at an obstacle before reaching anyone
+	if data.TargetID == "" {
+		if data.HitObstacle != nil && *data.HitObstacle {
+			logger.DebugLogger.Printf("Shot from player %s hit an obstacle at (%.2f, %.2f, %.2f)",
+				shooter.ID, data.HitPoint.X, data.HitPoint.Y, data.HitPoint.Z)
+			return true
+		}
+		return false
+	}
+
+	target, exists := sm.state.Players[data.TargetID]
+	if !exists || !target.IsAlive || target == shooter || !isFinite(*data.HitPoint) {
+		logger.DebugLogger.Printf("Rejected hit claimed by player %s on %q: no such living target", shooter.ID, data.TargetID)
+		return false
+	}
+
+	origin := eyePosition(shooter)
+	distance := distance3D(origin, *data.HitPoint)
+	if weapon.Range > 0 && distance > weapon.Range {
+		logger.DebugLogger.Printf("Rejected hit claimed by player %s on %s: %.2f away is out of %s range",
+			shooter.ID, target.ID, distance, weapon.ID)
+		return false
+	}
+	direction := normalized(types.Vector3{
+		X: data.HitPoint.X - origin.X,
+		Y: data.HitPoint.Y - origin.Y,
+		Z: data.HitPoint.Z - origin.Z,
+	})
+	if wall, blocked := obstacleAlongRay(origin, direction, sm.settings.Obstacles); blocked && wall < distance {
+		logger.DebugLogger.Printf("Rejected hit claimed by player %s on %s: an obstacle %.2f away is in the way",
+			shooter.ID, target.ID, wall)
+		return false
+	}
+	offset := distance3D(target.Position, *data.HitPoint)
+	if offset > hitThresholdAt(distance)*claimedHitTolerance {
+		logger.DebugLogger.Printf("Rejected hit claimed by player %s on %s: hit point %.2f away from the target",
+			shooter.ID, target.ID, offset)
+		return false
+	}
+
+	zone := hitZoneAt(target, *data.HitPoint)
+	sm.damagePlayer(target, shooter, sm.zoneDamage(falloffDamage(weapon, distance), zone), hitCause(zone))
+	logger.DebugLogger.Printf("Accepted hit claimed by player %s on %s (distance: %.2f, zone: %s)",
+		shooter.ID, target.ID, distance, zone)
+	return true
+}
+
+// distance3D is the straight line distance between a and b
+func distance3D(a, b types.Vector3) float64 {
+	dx, dy, dz := a.X-b.X, a.Y-b.Y, a.Z-b.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
@@ -0,0 +1,220 @@
+package game
+
+import (
+	"math"
+	"sort"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// rayHit is a player a shot's ray passes close enough to hit
+type rayHit struct {
+	player *types.Player
+	// distance is how far along the ray the player is
+	distance float64
+	zone     types.HitZone
+}
+
+// fireHitscan resolves an instant shot from origin along direction, one ray per pellet for weapons firing several
+func (sm *StateManager) fireHitscan(shooter *types.Player, origin, direction types.Vector3, weapon Weapon) {
+	// Normalize direction
+	magnitude := math.Sqrt(direction.X*direction.X + direction.Y*direction.Y + direction.Z*direction.Z)
+	if magnitude > 0 {
+		direction.X /= magnitude
+		direction.Y /= magnitude
+		direction.Z /= magnitude
+	}
+
+	if weapon.Pellets <= 1 {
+		sm.fireRay(shooter, origin, direction, weapon)
+		return
+	}
+	for _, pellet := range pelletDirections(direction, weapon.Pellets, weapon.PelletSpread) {
+		sm.fireRay(shooter, origin, pellet, weapon)
+	}
+}
+
+// pelletDirections spreads count normalized directions evenly on a cone spread radians around direction,
+// always the same pattern so pellets land the same way on every run
+func pelletDirections(direction types.Vector3, count int, spread float64) []types.Vector3 {
+	// Two axes perpendicular to the shot to spread the pellets along
+	up := types.Vector3{Y: 1}
+	if math.Abs(direction.Y) > 0.99 {
+		up = types.Vector3{X: 1}
+	}
+	right := normalized(cross(direction, up))
+	up = cross(right, direction)
+
+	offset := math.Tan(spread)
+	pellets := make([]types.Vector3, count)
+	for i := range pellets {
+		angle := 2 * math.Pi * float64(i) / float64(count)
+		sin, cos := math.Sincos(angle)
+		pellets[i] = normalized(types.Vector3{
+			X: direction.X + offset*(cos*right.X+sin*up.X),
+			Y: direction.Y + offset*(cos*right.Y+sin*up.Y),
+			Z: direction.Z + offset*(cos*right.Z+sin*up.Z),
+		})
+	}
+	return pellets
+}
+
+// cross is the cross product of a and b
+func cross(a, b types.Vector3) types.Vector3 {
+	return types.Vector3{X: a.Y*b.Z - a.Z*b.Y, Y: a.Z*b.X - a.X*b.Z, Z: a.X*b.Y - a.Y*b.X}
+}
+
+// normalized scales v to unit length, leaving a zero vector as it is
+func normalized(v types.Vector3) types.Vector3 {
+	length := math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+	if length == 0 {
+		return v
+	}
+	return types.Vector3{X: v.X / length, Y: v.Y / length, Z: v.Z / length}
+}
+
+// fireRay resolves one ray of a shot along the normalized direction. It hits the closest player within
+// the weapon's range, and penetrating weapons go on to hit the players lined up behind them with less
+// damage each time.
+func (sm *StateManager) fireRay(shooter *types.Player, origin, direction types.Vector3, weapon Weapon) {
+	hits := sm.hitsAlongRay(shooter, origin, direction)
+
+	// Players behind a wall are out of sight, whatever the client says its shot hit
+	if wall, blocked := obstacleAlongRay(origin, direction, sm.settings.Obstacles); blocked {
+		for i, hit := range hits {
+			if hit.distance > wall {
+				logger.DebugLogger.Printf("Shot from player %s was stopped by an obstacle %.2f away before reaching %s",
+					shooter.ID, wall, hit.player.ID)
+				hits = hits[:i]
+				break
+			}
+		}
+	}
+
+	// Players beyond the weapon's reach are out of range, hits are sorted so cut at the first one
+	if weapon.Range > 0 {
+		for i, hit := range hits {
+			if hit.distance > weapon.Range {
+				logger.DebugLogger.Printf("Shot from player %s fell short of %s (range %.0f, distance %.2f)",
+					shooter.ID, hit.player.ID, weapon.Range, hit.distance)
+				hits = hits[:i]
+				break
+			}
+		}
+	}
+
+	// Sustained fire makes long range hits unreliable, a shot that strays misses everyone behind too
+	if len(hits) > 0 && !sm.spreadAllowsHit(shooter, hits[0].distance) {
+		logger.DebugLogger.Printf("Shot from player %s strayed from %s (spread %.2f, distance %.2f)",
+			shooter.ID, hits[0].player.ID, shooter.Spread, hits[0].distance)
+		hits = nil
+	}
+
+	if len(hits) > weapon.Penetration+1 {
+		hits = hits[:weapon.Penetration+1]
+	}
+
+	power := 1.0
+	for i, hit := range hits {
+		// Each player passed through takes some of the shot's power
+		if i > 0 {
+			power *= weapon.PenetrationFalloff
+		}
+		oldHealth := hit.player.Health
+		damage := falloffDamage(weapon, hit.distance) * power
+		dealt := sm.zoneDamage(damage, hit.zone)
+
+		sm.damagePlayer(hit.player, shooter, dealt, hitCause(hit.zone))
+
+		logger.DebugLogger.Printf("Player %s hit player %s (health: %d -> %d, distance: %.2f, damage: %d, zone: %s, penetrated: %d)",
+			shooter.ID, hit.player.ID, oldHealth, hit.player.Health, hit.distance, dealt, hit.zone, i)
+	}
+
+	if len(hits) == 0 {
+		logger.DebugLogger.Printf("Summary: Shot from player %s did not hit any targets", shooter.ID)
+	} else {
+		logger.DebugLogger.Printf("Summary: Shot from player %s registered %d hits", shooter.ID, len(hits))
+	}
+}
+
+// passingPoint is where a ray goes past a player standing at position, level with them horizontally,
+// which is the height it meets their hitbox at. Rays going straight up or down use the closest point.
+func passingPoint(origin, direction, position, closest types.Vector3) types.Vector3 {
+	horizontal := direction.X*direction.X + direction.Z*direction.Z
+	if horizontal < 1e-9 {
+		return closest
+	}
+	along := ((position.X-origin.X)*direction.X + (position.Z-origin.Z)*direction.Z) / horizontal
+	return types.Vector3{
+		X: origin.X + direction.X*along,
+		Y: origin.Y + direction.Y*along,
+		Z: origin.Z + direction.Z*along,
+	}
+}
+
+// hitsAlongRay finds the living players a ray from origin along the normalized direction passes
+// close enough to hit, closest first and equally close ones by ID
+func (sm *StateManager) hitsAlongRay(shooter *types.Player, origin, direction types.Vector3) []rayHit {
+	var hits []rayHit
+	for _, player := range sm.sortedPlayers() {
+		id := player.ID
+		// Skip the shooter, players already dead and disconnected ones shots pass through
+		if id == shooter.ID || !player.IsAlive || player.Disconnected {
+			continue
+		}
+
+		// Calculate vector from the shooter's eyes to the player
+		toPlayer := types.Vector3{
+			X: player.Position.X - origin.X,
+			Y: player.Position.Y - origin.Y,
+			Z: player.Position.Z - origin.Z,
+		}
+
+		// Calculate the dot product to find the projection of toPlayer onto direction
+		dotProduct := toPlayer.X*direction.X + toPlayer.Y*direction.Y + toPlayer.Z*direction.Z
+
+		// If the player is behind the shooter, skip
+		if dotProduct <= 0 {
+			logger.DebugLogger.Printf("Player %s is behind the shooter, skipping", id)
+			continue
+		}
+
+		// Calculate closest point on ray to player
+		closestPoint := types.Vector3{
+			X: origin.X + direction.X*dotProduct,
+			Y: origin.Y + direction.Y*dotProduct,
+			Z: origin.Z + direction.Z*dotProduct,
+		}
+
+		// Shots passing over a crouching or prone player's head miss them
+		if passesOverHead(player, closestPoint) {
+			logger.DebugLogger.Printf("Shot passed over player %s in stance %s", id, player.Stance)
+			continue
+		}
+
+		// Calculate distance from closest point to player (perpendicular distance)
+		dx := player.Position.X - closestPoint.X
+		dy := player.Position.Y - closestPoint.Y
+		dz := player.Position.Z - closestPoint.Z
+		perpendicularDistance := math.Sqrt(dx*dx + dy*dy + dz*dz)
+
+		hitThreshold := hitThresholdAt(dotProduct)
+
+		logger.DebugLogger.Printf("Checking player %s at position (%.2f, %.2f, %.2f), distance along ray: %.2f, perpendicular distance: %.2f, hit threshold: %.2f",
+			id, player.Position.X, player.Position.Y, player.Position.Z, dotProduct, perpendicularDistance, hitThreshold)
+
+		// If the shot hit (ray passes within the calculated threshold of the player)
+		if perpendicularDistance >= hitThreshold {
+			logger.DebugLogger.Printf("Shot missed player %s - perpendicular distance %.2f > hit threshold %.2f", id, perpendicularDistance, hitThreshold)
+			continue
+		}
+
+		zone := hitZoneAt(player, passingPoint(origin, direction, player.Position, closestPoint))
+		hits = append(hits, rayHit{player: player, distance: dotProduct, zone: zone})
+	}
+
+	// Players are visited in ID order, so equally distant ones stay in that order
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].distance < hits[j].distance })
+	return hits
+}
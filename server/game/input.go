@@ -0,0 +1,133 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// queueMove buffers a move until the next tick, merging it over the player's earlier moves
+func (sm *StateManager) queueMove(id string, data types.PlayerActionData) {
+	sm.movesMu.Lock()
+	defer sm.movesMu.Unlock()
+
+	if sm.pendingMoves == nil {
+		sm.pendingMoves = make(map[string]types.PlayerActionData)
+	}
+
+	// A rotation-only move mustn't drop an earlier position, and the other way around
+	pending := sm.pendingMoves[id]
+	if data.Position != nil {
+		pending.Position = data.Position
+	}
+	if data.Rotation != nil {
+		pending.Rotation = data.Rotation
+	}
+	sm.pendingMoves[id] = pending
+}
+
+// applyQueuedMoves applies the latest buffered move of every player, the caller must hold sm.mu
+func (sm *StateManager) applyQueuedMoves() {
+	sm.movesMu.Lock()
+	moves := sm.pendingMoves
+	sm.pendingMoves = nil
+	sm.movesMu.Unlock()
+
+	for id, data := range moves {
+		player, exists := sm.state.Players[id]
+		if !exists || !player.IsAlive {
+			continue
+		}
+		sm.applyMove(player, data)
+	}
+}
+
+const (
+	// moveJitter is extra time granted to every move's speed check, so moves held up by the network and
+	// then arriving in a burst aren't mistaken for a player speeding
+	moveJitter = 200 * time.Millisecond
+	// moveWindowTicks is how many ticks since the last accepted move count towards the distance the
+	// next one may cover, so standing still doesn't save up a long jump
+	moveWindowTicks = 2
+)
+
+// maxMoveSpeed is the fastest a player may move horizontally in their stance, 0 when there's no limit
+func (sm *StateManager) maxMoveSpeed(player *types.Player) float64 {
+	return sm.settings.MaxMoveSpeed * profileOf(player).speedMultiplier
+}
+
+// movedTooFast reports whether getting to position since the player's last accepted move means going
+// horizontally faster than their stance allows
+func (sm *StateManager) movedTooFast(player *types.Player, position types.Vector3) bool {
+	if sm.settings.MaxMoveSpeed <= 0 {
+		return false
+	}
+
+	window := moveWindowTicks * time.Second / time.Duration(max(1, sm.settings.TickRate))
+	elapsed := min(time.Since(player.LastMoveTime), window) + moveJitter
+	distance := horizontalDistance(player.Position, position)
+	return distance > sm.maxMoveSpeed(player)*elapsed.Seconds()
+}
+
+// applyMove moves a player, keeping them out of obstacles and inside the world, and tells them
+// their authoritative position when it differs from the requested one. Moves faster than the speed
+// limit are rejected and the player snapped back to where they were.
+func (sm *StateManager) applyMove(player *types.Player, data types.PlayerActionData) {
+	if data.Position != nil && sm.movedTooFast(player, *data.Position) {
+		logger.WarningLogger.Printf("Rejected move of player %s to (%.2f, %.2f, %.2f): %.2f units in %v is faster than %.1f units/s (%s)",
+			player.ID, data.Position.X, data.Position.Y, data.Position.Z, horizontalDistance(player.Position, *data.Position),
+			time.Since(player.LastMoveTime).Round(time.Millisecond), sm.maxMoveSpeed(player), player.Stance)
+		sm.emit(types.MessageTypePositionCorrection, player.ID, types.PositionCorrectionPayload{Position: player.Position})
+	} else if data.Position != nil {
+		previousY := player.Position.Y
+		position := *data.Position
+		// The server flies jumping players along their arc, only their horizontal move is taken
+		if player.Airborne {
+			position.Y = player.Position.Y
+		}
+		if sm.settings.ObstacleCollision {
+			position = resolveObstacleCollisions(player.Position, position, sm.settings.Obstacles)
+		}
+		player.Position = sm.clampToWorld(position)
+		player.LastMoveTime = time.Now()
+		sm.collectPickups(player)
+
+		if player.Position != *data.Position {
+			sm.emit(types.MessageTypePositionCorrection, player.ID, types.PositionCorrectionPayload{Position: player.Position})
+			logger.DebugLogger.Printf("Corrected position of player %s to (%.2f, %.2f, %.2f)",
+				player.ID, player.Position.X, player.Position.Y, player.Position.Z)
+		}
+
+		if sm.settings.FallDamage {
+			sm.updateFall(player, previousY)
+		}
+	}
+	if data.Rotation != nil {
+		player.Rotation = *data.Rotation
+	}
+}
+
+// updateFall follows a player dropping over several moves and hurts them once they land, which is
+// when they reach the ground or stop going down, as hard as gravity would have them hit the ground
+func (sm *StateManager) updateFall(player *types.Player, previousY float64) {
+	if player.Position.Y < previousY {
+		if player.FallingFrom == 0 {
+			player.FallingFrom = previousY
+		}
+		if player.Position.Y > 0 {
+			return
+		}
+	}
+	if player.FallingFrom == 0 {
+		return
+	}
+
+	drop := player.FallingFrom - player.Position.Y
+	player.FallingFrom = 0
+	if drop <= 0 {
+		return
+	}
+	sm.landHard(player, math.Sqrt(2*gravity*drop))
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// updateAutoStart starts a match once enough players have waited out the warmup, and ends it
+// when too few players are left, the caller must hold sm.mu
+func (sm *StateManager) updateAutoStart(deltaTime float64) {
+	if !sm.settings.AutoStart {
+		return
+	}
+
+	enoughPlayers := len(sm.state.Players) >= sm.settings.MinPlayers
+
+	if sm.matchActive() {
+		if !enoughPlayers {
+			logger.InfoLogger.Printf("Players dropped below the minimum (%d/%d), returning to the lobby",
+				len(sm.state.Players), sm.settings.MinPlayers)
+			sm.endGame()
+		}
+		return
+	}
+
+	if !enoughPlayers {
+		sm.warmupElapsed = 0
+		if sm.state.MatchState != types.MatchStateLobby {
+			sm.setMatchState(types.MatchStateLobby)
+		}
+		return
+	}
+	if sm.state.MatchState != types.MatchStateWarmup {
+		sm.setMatchState(types.MatchStateWarmup)
+	}
+
+	sm.warmupElapsed += deltaTime
+	if sm.warmupElapsed < sm.settings.AutoStartWarmup.Seconds() {
+		return
+	}
+
+	sm.warmupElapsed = 0
+	if err := sm.startGame(time.Now().UnixNano()); err != nil {
+		logger.WarningLogger.Printf("Auto-start failed: %v", err)
+		return
+	}
+	logger.InfoLogger.Printf("Match auto-started with %d players", len(sm.state.Players))
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"slices"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// matchTransitions are the match states each state may move on to. A match restarted while
+// it is being played goes straight from active to active.
+var matchTransitions = map[types.MatchState][]types.MatchState{
+	types.MatchStateLobby:  {types.MatchStateWarmup, types.MatchStateActive},
+	types.MatchStateWarmup: {types.MatchStateLobby, types.MatchStateActive},
+	types.MatchStateActive: {types.MatchStateEnding, types.MatchStateActive},
+	types.MatchStateEnding: {types.MatchStateEnded},
+	types.MatchStateEnded:  {types.MatchStateLobby, types.MatchStateWarmup, types.MatchStateActive},
+}
+
+// setMatchState moves the match to the next state, keeping IsGameActive in step with it.
+// Transitions the lifecycle doesn't allow are refused and logged. The caller must hold sm.mu.
+func (sm *StateManager) setMatchState(next types.MatchState) bool {
+	current := sm.state.MatchState
+	if !slices.Contains(matchTransitions[current], next) {
+		logger.WarningLogger.Printf("Match %s can't go from %s to %s", sm.state.MatchID, current, next)
+		return false
+	}
+
+	sm.state.MatchState = next
+	sm.state.IsGameActive = next == types.MatchStateActive
+	logger.InfoLogger.Printf("Match %s: %s -> %s", sm.state.MatchID, current, next)
+	return true
+}
+
+// matchActive reports whether a match is being played, the caller must hold sm.mu
+func (sm *StateManager) matchActive() bool {
+	return sm.state.MatchState == types.MatchStateActive
+}
+
+// inMatch reports whether a match is being played or wrapping up, the caller must hold sm.mu
+func (sm *StateManager) inMatch() bool {
+	return sm.state.MatchState == types.MatchStateActive || sm.state.MatchState == types.MatchStateEnding
+}
+
+// MatchState returns the phase of the match lifecycle the game is in
+func (sm *StateManager) MatchState() types.MatchState {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return sm.state.MatchState
+}
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"finalcircle/server/types"
+)
+
+// NameCollisionPolicy decides what happens when a player picks a name someone else already has
+type NameCollisionPolicy string
+
+const (
+	// NameCollisionAllow lets several players share a name
+	NameCollisionAllow NameCollisionPolicy = "allow"
+	// NameCollisionReject refuses names that are already taken
+	NameCollisionReject NameCollisionPolicy = "reject"
+	// NameCollisionSuffix numbers taken names, e.g. "Name (2)"
+	NameCollisionSuffix NameCollisionPolicy = "suffix"
+)
+
+// resolveNameCollision applies the name collision policy to a name the player wants,
+// returning the name they get, the caller must hold sm.mu
+func (sm *StateManager) resolveNameCollision(id, displayName string) (string, error) {
+	if sm.settings.NameCollisionPolicy == NameCollisionAllow || !sm.nameTaken(id, displayName) {
+		return displayName, nil
+	}
+
+	if sm.settings.NameCollisionPolicy == NameCollisionReject {
+		return "", types.ErrNameTaken
+	}
+
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", displayName, n)
+		if !sm.nameTaken(id, candidate) {
+			return candidate, nil
+		}
+	}
+}
+
+// nameTaken reports whether a player other than id goes by the name, ignoring case
+func (sm *StateManager) nameTaken(id, displayName string) bool {
+	for otherID, player := range sm.state.Players {
+		if otherID != id && strings.EqualFold(player.DisplayName, displayName) {
+			return true
+		}
+	}
+	return false
+}
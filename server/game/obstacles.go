@@ -0,0 +1,133 @@
+package game
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	"finalcircle/server/types"
+)
+
+// LoadObstacles reads obstacle geometry from a JSON file containing an array of boxes
+func LoadObstacles(path string) ([]types.Obstacle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var obstacles []types.Obstacle
+	if err := json.Unmarshal(data, &obstacles); err != nil {
+		return nil, err
+	}
+	return obstacles, nil
+}
+
+// resolveObstacleCollisions moves a player from one position towards another, stopping at the
+// surface of any obstacle in the way. Each horizontal axis is resolved separately so a blocked
+// move slides along the wall instead of stopping dead.
+func resolveObstacleCollisions(from, to types.Vector3, obstacles []types.Obstacle) types.Vector3 {
+	if len(obstacles) == 0 {
+		return to
+	}
+
+	position := from
+	position.Y = to.Y
+	position.X = sweepAxis(position, to.X, obstacles, axisX)
+	position.Z = sweepAxis(position, to.Z, obstacles, axisZ)
+	return position
+}
+
+type axis int
+
+const (
+	axisX axis = iota
+	axisZ
+)
+
+// sweepAxis moves the position along one axis to target, returning how far it gets before hitting an obstacle
+func sweepAxis(position types.Vector3, target float64, obstacles []types.Obstacle, along axis) float64 {
+	start, cross := position.X, position.Z
+	if along == axisZ {
+		start, cross = position.Z, position.X
+	}
+
+	result := target
+	for _, obstacle := range obstacles {
+		// Players can pass over or under obstacles they don't vertically overlap with
+		if position.Y >= obstacle.Max.Y || position.Y+playerHeight <= obstacle.Min.Y {
+			continue
+		}
+
+		// Expand the box by the player radius so the player can be treated as a point
+		boxMin, boxMax, crossBoxMin, crossBoxMax := obstacle.Min.X, obstacle.Max.X, obstacle.Min.Z, obstacle.Max.Z
+		if along == axisZ {
+			boxMin, boxMax, crossBoxMin, crossBoxMax = obstacle.Min.Z, obstacle.Max.Z, obstacle.Min.X, obstacle.Max.X
+		}
+		boxMin -= playerRadius
+		boxMax += playerRadius
+		if cross <= crossBoxMin-playerRadius || cross >= crossBoxMax+playerRadius {
+			continue
+		}
+
+		// Don't trap players that are already inside an obstacle
+		if start > boxMin && start < boxMax {
+			continue
+		}
+
+		if target > start && start <= boxMin && result > boxMin {
+			result = boxMin
+		} else if target < start && start >= boxMax && result < boxMax {
+			result = boxMax
+		}
+	}
+	return result
+}
+
+// obstacleAlongRay is how far a ray from origin along the normalized direction travels before it enters
+// an obstacle, ok is false when it enters none. Obstacles the ray starts inside don't block it.
+func obstacleAlongRay(origin, direction types.Vector3, obstacles []types.Obstacle) (distance float64, ok bool) {
+	distance = math.Inf(1)
+	for _, obstacle := range obstacles {
+		if entry, hit := rayEntersBox(origin, direction, obstacle); hit && entry < distance {
+			distance, ok = entry, true
+		}
+	}
+	return distance, ok
+}
+
+// rayEntersBox returns how far along the ray it enters the box, using the slab method: the ray is
+// inside the box where it is between the box's planes on all three axes at once
+func rayEntersBox(origin, direction types.Vector3, box types.Obstacle) (float64, bool) {
+	near, far := math.Inf(-1), math.Inf(1)
+	slabs := [3][4]float64{
+		{origin.X, direction.X, box.Min.X, box.Max.X},
+		{origin.Y, direction.Y, box.Min.Y, box.Max.Y},
+		{origin.Z, direction.Z, box.Min.Z, box.Max.Z},
+	}
+	for _, slab := range slabs {
+		start, step, low, high := slab[0], slab[1], slab[2], slab[3]
+		// A ray parallel to the planes is either between them all along or never
+		if step == 0 {
+			if start < low || start > high {
+				return 0, false
+			}
+			continue
+		}
+
+		enter, exit := (low-start)/step, (high-start)/step
+		if enter > exit {
+			enter, exit = exit, enter
+		}
+		near = math.Max(near, enter)
+		far = math.Min(far, exit)
+		if near > far {
+			return 0, false
+		}
+	}
+
+	// The box is behind the ray, or the ray starts inside it
+	if near < 0 {
+		return 0, false
+	}
+	return near, true
+}
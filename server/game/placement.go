@@ -0,0 +1,140 @@
+package game
+
+import (
+	"sort"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// recordElimination places an eliminated player behind everyone still standing,
+// and ends the match once a single player is left
+func (sm *StateManager) recordElimination(victim *types.Player) {
+	if !sm.matchActive() {
+		return
+	}
+
+	survivors := sm.survivors()
+	victim.Placement = len(survivors) + 1
+	sm.state.Eliminations = append(sm.state.Eliminations, victim.ID)
+	logger.InfoLogger.Printf("Player %s eliminated in place %d", victim.ID, victim.Placement)
+
+	if len(survivors) > 1 {
+		return
+	}
+	if len(survivors) == 1 {
+		survivors[0].Placement = 1
+		logger.InfoLogger.Printf("Player %s wins match %s", survivors[0].ID, sm.state.MatchID)
+	}
+	sm.endGame()
+}
+
+// endDuel ends a duel on its first death, crowning the killer, or the last player standing
+// when the victim died to the environment
+func (sm *StateManager) endDuel(victim, killer *types.Player) {
+	if !sm.matchActive() {
+		return
+	}
+
+	winner := killer
+	if survivors := sm.survivors(); winner == nil && len(survivors) == 1 {
+		winner = survivors[0]
+	}
+
+	victim.Placement = 2
+	sm.state.Eliminations = append(sm.state.Eliminations, victim.ID)
+	if winner != nil {
+		winner.Placement = 1
+		logger.InfoLogger.Printf("Player %s wins duel %s against %s", winner.ID, sm.state.MatchID, victim.ID)
+	}
+	sm.endGame()
+}
+
+// recordDeparture ends an elimination match or a duel once a player still in it leaves and only one
+// other is left standing, crowning them. The caller must hold sm.mu and have removed the leaver.
+func (sm *StateManager) recordDeparture(leaver *types.Player) {
+	if !sm.matchActive() || !leaver.IsAlive || leaver.IsSpectator {
+		return
+	}
+	if sm.settings.GameMode != types.GameModeElimination && sm.settings.GameMode != types.GameModeDuel {
+		return
+	}
+
+	survivors := sm.survivors()
+	if len(survivors) != 1 {
+		return
+	}
+	survivors[0].Placement = 1
+	logger.InfoLogger.Printf("Player %s wins match %s after %s left", survivors[0].ID, sm.state.MatchID, leaver.ID)
+	sm.endGame()
+}
+
+// survivors returns the players still in the match
+func (sm *StateManager) survivors() []*types.Player {
+	var survivors []*types.Player
+	for _, player := range sm.state.Players {
+		if player.IsAlive && !player.IsSpectator {
+			survivors = append(survivors, player)
+		}
+	}
+	return survivors
+}
+
+// emitMatchEnd announces the final standings of the match, the caller must hold sm.mu
+func (sm *StateManager) emitMatchEnd() {
+	payload := types.MatchEndPayload{MatchID: sm.state.MatchID, Standings: sm.standings()}
+	for _, standing := range payload.Standings {
+		if standing.Placement == 1 {
+			payload.WinnerID = standing.ID
+		}
+	}
+	// Nobody is eliminated in deathmatch, the leader of the standings wins instead
+	if sm.settings.GameMode == types.GameModeDeathmatch && len(payload.Standings) > 0 {
+		payload.WinnerID = payload.Standings[0].ID
+	}
+	// In team modes the leading team wins and its best player is the MVP
+	if sm.settings.TeamCount > 0 {
+		sm.totalTeamScores()
+		payload.Teams = sm.state.Teams
+		payload.WinningTeam = sm.leadingTeam()
+		for _, standing := range payload.Standings {
+			if payload.WinningTeam != "" && standing.Team == payload.WinningTeam {
+				payload.MVPID = standing.ID
+				break
+			}
+		}
+	}
+	sm.state.Winner = payload.WinnerID
+	sm.emit(types.MessageTypeMatchEnd, "", payload)
+	sm.recordTimeline(types.TimelineMatchEnd, payload)
+}
+
+// standings lists the players by placement, players still in the match first, then by kills or score
+func (sm *StateManager) standings() []types.PlayerStats {
+	standings := make([]types.PlayerStats, 0, len(sm.state.Players))
+	for _, player := range sm.state.Players {
+		standings = append(standings, statsOf(player))
+	}
+
+	// Players still in the match haven't been placed yet, they share first place until they go out
+	rank := func(stats types.PlayerStats) int {
+		if stats.Placement == 0 {
+			return 1
+		}
+		return stats.Placement
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if sm.settings.RankBy == RankByScore && a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		return a.ID < b.ID
+	})
+	return standings
+}
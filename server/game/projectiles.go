@@ -0,0 +1,173 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+const (
+	// projectileLifetime is how many seconds a projectile flies before it expires
+	projectileLifetime = 3.0
+	// projectileHitRadius is how close in units a projectile has to pass a player's center to hit them
+	projectileHitRadius = 1.0
+)
+
+// resetProjectiles clears the projectiles of the last match
+func (sm *StateManager) resetProjectiles() {
+	sm.state.Projectiles = make(map[string]*types.Projectile)
+	sm.projectileCount = 0
+}
+
+// fireProjectile launches a projectile from the shooter's eyes towards the shot's target or direction
+func (sm *StateManager) fireProjectile(shooter *types.Player, weapon Weapon, data types.PlayerActionData) {
+	origin := eyePosition(shooter)
+
+	var direction types.Vector3
+	switch {
+	case data.Target != nil:
+		direction = types.Vector3{X: data.Target.X - origin.X, Y: data.Target.Y - origin.Y, Z: data.Target.Z - origin.Z}
+	case data.Direction != nil:
+		direction = *data.Direction
+	default:
+		return
+	}
+
+	length := math.Sqrt(direction.X*direction.X + direction.Y*direction.Y + direction.Z*direction.Z)
+	if length == 0 {
+		return
+	}
+	scale := weapon.ProjectileSpeed / length
+
+	sm.projectileCount++
+	projectile := &types.Projectile{
+		ID:        fmt.Sprintf("projectile-%d", sm.projectileCount),
+		Sequence:  sm.projectileCount,
+		OwnerID:   shooter.ID,
+		WeaponID:  weapon.ID,
+		Position:  origin,
+		Velocity:  types.Vector3{X: direction.X * scale, Y: direction.Y * scale, Z: direction.Z * scale},
+		Damage:    weapon.Damage,
+		Remaining: projectileLifetime,
+	}
+	sm.state.Projectiles[projectile.ID] = projectile
+	logger.DebugLogger.Printf("Player %s fired %s %s", shooter.ID, weapon.ID, projectile.ID)
+}
+
+// updateProjectiles moves every projectile along its path for this tick, hitting the first player
+// it passes and dropping the ones that expired or left the world
+func (sm *StateManager) updateProjectiles(deltaTime float64) {
+	// Projectiles move in the order they were fired so two reaching a player in the same tick always
+	// resolve the same way
+	projectiles := make([]*types.Projectile, 0, len(sm.state.Projectiles))
+	for _, projectile := range sm.state.Projectiles {
+		projectiles = append(projectiles, projectile)
+	}
+	sort.Slice(projectiles, func(i, j int) bool { return projectiles[i].Sequence < projectiles[j].Sequence })
+
+	for _, projectile := range projectiles {
+		id := projectile.ID
+		from := projectile.Position
+		to := types.Vector3{
+			X: from.X + projectile.Velocity.X*deltaTime,
+			Y: from.Y + projectile.Velocity.Y*deltaTime,
+			Z: from.Z + projectile.Velocity.Z*deltaTime,
+		}
+
+		if victim, point, ok := sm.projectileHit(projectile, from, to); ok {
+			zone := hitZoneAt(victim, point)
+			logger.DebugLogger.Printf("Projectile %s of player %s hit player %s (zone: %s)", id, projectile.OwnerID, victim.ID, zone)
+			if weapon, _ := LookupWeapon(projectile.WeaponID); weapon.ExplosionRadius > 0 {
+				sm.explode(projectile, point, weapon.ExplosionRadius)
+			} else {
+				sm.damagePlayer(victim, sm.state.Players[projectile.OwnerID], sm.zoneDamage(float64(projectile.Damage), zone), hitCause(zone))
+			}
+			delete(sm.state.Projectiles, id)
+			continue
+		}
+
+		projectile.Position = to
+		projectile.Remaining -= deltaTime
+		if projectile.Remaining <= 0 || sm.clampToWorld(to) != to || to.Y < 0 {
+			// Explosives go off where they land or run out of time, not once they've left the world
+			if weapon, _ := LookupWeapon(projectile.WeaponID); weapon.ExplosionRadius > 0 && sm.clampToWorld(to) == to {
+				to.Y = math.Max(0, to.Y)
+				sm.explode(projectile, to, weapon.ExplosionRadius)
+			}
+			delete(sm.state.Projectiles, id)
+		}
+	}
+}
+
+// explode damages every living player within radius of an explosion at point, scaling the projectile's
+// damage down the farther their center is from it. Its owner is only hurt with SelfDamage enabled,
+// and isn't credited with killing themselves.
+func (sm *StateManager) explode(projectile *types.Projectile, point types.Vector3, radius float64) {
+	owner := sm.state.Players[projectile.OwnerID]
+	logger.DebugLogger.Printf("Projectile %s of player %s exploded at (%.2f, %.2f, %.2f)",
+		projectile.ID, projectile.OwnerID, point.X, point.Y, point.Z)
+
+	for _, player := range sm.sortedPlayers() {
+		id := player.ID
+		if !player.IsAlive || player.IsSpectator {
+			continue
+		}
+		attacker := owner
+		if id == projectile.OwnerID {
+			if !sm.settings.SelfDamage {
+				continue
+			}
+			attacker = nil
+		}
+
+		center := player.Position
+		center.Y += profileOf(player).height / 2
+		dx, dy, dz := center.X-point.X, center.Y-point.Y, center.Z-point.Z
+		distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
+		if distance >= radius {
+			continue
+		}
+
+		damage := int(math.Round(float64(projectile.Damage) * (1 - distance/radius)))
+		logger.DebugLogger.Printf("Explosion of %s hits player %s for %d damage (distance %.2f)", projectile.ID, id, damage, distance)
+		sm.damagePlayer(player, attacker, damage, types.DamageCauseExplosion)
+	}
+}
+
+// projectileHit finds the living player closest to where a projectile starts its move from from to to,
+// among those it passes within the hit radius of, along with the point where it passes them
+func (sm *StateManager) projectileHit(projectile *types.Projectile, from, to types.Vector3) (*types.Player, types.Vector3, bool) {
+	segment := types.Vector3{X: to.X - from.X, Y: to.Y - from.Y, Z: to.Z - from.Z}
+	lengthSquared := segment.X*segment.X + segment.Y*segment.Y + segment.Z*segment.Z
+	if lengthSquared == 0 {
+		return nil, types.Vector3{}, false
+	}
+
+	var hit *types.Player
+	var hitPoint types.Vector3
+	closest := math.MaxFloat64
+	for _, player := range sm.sortedPlayers() {
+		if player.ID == projectile.OwnerID || !player.IsAlive || player.IsSpectator || player.Disconnected {
+			continue
+		}
+
+		center := player.Position
+		center.Y += profileOf(player).height / 2
+
+		// Project the player's center onto the segment travelled this tick
+		t := ((center.X-from.X)*segment.X + (center.Y-from.Y)*segment.Y + (center.Z-from.Z)*segment.Z) / lengthSquared
+		t = math.Max(0, math.Min(1, t))
+		point := types.Vector3{X: from.X + segment.X*t, Y: from.Y + segment.Y*t, Z: from.Z + segment.Z*t}
+
+		dx, dy, dz := center.X-point.X, center.Y-point.Y, center.Z-point.Z
+		if math.Sqrt(dx*dx+dy*dy+dz*dz) < projectileHitRadius && t < closest {
+			closest = t
+			hit = player
+			hitPoint = point
+		}
+	}
+	return hit, hitPoint, hit != nil
+}
@@ -0,0 +1,26 @@
+package game
+
+import (
+	"finalcircle/server/types"
+)
+
+// regenerateHealth heals a player who hasn't taken damage for HealthRegenDelay, HealthRegenRate points
+// per second up to full health
+func (sm *StateManager) regenerateHealth(player *types.Player, deltaTime float64) {
+	if !sm.settings.HealthRegen || player.Health >= 100 {
+		player.RegenProgress = 0
+		return
+	}
+
+	// Damage taken later than the current game time was in an earlier match
+	sinceDamage := sm.state.GameTime - player.LastDamageTime
+	if sinceDamage >= 0 && sinceDamage < sm.settings.HealthRegenDelay.Seconds() {
+		return
+	}
+
+	// Health is whole points, so the healing of short ticks adds up until it makes one
+	player.RegenProgress += sm.settings.HealthRegenRate * deltaTime
+	healed := int(player.RegenProgress)
+	player.RegenProgress -= float64(healed)
+	player.Health = min(100, player.Health+healed)
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// RankBy decides what players are ranked by on the leaderboard and at match end
+type RankBy string
+
+const (
+	// RankByKills ranks players by their number of kills
+	RankByKills RankBy = "kills"
+	// RankByScore ranks players by the score they earned from the scoring table
+	RankByScore RankBy = "score"
+)
+
+// Scoring is the number of points each play is worth
+type Scoring struct {
+	// Kill is awarded to the killer
+	Kill int
+	// HeadshotBonus is added on top of Kill when the killing shot hit the head
+	HeadshotBonus int
+	// Assist is awarded to everyone else who damaged the victim
+	Assist int
+	// Objective is awarded for completing an objective
+	Objective int
+	// ZoneKill is awarded to everyone who damaged a player the zone finished off
+	ZoneKill int
+}
+
+// DefaultScoring returns the default scoring table
+func DefaultScoring() Scoring {
+	return Scoring{
+		Kill:          100,
+		HeadshotBonus: 50,
+		Assist:        50,
+		Objective:     200,
+		ZoneKill:      75,
+	}
+}
+
+// rankingPoints returns what a player is ranked by under the configured RankBy
+func (sm *StateManager) rankingPoints(player *types.Player) int {
+	if sm.settings.RankBy == RankByScore {
+		return player.Score
+	}
+	return player.Kills
+}
+
+// AwardObjective gives a player the score for completing an objective
+func (sm *StateManager) AwardObjective(playerID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+	if !sm.matchActive() {
+		return types.ErrGameNotActive
+	}
+
+	player.Score += sm.settings.Scoring.Objective
+	logger.DebugLogger.Printf("Player %s completed an objective (score: %d)", playerID, player.Score)
+	return nil
+}
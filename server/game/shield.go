@@ -0,0 +1,36 @@
+package game
+
+import (
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// shieldAbsorbs reports whether a player's shield takes damage of the cause before their health,
+// the zone and falls hurt health directly
+func shieldAbsorbs(cause types.DamageCause) bool {
+	return cause != types.DamageCauseZone && cause != types.DamageCauseFall
+}
+
+// absorbDamage takes as much of the damage as the victim's shield can hold, returning what's left for
+// their health
+func absorbDamage(victim *types.Player, damage int, cause types.DamageCause) int {
+	if victim.Shield <= 0 || !shieldAbsorbs(cause) {
+		return damage
+	}
+	absorbed := min(damage, victim.Shield)
+	victim.Shield -= absorbed
+	if victim.Shield == 0 {
+		logger.DebugLogger.Printf("Shield of player %s broke", victim.ID)
+	}
+	return damage - absorbed
+}
+
+// startingShield is the shield players (re)spawn with
+func (sm *StateManager) startingShield() int {
+	return min(sm.settings.StartingShield, sm.settings.MaxShield)
+}
+
+// addShield tops a player's shield up by amount, no higher than MaxShield
+func (sm *StateManager) addShield(player *types.Player, amount int) {
+	player.Shield = min(player.Shield+amount, sm.settings.MaxShield)
+}
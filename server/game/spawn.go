@@ -0,0 +1,250 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+const (
+	// Spawn points are kept between these fractions of the circle radius to avoid
+	// spawning right at the center or against the ring wall
+	spawnInnerRadiusFactor = 0.3
+	spawnOuterRadiusFactor = 0.95
+
+	// poissonCandidates is the number of candidates tried around each active sample
+	poissonCandidates = 30
+)
+
+// GenerateSpawnPoints generates count spawn points within a circle of the given radius
+// using Poisson-disk sampling, so no two points are closer than minDistance
+func GenerateSpawnPoints(count int, radius, minDistance float64, r *rand.Rand) []types.Vector3 {
+	samples := poissonDiskSample(radius, minDistance, r)
+
+	// Any subset of a Poisson-disk sample keeps the minimum separation, so pick a random one
+	r.Shuffle(len(samples), func(i, j int) {
+		samples[i], samples[j] = samples[j], samples[i]
+	})
+
+	if len(samples) < count {
+		logger.WarningLogger.Printf("Only %d of %d spawn points fit with a minimum distance of %.2f in radius %.2f",
+			len(samples), count, minDistance, radius)
+		return samples
+	}
+
+	return samples[:count]
+}
+
+// LoadSpawnPoints reads a map's spawn points from a JSON file containing an array of positions with optional
+// weights. Points beyond the edge of a world of the given radius are refused, players would be pushed back
+// inside the moment they moved.
+func LoadSpawnPoints(path string, worldRadius float64) ([]types.SpawnPoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var spawnPoints []types.SpawnPoint
+	if err := json.Unmarshal(data, &spawnPoints); err != nil {
+		return nil, err
+	}
+	for i, point := range spawnPoints {
+		if distance := math.Hypot(point.Position.X, point.Position.Z); distance > worldRadius-playerRadius {
+			return nil, fmt.Errorf("spawn point %d at (%.2f, %.2f) is %.2f from the center, outside the %.0f unit world",
+				i, point.Position.X, point.Position.Z, distance, worldRadius)
+		}
+	}
+	return spawnPoints, nil
+}
+
+// spawnWeight is how likely a spawn point is picked relative to the others, unweighted points count as 1
+func spawnWeight(point types.SpawnPoint) float64 {
+	if point.Weight <= 0 {
+		return 1
+	}
+	return point.Weight
+}
+
+// pickSpawnPoint picks one of the spawn points at random, each in proportion to its weight
+func pickSpawnPoint(spawnPoints []types.SpawnPoint, r *rand.Rand) types.SpawnPoint {
+	total := 0.0
+	for _, point := range spawnPoints {
+		total += spawnWeight(point)
+	}
+
+	pick := r.Float64() * total
+	for _, point := range spawnPoints {
+		pick -= spawnWeight(point)
+		if pick < 0 {
+			return point
+		}
+	}
+	// Rounding can leave a sliver past the last point
+	return spawnPoints[len(spawnPoints)-1]
+}
+
+// poissonDiskSample fills the spawn ring of a circle with points at least minDistance apart (Bridson's algorithm)
+func poissonDiskSample(radius, minDistance float64, r *rand.Rand) []types.Vector3 {
+	innerRadius := radius * spawnInnerRadiusFactor
+	outerRadius := radius * spawnOuterRadiusFactor
+
+	inSpawnRing := func(x, z float64) bool {
+		distance := math.Sqrt(x*x + z*z)
+		return distance >= innerRadius && distance <= outerRadius
+	}
+
+	// Background grid where each cell holds at most one sample
+	cellSize := minDistance / math.Sqrt2
+	gridSize := int(math.Ceil(2*outerRadius/cellSize)) + 1
+	grid := make([]int, gridSize*gridSize)
+	for i := range grid {
+		grid[i] = -1
+	}
+	cellOf := func(x, z float64) (int, int) {
+		return int((x + outerRadius) / cellSize), int((z + outerRadius) / cellSize)
+	}
+
+	var samples []types.Vector3
+	var active []int
+
+	addSample := func(x, z float64) {
+		samples = append(samples, types.Vector3{X: x, Y: 0.0, Z: z})
+		cx, cz := cellOf(x, z)
+		grid[cz*gridSize+cx] = len(samples) - 1
+		active = append(active, len(samples)-1)
+	}
+
+	farEnough := func(x, z float64) bool {
+		cx, cz := cellOf(x, z)
+		for gz := cz - 2; gz <= cz+2; gz++ {
+			for gx := cx - 2; gx <= cx+2; gx++ {
+				if gx < 0 || gz < 0 || gx >= gridSize || gz >= gridSize {
+					continue
+				}
+				index := grid[gz*gridSize+gx]
+				if index < 0 {
+					continue
+				}
+				dx := samples[index].X - x
+				dz := samples[index].Z - z
+				if dx*dx+dz*dz < minDistance*minDistance {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	// Seed with a random point in the spawn ring
+	angle := r.Float64() * 2 * math.Pi
+	distance := innerRadius + r.Float64()*(outerRadius-innerRadius)
+	addSample(math.Cos(angle)*distance, math.Sin(angle)*distance)
+
+	for len(active) > 0 {
+		activeIndex := r.Intn(len(active))
+		origin := samples[active[activeIndex]]
+
+		found := false
+		for i := 0; i < poissonCandidates; i++ {
+			// Try a candidate in the annulus between minDistance and 2*minDistance around the origin
+			angle := r.Float64() * 2 * math.Pi
+			distance := minDistance * (1 + r.Float64())
+			x := origin.X + math.Cos(angle)*distance
+			z := origin.Z + math.Sin(angle)*distance
+
+			if inSpawnRing(x, z) && farEnough(x, z) {
+				addSample(x, z)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			active[activeIndex] = active[len(active)-1]
+			active = active[:len(active)-1]
+		}
+	}
+
+	return samples
+}
+
+const (
+	// spawnCampRadius is how close deaths have to be to count as dying at the same spot
+	spawnCampRadius = 25.0
+	// spawnCampClearance is how much farther from that spot each repeated death moves the next respawn
+	spawnCampClearance = 100.0
+)
+
+// recordDeath remembers where a player died, forgetting deaths older than the spawn camp window
+func (sm *StateManager) recordDeath(player *types.Player) {
+	window := sm.settings.SpawnCampWindow.Seconds()
+	if window <= 0 {
+		player.RecentDeaths = nil
+		return
+	}
+
+	recent := player.RecentDeaths[:0]
+	for _, death := range player.RecentDeaths {
+		if sm.state.GameTime-death.GameTime <= window {
+			recent = append(recent, death)
+		}
+	}
+	player.RecentDeaths = append(recent, types.DeathRecord{Position: player.Position, GameTime: sm.state.GameTime})
+}
+
+// respawnPoint picks where a dead player comes back. A player who keeps dying at the same spot respawns
+// farther from it with every repeated death, falling back to the farthest spawn point there is.
+func (sm *StateManager) respawnPoint(player *types.Player) types.Vector3 {
+	if len(player.RecentDeaths) == 0 {
+		return sm.getRandomSpawnPoint(player.Team)
+	}
+
+	last := player.RecentDeaths[len(player.RecentDeaths)-1].Position
+	repeats := 0
+	for _, death := range player.RecentDeaths[:len(player.RecentDeaths)-1] {
+		if horizontalDistance(death.Position, last) <= spawnCampRadius {
+			repeats++
+		}
+	}
+	if repeats == 0 {
+		return sm.getRandomSpawnPoint(player.Team)
+	}
+
+	spawnPoints := sm.spawnPoints
+	if zone := sm.teamSpawnPoints[player.Team]; len(zone) > 0 {
+		spawnPoints = zone
+	}
+	if len(spawnPoints) == 0 {
+		return sm.getRandomSpawnPoint(player.Team)
+	}
+
+	clearance := float64(repeats) * spawnCampClearance
+	var candidates []types.SpawnPoint
+	farthest := spawnPoints[0].Position
+	for _, point := range spawnPoints {
+		distance := horizontalDistance(point.Position, last)
+		if distance >= clearance {
+			candidates = append(candidates, point)
+		}
+		if distance > horizontalDistance(farthest, last) {
+			farthest = point.Position
+		}
+	}
+
+	logger.DebugLogger.Printf("Player %s died %d times near (%.2f, %.2f), respawning at least %.2f away",
+		player.ID, repeats+1, last.X, last.Z, clearance)
+	if len(candidates) == 0 {
+		return farthest
+	}
+	return pickSpawnPoint(candidates, sm.rng).Position
+}
+
+// horizontalDistance is the distance between two positions on the ground plane
+func horizontalDistance(a, b types.Vector3) float64 {
+	return math.Hypot(a.X-b.X, a.Z-b.Z)
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"math"
+	"sort"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// SpectatorView decides how much of the match spectators and observers can see
+type SpectatorView string
+
+const (
+	// SpectatorViewNearby only shows spectators the players around whoever they follow, like a live player
+	// would see, so a second connection can't be used to ghost the match
+	SpectatorViewNearby SpectatorView = "nearby"
+	// SpectatorViewAll shows spectators and observers every player, e.g. for casting
+	SpectatorViewAll SpectatorView = "all"
+)
+
+// LateJoinPolicy decides what players joining while a round is under way do until the next one
+type LateJoinPolicy string
+
+const (
+	// LateJoinSpawn spawns late joiners straight into the running round
+	LateJoinSpawn LateJoinPolicy = "spawn"
+	// LateJoinSpectate makes late joiners spectate until the next round starts
+	LateJoinSpectate LateJoinPolicy = "spectate"
+)
+
+// joinsAsSpectator reports whether a player joining now has to wait for the next round, deathmatch
+// has no rounds to wait for so players always spawn right away. The caller must hold sm.mu.
+func (sm *StateManager) joinsAsSpectator() bool {
+	return sm.settings.LateJoin == LateJoinSpectate &&
+		sm.matchActive() &&
+		sm.settings.GameMode != types.GameModeDeathmatch
+}
+
+// makeSpectator turns an eliminated player into a spectator, freeing their player slot
+func (sm *StateManager) makeSpectator(player, killer *types.Player) {
+	player.IsSpectator = true
+	player.SpectatingID = ""
+	if sm.settings.SpectateKiller && killer != nil {
+		player.SpectatingID = killer.ID
+	}
+
+	logger.InfoLogger.Printf("Player %s is now spectating (following: %q)", player.ID, player.SpectatingID)
+}
+
+// setSpectateTarget points a spectator at a living player
+func (sm *StateManager) setSpectateTarget(spectator *types.Player, targetID string) error {
+	if !spectator.IsSpectator {
+		return types.ErrNotSpectator
+	}
+
+	target, exists := sm.state.Players[targetID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+	if target.ID == spectator.ID || !target.IsAlive || target.IsSpectator {
+		return types.ErrInvalidTarget
+	}
+
+	spectator.SpectatingID = targetID
+	logger.DebugLogger.Printf("Spectator %s is now following %s", spectator.ID, targetID)
+	return nil
+}
+
+// retargetSpectators moves spectators watching a player who just died on to someone still alive
+func (sm *StateManager) retargetSpectators(victim, killer *types.Player) {
+	for _, spectator := range sm.state.Players {
+		if !spectator.IsSpectator || spectator.SpectatingID != victim.ID {
+			continue
+		}
+
+		spectator.SpectatingID = sm.nextSpectateTarget(spectator, killer)
+		logger.DebugLogger.Printf("Spectator %s target %s died, now following %q", spectator.ID, victim.ID, spectator.SpectatingID)
+	}
+}
+
+// nextSpectateTarget picks whom a spectator follows next: the preferred player if alive,
+// otherwise the living player with the lowest ID, or nobody if everyone is dead
+func (sm *StateManager) nextSpectateTarget(spectator, preferred *types.Player) string {
+	if preferred != nil && preferred.IsAlive && !preferred.IsSpectator && preferred.ID != spectator.ID {
+		return preferred.ID
+	}
+
+	var candidates []string
+	for id, player := range sm.state.Players {
+		if player.IsAlive && !player.IsSpectator && id != spectator.ID {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.Strings(candidates)
+	return candidates[0]
+}
+
+// GetSpectatorState returns the game state a spectator sees, only around their target unless
+// spectators can see everyone. It returns false if the player isn't a spectator following someone.
+func (sm *StateManager) GetSpectatorState(id string) (*types.GameState, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	spectator, exists := sm.state.Players[id]
+	if !exists || !spectator.IsSpectator {
+		return nil, false
+	}
+
+	target, exists := sm.state.Players[spectator.SpectatingID]
+	if !exists {
+		return nil, false
+	}
+
+	if sm.settings.SpectatorView == SpectatorViewAll {
+		return sm.viewAround(nil, id, 0), true
+	}
+	return sm.viewAround(target, id, sm.settings.SpectatorViewRadius), true
+}
+
+// GetObserverState returns the game state read-only observers see. Unless spectators can see
+// everyone, it's the view around the player a new spectator would follow.
+func (sm *StateManager) GetObserverState() *types.GameState {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.settings.SpectatorView == SpectatorViewAll {
+		return sm.viewAround(nil, "", 0)
+	}
+	// Observers aren't players, so the empty player never matches anyone when picking a target
+	return sm.viewAround(sm.state.Players[sm.nextSpectateTarget(&types.Player{}, nil)], "", sm.settings.SpectatorViewRadius)
+}
+
+// viewAround copies the game state with only the players within radius of the target and the viewer
+// themselves, or every player when there is no target. The caller must hold sm.mu.
+func (sm *StateManager) viewAround(target *types.Player, viewerID string, radius float64) *types.GameState {
+	view := sm.copyState()
+	for playerID, player := range sm.state.Players {
+		visible := target == nil || playerID == viewerID
+		if !visible {
+			dx := player.Position.X - target.Position.X
+			dz := player.Position.Z - target.Position.Z
+			visible = math.Sqrt(dx*dx+dz*dz) <= radius
+		}
+		if visible {
+			view.Players[playerID] = copyPlayer(player)
+		}
+	}
+	return view
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/types"
+)
+
+// hitThresholdAt is how close a shot has to pass a player at the given distance to hit them.
+// Base threshold is 2.5 units at close range, we add 1.5 units per 10 units of distance.
+func hitThresholdAt(distance float64) float64 {
+	return 2.5 + distance*0.15
+}
+
+// addRecoil widens a player's spread after they fire a shot
+func addRecoil(player *types.Player, weapon Weapon) {
+	player.Spread = math.Min(weapon.MaxSpread, player.Spread+weapon.SpreadPerShot)
+}
+
+// recoverSpread narrows a player's spread as time passes without firing
+func recoverSpread(player *types.Player, deltaTime float64) {
+	if player.Spread <= 0 {
+		return
+	}
+
+	weapon, ok := LookupWeapon(player.CurrentWeapon)
+	if !ok {
+		player.Spread = 0
+		return
+	}
+	player.Spread = math.Max(0, player.Spread-weapon.SpreadRecovery*deltaTime)
+}
+
+// spreadAllowsHit decides whether a shot that lined up with a player at the given distance lands,
+// given the shooter's spread. While the spread cone fits within the hit threshold every shot lands,
+// beyond that the chance of landing falls with the share of the cone the threshold covers.
+func (sm *StateManager) spreadAllowsHit(shooter *types.Player, distance float64) bool {
+	cone := distance * math.Tan(shooter.Spread)
+	threshold := hitThresholdAt(distance)
+	if cone <= threshold {
+		return true
+	}
+
+	chance := (threshold / cone) * (threshold / cone)
+	return sm.rng.Float64() < chance
+}
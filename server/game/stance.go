@@ -0,0 +1,99 @@
+package game
+
+import (
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// hitHeightTolerance is how far above a player's head a shot may pass and still hit them
+const hitHeightTolerance = 0.1
+
+// stanceProfile describes the hitbox and movement of a stance
+type stanceProfile struct {
+	// height is the height of the hitbox and eyeHeight where shots are fired from
+	height    float64
+	eyeHeight float64
+	// speedMultiplier scales the move speed - matches stanceSpeedMultipliers in the client's types/game.ts
+	speedMultiplier float64
+}
+
+// stanceProfiles holds the profile of every stance a player can take
+var stanceProfiles = map[types.Stance]stanceProfile{
+	types.StanceStand:  {height: playerHeight, eyeHeight: 1.6, speedMultiplier: 1.0},
+	types.StanceCrouch: {height: 1.2, eyeHeight: 1.0, speedMultiplier: 0.5},
+	types.StanceProne:  {height: 0.5, eyeHeight: 0.3, speedMultiplier: 0.25},
+	types.StanceSprint: {height: playerHeight, eyeHeight: 1.6, speedMultiplier: 2.0},
+}
+
+// profileOf returns the profile of a player's stance, standing if they haven't picked one
+func profileOf(player *types.Player) stanceProfile {
+	if profile, ok := stanceProfiles[player.Stance]; ok {
+		return profile
+	}
+	return stanceProfiles[types.StanceStand]
+}
+
+// setStance changes how a player is standing
+func (sm *StateManager) setStance(player *types.Player, stance types.Stance) error {
+	if _, ok := stanceProfiles[stance]; !ok {
+		return types.ErrInvalidStance
+	}
+
+	if player.Stance != stance {
+		logger.DebugLogger.Printf("Player %s changed stance: %s -> %s", player.ID, player.Stance, stance)
+		player.Stance = stance
+	}
+	return nil
+}
+
+// eyePosition returns where a player's shots are fired from
+func eyePosition(player *types.Player) types.Vector3 {
+	position := player.Position
+	position.Y += profileOf(player).eyeHeight
+	return position
+}
+
+// headHeight is the height of the part of the hitbox counted as the head, and legsShare the share
+// of the hitbox from the feet up counted as the legs
+const (
+	headHeight = 0.3
+	legsShare  = 0.45
+)
+
+// hitZoneAt returns the part of a player's hitbox a shot passing them at point hits
+func hitZoneAt(player *types.Player, point types.Vector3) types.HitZone {
+	height := profileOf(player).height
+	switch {
+	case point.Y >= player.Position.Y+height-headHeight:
+		return types.HitZoneHead
+	case point.Y < player.Position.Y+height*legsShare:
+		return types.HitZoneLegs
+	}
+	return types.HitZoneBody
+}
+
+// zoneDamage scales the damage of a hit by the multiplier of the hitbox zone it landed in
+func (sm *StateManager) zoneDamage(damage float64, zone types.HitZone) int {
+	switch zone {
+	case types.HitZoneHead:
+		damage *= sm.settings.HeadshotMultiplier
+	case types.HitZoneLegs:
+		damage *= sm.settings.LegshotMultiplier
+	}
+	return int(math.Round(damage))
+}
+
+// hitCause is the damage cause of a hit in the zone, hits to the head count as headshots
+func hitCause(zone types.HitZone) types.DamageCause {
+	if zone == types.HitZoneHead {
+		return types.DamageCauseHeadshot
+	}
+	return types.DamageCauseWeapon
+}
+
+// passesOverHead reports whether a shot passing a player at point goes over the top of their hitbox
+func passesOverHead(player *types.Player, point types.Vector3) bool {
+	return point.Y > player.Position.Y+profileOf(player).height+hitHeightTolerance
+}
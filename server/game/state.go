@@ -1,15 +1,242 @@
 package game
 
 import (
+	"maps"
 	"math"
 	"math/rand"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
 	"finalcircle/server/logger"
 	"finalcircle/server/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultWorldRadius is the radius of the play area - matches the ringWallRadius in GameMap.ts
+	DefaultWorldRadius = 800.0
+	// DefaultMaxMoveSpeed is the fastest in units per second a standing player may move horizontally -
+	// above the client's walking speed in PlayerControls.ts
+	DefaultMaxMoveSpeed = 8.0
+
+	// playerRadius is the collision radius of a player - matches playerRadius in PlayerControls.ts
+	playerRadius = 0.5
+
+	// playerHeight is the height of a standing player's collision volume
+	playerHeight = 1.8
 )
 
+// Settings holds the tunable parameters of a StateManager
+type Settings struct {
+	MaxPlayers int
+	// TickRate is the number of game updates per second
+	TickRate int
+	// MinPlayers is the number of players needed to start a match
+	MinPlayers int
+
+	// AutoStart starts a match once MinPlayers have joined and ends it when too few are left
+	AutoStart bool
+	// AutoStartWarmup is how long enough players have to be connected before an auto-started match begins
+	AutoStartWarmup time.Duration
+
+	// WorldRadius is the radius of the circular play area, spawns and the safe zone scale with it
+	WorldRadius float64
+
+	// SpawnPointCount is the number of spawn points generated for the map
+	SpawnPointCount int
+	// SpawnMinDistance is the minimum distance kept between any two spawn points
+	SpawnMinDistance float64
+	// SpawnPoints are the spawn points a map defines, used instead of generated ones when set
+	SpawnPoints []types.SpawnPoint
+
+	// NameCollisionPolicy decides what happens when a player picks a name someone else already has
+	NameCollisionPolicy NameCollisionPolicy
+	// NameChangeCooldown is the minimum time between two name changes of a player
+	NameChangeCooldown time.Duration
+
+	// MapName is the name of the map the server is running
+	MapName string
+
+	// GameMode decides what happens when a player dies
+	GameMode types.GameMode
+	// SpectateKiller makes eliminated players follow their killer when they become spectators
+	SpectateKiller bool
+	// SpectatorViewRadius is how far around their target spectators can see other players
+	SpectatorViewRadius float64
+	// SpectatorView decides whether spectators and observers see every player or only those nearby
+	SpectatorView SpectatorView
+	// MaxViewDistance leaves players farther away than this out of the state a player receives,
+	// 0 sends everyone
+	MaxViewDistance float64
+	// PriorityRadius sends players within it, and those fighting the receiving player, in every state
+	// broadcast while everyone farther away only goes out every DistantUpdateEvery broadcasts. 0 sends
+	// everyone every time.
+	PriorityRadius     float64
+	DistantUpdateEvery int
+	// LateJoin decides whether players joining mid-round spawn or spectate until the next round
+	LateJoin LateJoinPolicy
+
+	// Scoring is the number of points each play is worth
+	Scoring Scoring
+	// RankBy decides whether players are ranked by kills or by score
+	RankBy RankBy
+
+	// StreakMilestones are the killstreak lengths that earn an achievement
+	StreakMilestones []int
+	// AchievementCooldown is the minimum time before a player can earn the same achievement again
+	AchievementCooldown time.Duration
+
+	// MaxFrameTime is the longest time step a single update simulates, so a stalled process
+	// doesn't make the game jump ahead in one frame
+	MaxFrameTime time.Duration
+	// MaxCatchUpSteps is how many MaxFrameTime steps an update may take to catch up after a stall,
+	// time beyond that is dropped
+	MaxCatchUpSteps int
+
+	// CoalesceMoves buffers moves and applies only each player's latest one per tick,
+	// instead of applying every move as it arrives
+	CoalesceMoves bool
+
+	// Obstacles is the solid map geometry players collide with
+	Obstacles []types.Obstacle
+	// ObstacleCollision stops moves that would pass through obstacles
+	ObstacleCollision bool
+	// MaxMoveSpeed is the fastest in units per second a standing player may move horizontally, scaled
+	// by the speed multiplier of other stances. Moves implying more are rejected and the player
+	// snapped back. 0 disables the check.
+	MaxMoveSpeed float64
+
+	// TeamCount is the number of teams players are split into, 0 for free-for-all
+	TeamCount int
+	// TeamWinTarget ends a team match once a team reaches that many kills, or points when ranking by
+	// score, and TeamTimeLimit ends it with the leading team winning. 0 disables either.
+	TeamWinTarget int
+	TeamTimeLimit time.Duration
+
+	// RespawnDelay is how long dead players wait before respawning in respawn modes
+	RespawnDelay time.Duration
+	// RespawnWaveInterval respawns dead players together in waves this far apart instead of after
+	// their own RespawnDelay, 0 disables waves
+	RespawnWaveInterval time.Duration
+	// SpawnProtection is how long respawned players can't take damage
+	SpawnProtection time.Duration
+	// SpawnCampWindow is how far back a player's deaths count when moving their respawn away from
+	// a spot they keep dying at, 0 disables it
+	SpawnCampWindow time.Duration
+
+	// StartingWeapons is the inventory every player spawns with, the first one is selected
+	StartingWeapons []string
+	// WeaponSwitchCooldown is the minimum time between two weapon switches
+	WeaponSwitchCooldown time.Duration
+	// EnforceFireRate rejects shots arriving faster than the weapon fires, FireRateTolerance earlier
+	// than its fire interval still passing to allow for network jitter
+	EnforceFireRate   bool
+	FireRateTolerance time.Duration
+	// HitRegMode decides whether the hits clients report with their shots are trusted
+	HitRegMode HitRegMode
+	// HeadshotMultiplier and LegshotMultiplier scale the damage of shots landing in the head or the legs
+	HeadshotMultiplier float64
+	LegshotMultiplier  float64
+	// MaxAimDeviation is how far in radians a shot may point away from the shooter's facing, 0 disables the check
+	MaxAimDeviation float64
+	// SelfDamage lets players' own explosives hurt them
+	SelfDamage bool
+	// FallDamage hurts players who land faster than FallDamageSpeed units per second, taking
+	// FallDamagePerSpeed damage for each unit per second beyond it
+	FallDamage         bool
+	FallDamageSpeed    float64
+	FallDamagePerSpeed float64
+	// HealthRegen heals players who go HealthRegenDelay without taking damage, HealthRegenRate points
+	// per second up to full health
+	HealthRegen      bool
+	HealthRegenDelay time.Duration
+	HealthRegenRate  float64
+
+	// ZonePhases is the safe zone schedule of a match, no zone is used when it's empty
+	ZonePhases []ZonePhase
+
+	// MaxMatchDuration force-ends matches that run longer, whatever their win conditions, 0 disables it
+	MaxMatchDuration time.Duration
+
+	// SupplyDropInterval is how often a supply drop is announced during a match, 0 disables them
+	SupplyDropInterval time.Duration
+	// SupplyDropDelay is how long after its announcement a supply drop lands
+	SupplyDropDelay time.Duration
+	// SupplyDropLoot are the weapons a supply drop can contain
+	SupplyDropLoot []string
+	// SupplyDropShield is the shield a supply drop carries besides its weapon
+	SupplyDropShield int
+	// StartingShield is the shield players spawn with and MaxShield the most they can carry
+	StartingShield int
+	MaxShield      int
+	// PickupRadius is how close a player has to get to an item to pick it up
+	PickupRadius float64
+}
+
+// DefaultSettings returns the settings used when nothing else is configured
+func DefaultSettings() Settings {
+	return Settings{
+		MaxPlayers:     50,
+		TickRate:       20,
+		MinPlayers:     2,
+		WorldRadius:    DefaultWorldRadius,
+		MapName:        "default",
+		GameMode:       types.GameModeElimination,
+		SpectateKiller: true,
+
+		NameCollisionPolicy: NameCollisionAllow,
+		NameChangeCooldown:  5 * time.Second,
+
+		SpectatorViewRadius: 150.0,
+		SpectatorView:       SpectatorViewNearby,
+		LateJoin:            LateJoinSpawn,
+		DistantUpdateEvery:  3,
+
+		ObstacleCollision: true,
+		MaxMoveSpeed:      DefaultMaxMoveSpeed,
+		SpawnPointCount:   20,
+		SpawnMinDistance:  150.0,
+		RespawnDelay:      3 * time.Second,
+		SpawnProtection:   2 * time.Second,
+		SpawnCampWindow:   30 * time.Second,
+
+		MaxFrameTime:    250 * time.Millisecond,
+		MaxCatchUpSteps: 1,
+
+		Scoring: DefaultScoring(),
+		RankBy:  RankByKills,
+
+		StreakMilestones:    []int{3, 5, 10},
+		AchievementCooldown: 10 * time.Second,
+
+		// Matches the loadout of the client's WeaponSystem
+		StartingWeapons:      []string{"RIFLE", "SMG", "PISTOL", "SNIPER", "KNIFE"},
+		WeaponSwitchCooldown: 250 * time.Millisecond,
+		FireRateTolerance:    30 * time.Millisecond,
+		HitRegMode:           HitRegServer,
+		HeadshotMultiplier:   2,
+		LegshotMultiplier:    0.75,
+		SelfDamage:           true,
+		FallDamageSpeed:      10,
+		FallDamagePerSpeed:   10,
+		HealthRegenDelay:     5 * time.Second,
+		HealthRegenRate:      10,
+
+		ZonePhases: DefaultZonePhases(),
+
+		MaxMatchDuration: time.Hour,
+
+		SupplyDropInterval: 90 * time.Second,
+		SupplyDropDelay:    15 * time.Second,
+		SupplyDropLoot:     []string{"SNIPER"},
+		PickupRadius:       2.0,
+		MaxShield:          100,
+	}
+}
+
 // StateManager handles the game state and player management
 type StateManager struct {
 	mu          sync.RWMutex
@@ -17,23 +244,75 @@ type StateManager struct {
 	lastUpdate  time.Time
 	updateRate  time.Duration
 	maxPlayers  int
-	spawnPoints []types.Vector3
+	spawnPoints []types.SpawnPoint
+	settings    Settings
+
+	// rng is the match's random source, seeded from the match seed so matches can be replayed
+	rng *rand.Rand
+
+	// teamSpawnPoints holds the spawn zone of each team in team modes
+	teamSpawnPoints map[string][]types.SpawnPoint
+
+	// pendingMoves are the latest moves of each player since the last tick, guarded by movesMu
+	// so queueing a move doesn't contend with the game state lock
+	pendingMoves map[string]types.PlayerActionData
+	movesMu      sync.Mutex
+
+	// events are messages produced by the game waiting to be delivered by the server
+	events []types.GameEvent
+
+	// warmupElapsed is how long enough players have been waiting for an auto-started match
+	warmupElapsed float64
+
+	// nextSupplyDrop is the game time of the next supply drop announcement
+	nextSupplyDrop float64
+	// nextRespawnWave is the game time of the next respawn wave, respawnQueue the dead players waiting for it
+	nextRespawnWave float64
+	respawnQueue    []string
+
+	// pendingDrops are announced supply drops that haven't landed yet
+	pendingDrops []pendingDrop
+	dropCount    int
+
+	// projectileCount numbers the projectiles fired this match
+	projectileCount int
+
+	// timeline are the significant events of the running match, timelines those of the last
+	// finished matches by match ID, oldest first in timelineOrder
+	timeline      []types.TimelineEvent
+	timelines     map[string][]types.TimelineEvent
+	timelineOrder []string
+
+	// peakPlayers is the most players that have been in the game at once
+	peakPlayers int
 }
 
 // NewStateManager creates a new game state manager
 func NewStateManager(maxPlayers int) *StateManager {
-	return &StateManager{
+	settings := DefaultSettings()
+	settings.MaxPlayers = maxPlayers
+	return NewStateManagerWithSettings(settings)
+}
+
+// NewStateManagerWithSettings creates a new game state manager using the given settings
+func NewStateManagerWithSettings(settings Settings) *StateManager {
+	sm := &StateManager{
 		state: &types.GameState{
-			Players:      make(map[string]*types.Player),
-			GameTime:     0,
-			IsGameActive: false,
-			MatchID:      generateMatchID(),
+			Players:    make(map[string]*types.Player),
+			GameTime:   0,
+			MatchState: types.MatchStateLobby,
+			MatchID:    generateMatchID(),
 		},
-		lastUpdate:  time.Now(),
-		updateRate:  time.Second / 60, // 60 updates per second
-		maxPlayers:  maxPlayers,
-		spawnPoints: generateSpawnPoints(),
+		lastUpdate: time.Now(),
+		updateRate: time.Second / 60, // 60 updates per second
+		maxPlayers: settings.MaxPlayers,
+		settings:   settings,
 	}
+	sm.seedMatch(time.Now().UnixNano())
+	sm.resetZone()
+	sm.resetSupplyDrops()
+	sm.resetProjectiles()
+	return sm
 }
 
 // Update updates the game state
@@ -42,69 +321,158 @@ func (sm *StateManager) Update() {
 	defer sm.mu.Unlock()
 
 	now := time.Now()
-	deltaTime := now.Sub(sm.lastUpdate).Seconds()
+	elapsed := now.Sub(sm.lastUpdate)
 	sm.lastUpdate = now
 
+	sm.advance(elapsed)
+}
+
+// Advance updates the game state by the elapsed wall-clock time, clamped to at most
+// MaxCatchUpSteps steps of MaxFrameTime
+func (sm *StateManager) Advance(elapsed time.Duration) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.lastUpdate = time.Now()
+	sm.advance(elapsed)
+}
+
+// advance simulates elapsed in steps of at most MaxFrameTime, the caller must hold sm.mu
+func (sm *StateManager) advance(elapsed time.Duration) {
+	maxFrame := sm.settings.MaxFrameTime
+	if maxFrame <= 0 {
+		sm.update(elapsed.Seconds())
+		return
+	}
+
+	steps := sm.settings.MaxCatchUpSteps
+	if steps < 1 {
+		steps = 1
+	}
+
+	remaining := elapsed
+	for i := 0; i < steps && remaining > 0; i++ {
+		step := min(remaining, maxFrame)
+		sm.update(step.Seconds())
+		remaining -= step
+	}
+
+	if remaining > 0 {
+		logger.WarningLogger.Printf("Update fell behind by %v, skipping %v of game time", elapsed, remaining)
+	}
+}
+
+// UpdateWithDelta updates the game state by a fixed time step in seconds
+func (sm *StateManager) UpdateWithDelta(deltaTime float64) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.lastUpdate = time.Now()
+	sm.update(deltaTime)
+}
+
+// update advances the game state by deltaTime seconds, the caller must hold sm.mu
+func (sm *StateManager) update(deltaTime float64) {
 	// Update game time
 	sm.state.GameTime += deltaTime
 
 	// Every 30 seconds, log a game status update
 	if int(sm.state.GameTime)%30 == 0 && deltaTime < 0.1 {
 		activePlayers := 0
-		highestKills := 0
+		highest := 0
 		leadingPlayer := ""
 
 		for id, player := range sm.state.Players {
 			if player.IsAlive {
 				activePlayers++
 			}
-			if player.Kills > highestKills {
-				highestKills = player.Kills
+			if points := sm.rankingPoints(player); points > highest {
+				highest = points
 				leadingPlayer = id
 			}
 		}
 
-		if sm.state.IsGameActive && len(sm.state.Players) > 0 {
+		if sm.matchActive() && len(sm.state.Players) > 0 {
 			leaderName := "None"
 			if leadingPlayer != "" {
 				leaderName = sm.state.Players[leadingPlayer].DisplayName
 			}
 
-			logger.InfoLogger.Printf("Game status update - Time: %.1f, Players: %d active/%d total, Leader: %s (%d kills)",
+			logger.InfoLogger.Printf("Game status update - Time: %.1f, Players: %d active/%d total, Leader: %s (%d %s)",
 				sm.state.GameTime, activePlayers, len(sm.state.Players),
-				leaderName, highestKills)
+				leaderName, highest, sm.settings.RankBy)
 		}
 	}
 
+	// Apply the moves buffered since the last tick
+	sm.applyQueuedMoves()
+
 	// Update player positions and handle actions
 	for _, player := range sm.state.Players {
+		updateMute(player, deltaTime)
+
 		if !player.IsAlive {
+			sm.updateRespawn(player, deltaTime)
 			continue
 		}
-		// Update player state based on their actions
-		// This will be expanded as we add more game mechanics
+
+		// Spawn protection wears off over time
+		if player.InvulnerableFor > 0 {
+			player.InvulnerableFor = math.Max(0, player.InvulnerableFor-deltaTime)
+		}
+
+		// Spread settles while the player isn't firing
+		recoverSpread(player, deltaTime)
+
+		// Reloads finish after the weapon's reload time
+		updateReload(player, deltaTime)
+
+		// Players in the middle of a jump rise and fall
+		sm.updateGravity(player, deltaTime)
+
+		// Players out of harm's way for a while heal up
+		sm.regenerateHealth(player, deltaTime)
 	}
 
+	// Dead players waiting for a respawn wave come back together
+	sm.updateRespawnWaves()
+
+	// Projectiles in flight move on and hit whoever they reach
+	sm.updateProjectiles(deltaTime)
+
+	// Burns, poison and other effects tick down
+	sm.updateEffects(deltaTime)
+
+	// Players outside the safe zone take damage
+	sm.updateZone(deltaTime)
+
+	// Supply drops are announced and land on their own timers
+	sm.updateSupplyDrops()
+
+	// Public servers start and stop matches on their own as players come and go
+	sm.updateAutoStart(deltaTime)
+
 	// Check for achievements and special events
 	sm.checkAchievements()
+
+	// Team modes end once a team reaches the target or the time runs out
+	sm.totalTeamScores()
+	sm.checkTeamWin()
+
+	// Runaway matches are cut off
+	if sm.matchActive() && sm.settings.MaxMatchDuration > 0 && sm.state.GameTime >= sm.settings.MaxMatchDuration.Seconds() {
+		logger.WarningLogger.Printf("Match %s exceeded the maximum duration of %v, ending it", sm.state.MatchID, sm.settings.MaxMatchDuration)
+		sm.endGame()
+	}
 }
 
 // checkAchievements checks for special game events and achievements
 func (sm *StateManager) checkAchievements() {
-	if !sm.state.IsGameActive || len(sm.state.Players) < 2 {
+	if !sm.matchActive() || len(sm.state.Players) < 2 {
 		return
 	}
 
-	// Find players with killstreaks
-	for id, player := range sm.state.Players {
-		// This would be better tracked with a dedicated killstreak field
-		// For now, we'll just use the current kills as an approximation
-		if player.Kills > 0 && player.Kills%5 == 0 && player.IsAlive {
-			// Only log once when they reach each multiple of 5
-			logger.DebugLogger.Printf("ACHIEVEMENT: Player %s (%s) is on a %d kill streak!",
-				id, player.DisplayName, player.Kills)
-		}
-	}
+	// Killstreak and revenge achievements are awarded as kills happen, see awardKillAchievements
 
 	// Check for close matches (when two players have similar high scores)
 	var topPlayers []struct {
@@ -156,9 +524,9 @@ func (sm *StateManager) AddPlayer(id string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	if len(sm.state.Players) >= sm.maxPlayers {
+	if sm.activePlayerCount() >= sm.maxPlayers {
 		logger.InfoLogger.Printf("Player join rejected: server full (max: %d)", sm.maxPlayers)
-		return types.ErrGameNotActive
+		return types.ErrServerFull
 	}
 
 	if _, exists := sm.state.Players[id]; exists {
@@ -166,19 +534,45 @@ func (sm *StateManager) AddPlayer(id string) error {
 		return types.ErrPlayerAlreadyExists
 	}
 
-	// Find a random spawn point
-	spawnPoint := sm.getRandomSpawnPoint()
+	// Find a random spawn point in the player's team zone
+	team := sm.assignTeam()
+	spawnPoint := sm.getRandomSpawnPoint(team)
+
+	// Default name using part of the ID, account IDs from auth tokens may be shorter than generated ones
+	shortID := id
+	if len(shortID) > 5 {
+		shortID = shortID[:5]
+	}
 
-	sm.state.Players[id] = &types.Player{
-		ID:          id,
-		DisplayName: "Player " + id[:5], // Default name using part of the ID
-		Position:    spawnPoint,
-		Rotation:    types.Vector3{X: 0, Y: 0, Z: 0},
-		Health:      100,
-		IsAlive:     true,
-		Kills:       0,
-		Deaths:      0,
+	player := &types.Player{
+		ID: id,
+		PlayerInfo: types.PlayerInfo{
+			DisplayName: "Player " + shortID,
+			Team:        team,
+		},
+		Position: spawnPoint,
+		Rotation: types.Vector3{X: 0, Y: 0, Z: 0},
+		Health:   100,
+		Shield:   sm.startingShield(),
+		IsAlive:  true,
+		// Moves are checked against the speed limit from the spawn point on
+		LastMoveTime: time.Now(),
+		Kills:        0,
+		Deaths:       0,
+		Stance:       types.StanceStand,
+	}
+	sm.giveStartingWeapons(player)
+
+	// Late joiners waiting for the next round take no part in this one, startGame brings them in
+	if sm.joinsAsSpectator() {
+		player.IsAlive = false
+		player.IsSpectator = true
+		player.SpectatingID = sm.nextSpectateTarget(player, nil)
+		logger.InfoLogger.Printf("Player %s joined mid-round, spectating until the next one (following: %q)", id, player.SpectatingID)
 	}
+	sm.state.Players[id] = player
+	sm.peakPlayers = max(sm.peakPlayers, len(sm.state.Players))
+	sm.emitPlayerUpdate(player)
 
 	logger.InfoLogger.Printf("Player added: %s at position (%.2f, %.2f, %.2f), distance from center: %.2f",
 		id, spawnPoint.X, spawnPoint.Y, spawnPoint.Z,
@@ -199,414 +593,575 @@ func (sm *StateManager) RemovePlayer(id string) error {
 
 	logger.DebugLogger.Printf("Player removed: %s (Kills: %d, Deaths: %d)", id, player.Kills, player.Deaths)
 	delete(sm.state.Players, id)
+	sm.recordDeparture(player)
 	return nil
 }
 
-// GetState returns the current game state
-func (sm *StateManager) GetState() *types.GameState {
+// MaxPlayers returns the number of player slots
+func (sm *StateManager) MaxPlayers() int {
+	return sm.maxPlayers
+}
+
+// IsFull reports whether every player slot is taken, so AddPlayer would refuse another player
+func (sm *StateManager) IsFull() bool {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
-	return sm.state
+
+	return sm.activePlayerCount() >= sm.maxPlayers
 }
 
-// HandlePlayerAction processes a player's action
-func (sm *StateManager) HandlePlayerAction(id string, action types.PlayerAction) error {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
+// GameMode returns the rules matches are played with
+func (sm *StateManager) GameMode() types.GameMode {
+	return sm.settings.GameMode
+}
 
-	player, exists := sm.state.Players[id]
-	if !exists {
-		return types.ErrPlayerNotFound
-	}
+// MapName returns the name of the map
+func (sm *StateManager) MapName() string {
+	return sm.settings.MapName
+}
 
-	if !player.IsAlive {
-		return types.ErrPlayerDead
-	}
+// Summary is a snapshot of the headline numbers of a game
+type Summary struct {
+	Players     int
+	PeakPlayers int
+	GameActive  bool
+	GameTime    float64
+	MatchID     string
+	GameMode    types.GameMode
+	MapName     string
+}
 
-	switch action.Type {
-	case "move":
-		if action.Data.Position != nil {
-			player.Position = *action.Data.Position
-		}
-		if action.Data.Rotation != nil {
-			player.Rotation = *action.Data.Rotation
-		}
-	case "jump":
-		// Could add jump mechanics here
-	case "shoot":
-		if action.Data.Target != nil {
-			sm.HandleShot(id, *action.Data.Target, action.Data.Damage)
-		} else if action.Data.Direction != nil {
-			sm.HandleDirectionalShot(id, *action.Data.Direction, action.Data.Damage)
-		}
-	case "reload":
-		// Reload is handled client-side for now
-	case "heal":
-		// Handle healing action
-		sm.HandleHealAction(id, action)
-	default:
-		return types.ErrInvalidActionType
+// Summary returns a snapshot of the game's headline numbers
+func (sm *StateManager) Summary() Summary {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return Summary{
+		Players:     len(sm.state.Players),
+		PeakPlayers: sm.peakPlayers,
+		GameActive:  sm.matchActive(),
+		GameTime:    sm.state.GameTime,
+		MatchID:     sm.state.MatchID,
+		GameMode:    sm.settings.GameMode,
+		MapName:     sm.settings.MapName,
 	}
+}
 
-	return nil
+// GetZone returns a snapshot of the safe zone
+func (sm *StateManager) GetZone() types.SafeZone {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return sm.state.Zone
 }
 
-// HandleShot handles a player's shot
-func (sm *StateManager) HandleShot(shooterId string, target types.Vector3, damagePtr *int) {
-	shooter := sm.state.Players[shooterId]
-	hitRegistered := false
+// ZoneEnabled reports whether matches are played with a shrinking safe zone
+func (sm *StateManager) ZoneEnabled() bool {
+	return len(sm.settings.ZonePhases) > 0
+}
 
-	logger.DebugLogger.Printf("Processing shot from player %s", shooterId)
-	logger.DebugLogger.Printf("Shot target position: (%.2f, %.2f, %.2f)", target.X, target.Y, target.Z)
-	logger.DebugLogger.Printf("Shooter position: (%.2f, %.2f, %.2f)", shooter.Position.X, shooter.Position.Y, shooter.Position.Z)
+// TickRate returns the number of game updates per second
+func (sm *StateManager) TickRate() int {
+	return sm.settings.TickRate
+}
 
-	// Calculate ray direction from shooter to target
-	rayDirection := types.Vector3{
-		X: target.X - shooter.Position.X,
-		Y: target.Y - shooter.Position.Y,
-		Z: target.Z - shooter.Position.Z,
-	}
+// WorldRadius returns the radius of the play area
+func (sm *StateManager) WorldRadius() float64 {
+	return sm.settings.WorldRadius
+}
 
-	// Normalize ray direction
-	rayLength := math.Sqrt(rayDirection.X*rayDirection.X + rayDirection.Y*rayDirection.Y + rayDirection.Z*rayDirection.Z)
-	if rayLength > 0 {
-		rayDirection.X /= rayLength
-		rayDirection.Y /= rayLength
-		rayDirection.Z /= rayLength
-	}
+// Obstacles returns the solid map geometry
+func (sm *StateManager) Obstacles() []types.Obstacle {
+	return sm.settings.Obstacles
+}
 
-	// Log how many potential targets we're checking
-	playerCount := 0
-	for id, player := range sm.state.Players {
-		if id != shooterId && player.IsAlive {
-			playerCount++
-		}
+// GetPlayerPosition returns the authoritative position of a player
+func (sm *StateManager) GetPlayerPosition(id string) (types.Vector3, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	player, exists := sm.state.Players[id]
+	if !exists {
+		return types.Vector3{}, false
 	}
-	logger.DebugLogger.Printf("Checking shot against %d potential targets", playerCount)
+	return player.Position, true
+}
 
-	// Find the closest hit player (if any)
-	var closestHitPlayer *types.Player
-	var closestHitPlayerId string
-	closestDistance := math.MaxFloat64
+// GetPlayerName returns the display name of a player
+func (sm *StateManager) GetPlayerName(id string) (string, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 
-	// Check all players to see if they were hit
-	for id, player := range sm.state.Players {
-		// Skip the shooter
-		if id == shooterId {
-			continue
-		}
+	player, exists := sm.state.Players[id]
+	if !exists {
+		return "", false
+	}
+	return player.DisplayName, true
+}
 
-		// Skip already dead players
-		if !player.IsAlive {
-			continue
-		}
+// DrainEvents returns the game events produced since the last call and clears them
+func (sm *StateManager) DrainEvents() []types.GameEvent {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
-		// Calculate vector from shooter to the player
-		toPlayer := types.Vector3{
-			X: player.Position.X - shooter.Position.X,
-			Y: player.Position.Y - shooter.Position.Y,
-			Z: player.Position.Z - shooter.Position.Z,
-		}
+	events := sm.events
+	sm.events = nil
+	return events
+}
 
-		// Calculate the dot product to find the projection of toPlayer onto rayDirection
-		dotProduct := toPlayer.X*rayDirection.X + toPlayer.Y*rayDirection.Y + toPlayer.Z*rayDirection.Z
+// emit queues a game event for delivery, the caller must hold sm.mu
+func (sm *StateManager) emit(msgType types.MessageType, playerID string, payload interface{}) {
+	sm.events = append(sm.events, types.GameEvent{
+		Type:     msgType,
+		PlayerID: playerID,
+		Payload:  payload,
+	})
+}
 
-		// If the player is behind the shooter, skip
-		if dotProduct <= 0 {
-			logger.DebugLogger.Printf("Player %s is behind the shooter, skipping", id)
-			continue
-		}
+// GetPlayerStats returns a snapshot of a player's current stats
+func (sm *StateManager) GetPlayerStats(id string) (types.PlayerStats, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 
-		// Calculate closest point on ray to player
-		closestPoint := types.Vector3{
-			X: shooter.Position.X + rayDirection.X*dotProduct,
-			Y: shooter.Position.Y + rayDirection.Y*dotProduct,
-			Z: shooter.Position.Z + rayDirection.Z*dotProduct,
-		}
+	player, exists := sm.state.Players[id]
+	if !exists {
+		return types.PlayerStats{}, false
+	}
+	return statsOf(player), true
+}
 
-		// Calculate distance from closest point to player (perpendicular distance)
-		dx := player.Position.X - closestPoint.X
-		dy := player.Position.Y - closestPoint.Y
-		dz := player.Position.Z - closestPoint.Z
-		perpendicularDistance := math.Sqrt(dx*dx + dy*dy + dz*dz)
-
-		// Calculate a distance-sensitive hit threshold
-		// Base threshold is 2.5 units at close range
-		// We add 1.5 units per 10 units of distance
-		hitThreshold := 2.5 + (dotProduct * 0.15)
-
-		logger.DebugLogger.Printf("Checking player %s at position (%.2f, %.2f, %.2f), distance along ray: %.2f, perpendicular distance: %.2f, hit threshold: %.2f",
-			id, player.Position.X, player.Position.Y, player.Position.Z, dotProduct, perpendicularDistance, hitThreshold)
-
-		// If the shot hit (ray passes within the calculated threshold of the player)
-		if perpendicularDistance < hitThreshold && dotProduct < closestDistance {
-			closestDistance = dotProduct
-			closestHitPlayer = player
-			closestHitPlayerId = id
-		} else {
-			logger.DebugLogger.Printf("Shot missed player %s - perpendicular distance %.2f > hit threshold %.2f", id, perpendicularDistance, hitThreshold)
-		}
+// statsOf summarizes a player's stats
+func statsOf(player *types.Player) types.PlayerStats {
+	// K/D is the kill count until the first death
+	kd := float64(player.Kills)
+	if player.Deaths > 0 {
+		kd = float64(player.Kills) / float64(player.Deaths)
 	}
 
-	// Process the hit on the closest player
-	if closestHitPlayer != nil {
-		oldHealth := closestHitPlayer.Health
+	return types.PlayerStats{
+		ID:          player.ID,
+		DisplayName: player.DisplayName,
+		Team:        player.Team,
+		Kills:       player.Kills,
+		Deaths:      player.Deaths,
+		Assists:     player.Assists,
+		Score:       player.Score,
+		KD:          kd,
+		Health:      player.Health,
+		IsAlive:     player.IsAlive,
+		Placement:   player.Placement,
+	}
+}
 
-		// Get damage from the action payload if available
-		damage := 20 // Default damage as fallback
-		if damagePtr != nil {
-			damage = *damagePtr
-		}
+// emitPlayerUpdate announces a player's slow-changing fields to everyone, the caller must hold sm.mu
+func (sm *StateManager) emitPlayerUpdate(player *types.Player) {
+	sm.emit(types.MessageTypePlayerUpdate, "", types.PlayerUpdatePayload{
+		PlayerID:   player.ID,
+		PlayerInfo: copyPlayerInfo(player.PlayerInfo),
+	})
+}
 
-		// Reduce health
-		closestHitPlayer.Health -= damage
+// PlayerUpdates returns the slow-changing fields of every player, for clients that just connected
+func (sm *StateManager) PlayerUpdates() []types.PlayerUpdatePayload {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 
-		logger.DebugLogger.Printf("Player %s hit player %s (health: %d -> %d, distance: %.2f, damage: %d)",
-			shooterId, closestHitPlayerId, oldHealth, closestHitPlayer.Health, closestDistance, damage)
+	updates := make([]types.PlayerUpdatePayload, 0, len(sm.state.Players))
+	for id, player := range sm.state.Players {
+		updates = append(updates, types.PlayerUpdatePayload{
+			PlayerID:   id,
+			PlayerInfo: copyPlayerInfo(player.PlayerInfo),
+		})
+	}
+	return updates
+}
 
-		hitRegistered = true
+// copyPlayerInfo copies player info so later inventory changes don't leak into queued messages
+func copyPlayerInfo(info types.PlayerInfo) types.PlayerInfo {
+	info.Weapons = append([]string(nil), info.Weapons...)
+	return info
+}
 
-		// Check if player died
-		if closestHitPlayer.Health <= 0 {
-			closestHitPlayer.IsAlive = false
-			closestHitPlayer.Health = 0
-			closestHitPlayer.Deaths++
-			shooter.Kills++
+// GetState returns a copy of the current game state, safe to read and marshal without holding any lock
+func (sm *StateManager) GetState() *types.GameState {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
 
-			logger.InfoLogger.Printf("Player %s killed by %s (kills: %d, deaths: %d)",
-				closestHitPlayerId, shooterId, shooter.Kills, closestHitPlayer.Deaths)
+	state := sm.copyState()
+	for id, player := range sm.state.Players {
+		state.Players[id] = copyPlayer(player)
+	}
+	return state
+}
 
-			// No automatic respawn - players stay dead until the next round
+// copyState copies the game state without any players, for the caller to add the ones it wants with
+// copyPlayer. The caller must hold sm.mu.
+func (sm *StateManager) copyState() *types.GameState {
+	state := *sm.state
+	state.Players = make(map[string]*types.Player)
+	if sm.state.Pickups != nil {
+		state.Pickups = make(map[string]*types.Pickup, len(sm.state.Pickups))
+		for id, pickup := range sm.state.Pickups {
+			pickupCopy := *pickup
+			state.Pickups[id] = &pickupCopy
 		}
 	}
-
-	if !hitRegistered {
-		logger.DebugLogger.Printf("Summary: Shot from player %s did not hit any targets", shooterId)
-	} else {
-		logger.DebugLogger.Printf("Summary: Shot from player %s registered a hit", shooterId)
+	if sm.state.Projectiles != nil {
+		state.Projectiles = make(map[string]*types.Projectile, len(sm.state.Projectiles))
+		for id, projectile := range sm.state.Projectiles {
+			projectileCopy := *projectile
+			state.Projectiles[id] = &projectileCopy
+		}
 	}
+	state.KillFeed = slices.Clone(sm.state.KillFeed)
+	state.Eliminations = slices.Clone(sm.state.Eliminations)
+	state.Deferred = slices.Clone(sm.state.Deferred)
+	state.Teams = maps.Clone(sm.state.Teams)
+	return &state
 }
 
-// HandleDirectionalShot handles a shot fired with a direction vector
-func (sm *StateManager) HandleDirectionalShot(shooterId string, direction types.Vector3, damagePtr *int) {
-	shooter := sm.state.Players[shooterId]
-	hitRegistered := false
+// copyPlayer copies a player along with the maps and slices the game keeps changing in place, so the
+// copy can be marshalled while the game goes on. The caller must hold sm.mu.
+func copyPlayer(player *types.Player) *types.Player {
+	playerCopy := *player
+	playerCopy.PlayerInfo = copyPlayerInfo(player.PlayerInfo)
+	playerCopy.Effects = slices.Clone(player.Effects)
+	playerCopy.Ammo = maps.Clone(player.Ammo)
+	playerCopy.ReserveAmmo = maps.Clone(player.ReserveAmmo)
+	return &playerCopy
+}
 
-	logger.DebugLogger.Printf("Processing directional shot from player %s", shooterId)
-	logger.DebugLogger.Printf("Shot direction: (%.2f, %.2f, %.2f)", direction.X, direction.Y, direction.Z)
-	logger.DebugLogger.Printf("Shooter position: (%.2f, %.2f, %.2f)", shooter.Position.X, shooter.Position.Y, shooter.Position.Z)
+// HandlePlayerAction processes a player's action
+func (sm *StateManager) HandlePlayerAction(id string, action types.PlayerAction) error {
+	// Moves wait for the next tick so only the latest one is simulated
+	if action.Type == "move" && sm.settings.CoalesceMoves {
+		sm.queueMove(id, action.Data)
+		return nil
+	}
 
-	// Normalize direction
-	magnitude := math.Sqrt(direction.X*direction.X + direction.Y*direction.Y + direction.Z*direction.Z)
-	if magnitude > 0 {
-		direction.X /= magnitude
-		direction.Y /= magnitude
-		direction.Z /= magnitude
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[id]
+	if !exists {
+		return types.ErrPlayerNotFound
 	}
 
-	// Log how many potential targets we're checking
-	playerCount := 0
-	for id, player := range sm.state.Players {
-		if id != shooterId && player.IsAlive {
-			playerCount++
-		}
+	// Choosing whom to watch is the one thing spectators can do
+	if action.Type == "spectateTarget" {
+		return sm.setSpectateTarget(player, action.Data.TargetID)
 	}
-	logger.DebugLogger.Printf("Checking shot against %d potential targets", playerCount)
 
-	// Find the closest hit player (if any)
-	var closestHitPlayer *types.Player
-	var closestHitPlayerId string
-	closestDistance := math.MaxFloat64
+	if !player.IsAlive {
+		return types.ErrPlayerDead
+	}
 
-	// Check all players to see if they were hit
-	for id, player := range sm.state.Players {
-		// Skip the shooter
-		if id == shooterId {
-			continue
+	switch action.Type {
+	case "move":
+		sm.applyMove(player, action.Data)
+	case "jump":
+		return sm.jump(player)
+	case "shoot":
+		// Players can walk around the lobby, but fighting waits for the match
+		if !sm.matchActive() {
+			return types.ErrCombatDisabled
 		}
 
-		// Skip already dead players
-		if !player.IsAlive {
-			continue
+		// A shot fired with another weapon implies switching to it first
+		if action.Data.WeaponID != "" && action.Data.WeaponID != player.CurrentWeapon {
+			if err := sm.switchWeapon(player, action.Data.WeaponID); err != nil {
+				return err
+			}
 		}
 
-		// Calculate vector from shooter to the player
-		toPlayer := types.Vector3{
-			X: player.Position.X - shooter.Position.X,
-			Y: player.Position.Y - shooter.Position.Y,
-			Z: player.Position.Z - shooter.Position.Z,
+		weapon, ok := LookupWeapon(player.CurrentWeapon)
+		if !ok {
+			return types.ErrUnknownWeapon
 		}
-
-		// Calculate the dot product to find the projection of toPlayer onto direction
-		dotProduct := toPlayer.X*direction.X + toPlayer.Y*direction.Y + toPlayer.Z*direction.Z
-
-		// If the player is behind the shooter, skip
-		if dotProduct <= 0 {
-			logger.DebugLogger.Printf("Player %s is behind the shooter, skipping", id)
-			continue
+		if err := sm.checkAim(player, action.Data); err != nil {
+			return err
 		}
 
-		// Calculate closest point on ray to player
-		closestPoint := types.Vector3{
-			X: shooter.Position.X + direction.X*dotProduct,
-			Y: shooter.Position.Y + direction.Y*dotProduct,
-			Z: shooter.Position.Z + direction.Z*dotProduct,
+		if err := sm.checkFireRate(player, weapon); err != nil {
+			return err
 		}
-
-		// Calculate distance from closest point to player (perpendicular distance)
-		dx := player.Position.X - closestPoint.X
-		dy := player.Position.Y - closestPoint.Y
-		dz := player.Position.Z - closestPoint.Z
-		perpendicularDistance := math.Sqrt(dx*dx + dy*dy + dz*dz)
-
-		// Calculate a distance-sensitive hit threshold
-		// Base threshold is 2.5 units at close range
-		// We add 1.5 units per 10 units of distance
-		hitThreshold := 2.5 + (dotProduct * 0.15)
-
-		logger.DebugLogger.Printf("Checking player %s at position (%.2f, %.2f, %.2f), distance along ray: %.2f, perpendicular distance: %.2f, hit threshold: %.2f",
-			id, player.Position.X, player.Position.Y, player.Position.Z, dotProduct, perpendicularDistance, hitThreshold)
-
-		// If the shot hit (ray passes within the calculated threshold of the player)
-		if perpendicularDistance < hitThreshold && dotProduct < closestDistance {
-			closestDistance = dotProduct
-			closestHitPlayer = player
-			closestHitPlayerId = id
-		} else {
-			logger.DebugLogger.Printf("Shot missed player %s - perpendicular distance %.2f > hit threshold %.2f", id, perpendicularDistance, hitThreshold)
+		if err := useAmmo(player, weapon); err != nil {
+			return err
 		}
-	}
-
-	// Process the hit on the closest player
-	if closestHitPlayer != nil {
-		oldHealth := closestHitPlayer.Health
-
-		// Get damage from the action payload if available
-		damage := 20 // Default damage as fallback
-		if damagePtr != nil {
-			damage = *damagePtr
+		player.LastShotTime = time.Now()
+
+		// Firing gives up spawn protection
+		player.InvulnerableFor = 0
+
+		switch {
+		case weapon.ProjectileSpeed > 0:
+			sm.fireProjectile(player, weapon, action.Data)
+		case sm.handleClaimedShot(player, action.Data, weapon):
+			// The hit the client reported was accepted
+		case action.Data.Target != nil:
+			sm.HandleShot(id, *action.Data.Target, weapon)
+		case action.Data.Direction != nil:
+			sm.HandleDirectionalShot(id, *action.Data.Direction, weapon)
 		}
+		addRecoil(player, weapon)
+	case "switchWeapon":
+		return sm.switchWeapon(player, action.Data.WeaponID)
+	case "setStance":
+		return sm.setStance(player, action.Data.Stance)
+	case "reload":
+		return startReload(player)
+	case "heal":
+		// Handle healing action
+		sm.HandleHealAction(id, action)
+	default:
+		return types.ErrInvalidActionType
+	}
 
-		// Reduce health based on damage
-		closestHitPlayer.Health -= damage
+	return nil
+}
 
-		logger.DebugLogger.Printf("Player %s hit player %s (health: %d -> %d, distance: %.2f, damage: %d)",
-			shooterId, closestHitPlayerId, oldHealth, closestHitPlayer.Health, closestDistance, damage)
+// HandleShot handles a player's shot at a target position
+func (sm *StateManager) HandleShot(shooterId string, target types.Vector3, weapon Weapon) {
+	shooter := sm.state.Players[shooterId]
 
-		hitRegistered = true
+	logger.DebugLogger.Printf("Processing shot from player %s", shooterId)
+	logger.DebugLogger.Printf("Shot target position: (%.2f, %.2f, %.2f)", target.X, target.Y, target.Z)
 
-		// Check if player died
-		if closestHitPlayer.Health <= 0 {
-			closestHitPlayer.IsAlive = false
-			closestHitPlayer.Health = 0
-			closestHitPlayer.Deaths++
-			shooter.Kills++
+	// Calculate ray direction from the shooter's eyes to target
+	origin := eyePosition(shooter)
+	rayDirection := types.Vector3{
+		X: target.X - origin.X,
+		Y: target.Y - origin.Y,
+		Z: target.Z - origin.Z,
+	}
+	sm.fireHitscan(shooter, origin, rayDirection, weapon)
+}
 
-			logger.InfoLogger.Printf("Player %s killed by %s (kills: %d, deaths: %d)",
-				closestHitPlayerId, shooterId, shooter.Kills, closestHitPlayer.Deaths)
+// HandleDirectionalShot handles a shot fired with a direction vector
+func (sm *StateManager) HandleDirectionalShot(shooterId string, direction types.Vector3, weapon Weapon) {
+	shooter := sm.state.Players[shooterId]
 
-			// No automatic respawn - players stay dead until the next round
-		}
-	}
+	logger.DebugLogger.Printf("Processing directional shot from player %s", shooterId)
+	logger.DebugLogger.Printf("Shot direction: (%.2f, %.2f, %.2f)", direction.X, direction.Y, direction.Z)
 
-	if !hitRegistered {
-		logger.DebugLogger.Printf("Summary: Shot from player %s did not hit any targets", shooterId)
-	} else {
-		logger.DebugLogger.Printf("Summary: Shot from player %s registered a hit", shooterId)
-	}
+	// Shots are fired from the shooter's eyes
+	sm.fireHitscan(shooter, eyePosition(shooter), direction, weapon)
 }
 
-// StartGame starts a new game
+// StartGame starts a new game with a seed derived from the current time
 func (sm *StateManager) StartGame() error {
+	return sm.StartGameWithSeed(time.Now().UnixNano())
+}
+
+// StartGameWithSeed starts a new game whose randomness (spawns, supply drops) all derives from the seed,
+// so the same seed and inputs replay the same match
+func (sm *StateManager) StartGameWithSeed(seed int64) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	if len(sm.state.Players) < 2 {
-		logger.InfoLogger.Printf("Game start rejected: not enough players (%d/2)", len(sm.state.Players))
-		return types.ErrGameNotActive
+	return sm.startGame(seed)
+}
+
+// startGame starts a new match, the caller must hold sm.mu
+func (sm *StateManager) startGame(seed int64) error {
+	if err := sm.checkEnoughPlayers(); err != nil {
+		return err
 	}
 
-	// Respawn all players at the start of a new round
-	for id, player := range sm.state.Players {
+	// A match restarted before it ended keeps what happened so far
+	if sm.matchActive() {
+		sm.archiveTimeline()
+	}
+	sm.seedMatch(seed)
+
+	// Respawn all players at the start of a new round, in a stable order so the seed decides who spawns where
+	for _, player := range sm.sortedPlayers() {
+		// Spectators from the last round rejoin while there are free player slots
+		if player.IsSpectator {
+			if sm.activePlayerCount() >= sm.maxPlayers {
+				continue
+			}
+			player.IsSpectator = false
+			player.SpectatingID = ""
+		}
+
 		// Reset player health
 		player.Health = 100
+		player.Shield = sm.startingShield()
 		player.IsAlive = true
+		player.RespawnIn = 0
+		player.InvulnerableFor = 0
+		player.Killstreak = 0
+		player.ZoneDamage = 0
+		player.Attackers = nil
+		player.LastDamagedBy = nil
+		player.RecentDeaths = nil
+		player.Effects = nil
+		player.Stance = types.StanceStand
+		player.Placement = 0
+		player.Spread = 0
+		player.Airborne = false
+		player.VerticalVelocity = 0
+		refillAmmo(player)
 
 		// Assign a random spawn point
-		spawnPoint := sm.getRandomSpawnPoint()
+		spawnPoint := sm.getRandomSpawnPoint(player.Team)
 		player.Position = spawnPoint
+		player.LastMoveTime = time.Now()
 
 		logger.InfoLogger.Printf("Player %s respawned at position (%.2f, %.2f, %.2f) for new round",
-			id, spawnPoint.X, spawnPoint.Y, spawnPoint.Z)
+			player.ID, spawnPoint.X, spawnPoint.Y, spawnPoint.Z)
 	}
 
-	sm.state.IsGameActive = true
+	sm.setMatchState(types.MatchStateActive)
 	sm.state.GameTime = 0
 	sm.state.MatchID = generateMatchID()
+	sm.state.KillFeed = nil
+	sm.state.Eliminations = nil
+	sm.state.Winner = ""
+	sm.resetZone()
+	sm.resetSupplyDrops()
+	sm.resetProjectiles()
+	sm.resetRespawnWaves()
+	sm.startTimeline()
 	logger.InfoLogger.Printf("Game started: %s with %d players", sm.state.MatchID, len(sm.state.Players))
 	return nil
 }
 
+// checkEnoughPlayers returns a NotEnoughPlayersError when too few players are connected to start
+// a match, telling everyone why it didn't start. The caller must hold sm.mu.
+func (sm *StateManager) checkEnoughPlayers() error {
+	if len(sm.state.Players) >= sm.settings.MinPlayers {
+		return nil
+	}
+
+	logger.InfoLogger.Printf("Game start rejected: not enough players (%d/%d)", len(sm.state.Players), sm.settings.MinPlayers)
+	err := &types.NotEnoughPlayersError{Current: len(sm.state.Players), Required: sm.settings.MinPlayers}
+	sm.emit(types.MessageTypeStartFailed, "", types.StartFailedPayload{
+		Code:     "notEnoughPlayers",
+		Message:  err.Error(),
+		Current:  err.Current,
+		Required: err.Required,
+	})
+	return err
+}
+
+// ResetMatch starts a fresh match with the connected players, clearing everyone's stats
+func (sm *StateManager) ResetMatch() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.checkEnoughPlayers(); err != nil {
+		return err
+	}
+
+	for _, player := range sm.state.Players {
+		player.Kills = 0
+		player.Deaths = 0
+		player.Assists = 0
+		player.Score = 0
+		player.Killstreak = 0
+		player.LastKilledBy = ""
+		player.AchievementTimes = nil
+	}
+
+	if err := sm.startGame(time.Now().UnixNano()); err != nil {
+		return err
+	}
+
+	sm.emit(types.MessageTypeMatchReset, "", types.MatchResetPayload{
+		MatchID: sm.state.MatchID,
+		Seed:    sm.state.Seed,
+	})
+	logger.InfoLogger.Printf("Match reset: %s", sm.state.MatchID)
+	return nil
+}
+
 // EndGame ends the current game
 func (sm *StateManager) EndGame() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
-	sm.state.IsGameActive = false
-	sm.state.GameTime = 0
+	sm.endGame()
+}
+
+// endGame ends the current match and announces the standings, the caller must hold sm.mu
+func (sm *StateManager) endGame() {
+	if sm.matchActive() {
+		sm.setMatchState(types.MatchStateEnding)
+		sm.emitMatchEnd()
+		sm.archiveTimeline()
+		sm.setMatchState(types.MatchStateEnded)
+	}
+
 	logger.InfoLogger.Printf("Game ended: %s, total time: %.2f seconds", sm.state.MatchID, sm.state.GameTime)
+	sm.state.GameTime = 0
+	sm.resetProjectiles()
 }
 
-// getRandomSpawnPoint returns a random spawn point
-func (sm *StateManager) getRandomSpawnPoint() types.Vector3 {
-	// If there are no spawn points defined, create one randomly within the circle
-	if len(sm.spawnPoints) == 0 {
-		return generateRandomPointInCircle(0, 0, 800.0) // Fallback with default circle radius
+// getRandomSpawnPoint returns a random spawn point, from the team's spawn zone when the player is on a team
+func (sm *StateManager) getRandomSpawnPoint(team string) types.Vector3 {
+	spawnPoints := sm.spawnPoints
+	if zone := sm.teamSpawnPoints[team]; len(zone) > 0 {
+		spawnPoints = zone
 	}
 
-	// Create a properly seeded random source
-	source := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(source)
+	// If there are no spawn points defined, create one randomly within the circle
+	if len(spawnPoints) == 0 {
+		return generateRandomPointInCircle(0, 0, sm.settings.WorldRadius, sm.rng)
+	}
 
-	// Pick a random spawn point from the available ones
-	randomIndex := r.Intn(len(sm.spawnPoints))
+	return pickSpawnPoint(spawnPoints, sm.rng).Position
+}
 
-	return sm.spawnPoints[randomIndex]
+// seedMatch reseeds the match's random source and lays out the spawn points from it
+func (sm *StateManager) seedMatch(seed int64) {
+	sm.state.Seed = seed
+	sm.rng = rand.New(rand.NewSource(seed))
+	sm.spawnPoints = sm.settings.SpawnPoints
+	if len(sm.spawnPoints) == 0 {
+		generated := GenerateSpawnPoints(sm.settings.SpawnPointCount, sm.settings.WorldRadius, sm.settings.SpawnMinDistance, sm.rng)
+		sm.spawnPoints = make([]types.SpawnPoint, len(generated))
+		for i, position := range generated {
+			sm.spawnPoints[i] = types.SpawnPoint{Position: position, Weight: 1}
+		}
+	}
+	sm.teamSpawnPoints = partitionSpawnZones(sm.spawnPoints, sm.settings.TeamCount)
 }
 
-// generateMatchID generates a unique match ID
+// generateMatchID generates a unique match ID, suffixed so matches restarted within the same second differ
 func generateMatchID() string {
-	return time.Now().Format("20060102150405")
+	return time.Now().Format("20060102150405") + "-" + uuid.New().String()[:8]
 }
 
-// generateSpawnPoints generates initial spawn points within the play area circle
-func generateSpawnPoints() []types.Vector3 {
-	// Center of the circle
-	centerX := 0.0
-	centerY := 0.0
-
-	// Radius of the spawn area circle - matches the ringWallRadius in GameMap.ts
-	circleRadius := 800.0
-
-	// Number of spawn points to generate
-	spawnPointCount := 20
-
-	spawnPoints := make([]types.Vector3, spawnPointCount)
+// sortedPlayers lists the players ordered by ID. Anything whose outcome depends on which player goes first,
+// like a shot choosing between two equally close victims, iterates this instead of the map so runs and
+// replays of a match resolve the same way. The caller must hold sm.mu.
+func (sm *StateManager) sortedPlayers() []*types.Player {
+	players := make([]*types.Player, 0, len(sm.state.Players))
+	for _, player := range sm.state.Players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	return players
+}
 
-	// Create spawn points randomly distributed within the circle
-	for i := 0; i < spawnPointCount; i++ {
-		spawnPoints[i] = generateRandomPointInCircle(centerX, centerY, circleRadius)
+// clampToWorld keeps a position inside the ring wall, preserving its height and direction from the center
+func (sm *StateManager) clampToWorld(position types.Vector3) types.Vector3 {
+	maxDistance := sm.settings.WorldRadius - playerRadius
+	distance := math.Sqrt(position.X*position.X + position.Z*position.Z)
+	if distance <= maxDistance {
+		return position
 	}
 
-	return spawnPoints
+	scale := maxDistance / distance
+	position.X *= scale
+	position.Z *= scale
+	return position
 }
 
 // generateRandomPointInCircle creates a random position within a circle
-func generateRandomPointInCircle(centerX, centerY, radius float64) types.Vector3 {
-	// Create a properly seeded random source
-	source := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(source)
-
+func generateRandomPointInCircle(centerX, centerY, radius float64, r *rand.Rand) types.Vector3 {
 	// Generate random angle
 	angle := r.Float64() * 2 * math.Pi
 
@@ -637,8 +1192,23 @@ func (sm *StateManager) UpdatePlayerName(id string, displayName string) error {
 		return types.ErrPlayerNotFound
 	}
 
+	now := time.Now()
+	if now.Sub(player.LastNameChange) < sm.settings.NameChangeCooldown {
+		logger.InfoLogger.Printf("Set name failed: player %s changed name too recently", id)
+		return types.ErrNameChangeTooSoon
+	}
+
+	resolved, err := sm.resolveNameCollision(id, displayName)
+	if err != nil {
+		logger.InfoLogger.Printf("Set name failed: %q is already taken", displayName)
+		return err
+	}
+	displayName = resolved
+
 	oldName := player.DisplayName
 	player.DisplayName = displayName
+	player.LastNameChange = now
+	sm.emitPlayerUpdate(player)
 	logger.DebugLogger.Printf("Player %s changed name: '%s' -> '%s'", id, oldName, displayName)
 	return nil
 }
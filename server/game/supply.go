@@ -0,0 +1,119 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// supplyDropSpread is the fraction of the safe zone radius supply drops can land in
+const supplyDropSpread = 0.8
+
+// pendingDrop is a supply drop that has been announced but hasn't landed yet
+type pendingDrop struct {
+	pickup  types.Pickup
+	landsAt float64
+}
+
+// resetSupplyDrops clears the drops and pickups of the last match and schedules the first drop
+func (sm *StateManager) resetSupplyDrops() {
+	sm.state.Pickups = make(map[string]*types.Pickup)
+	sm.pendingDrops = nil
+	sm.dropCount = 0
+	sm.nextSupplyDrop = sm.settings.SupplyDropInterval.Seconds()
+}
+
+// updateSupplyDrops announces new supply drops on schedule and lands the ones whose delay has passed
+func (sm *StateManager) updateSupplyDrops() {
+	if !sm.matchActive() {
+		return
+	}
+
+	if sm.settings.SupplyDropInterval > 0 && len(sm.settings.SupplyDropLoot) > 0 &&
+		sm.state.GameTime >= sm.nextSupplyDrop {
+		sm.announceSupplyDrop()
+		sm.nextSupplyDrop += sm.settings.SupplyDropInterval.Seconds()
+	}
+
+	remaining := sm.pendingDrops[:0]
+	for _, drop := range sm.pendingDrops {
+		if sm.state.GameTime < drop.landsAt {
+			remaining = append(remaining, drop)
+			continue
+		}
+
+		pickup := drop.pickup
+		sm.state.Pickups[pickup.ID] = &pickup
+		logger.InfoLogger.Printf("Supply drop %s landed at (%.2f, %.2f, %.2f)",
+			pickup.ID, pickup.Position.X, pickup.Position.Y, pickup.Position.Z)
+	}
+	sm.pendingDrops = remaining
+}
+
+// announceSupplyDrop picks loot and a landing spot inside the safe zone and tells every client about it
+func (sm *StateManager) announceSupplyDrop() {
+	sm.dropCount++
+	pickup := types.Pickup{
+		ID:       fmt.Sprintf("drop-%d", sm.dropCount),
+		Position: sm.randomPointInZone(),
+		WeaponID: sm.settings.SupplyDropLoot[sm.rng.Intn(len(sm.settings.SupplyDropLoot))],
+		Shield:   sm.settings.SupplyDropShield,
+	}
+	delay := sm.settings.SupplyDropDelay.Seconds()
+	sm.pendingDrops = append(sm.pendingDrops, pendingDrop{pickup: pickup, landsAt: sm.state.GameTime + delay})
+
+	announcement := types.SupplyDropPayload{
+		ID:       pickup.ID,
+		Position: pickup.Position,
+		WeaponID: pickup.WeaponID,
+		Shield:   pickup.Shield,
+		LandsIn:  delay,
+	}
+	sm.emit(types.MessageTypeSupplyDrop, "", announcement)
+	sm.recordTimeline(types.TimelineSupplyDrop, announcement)
+	logger.InfoLogger.Printf("Supply drop %s with %s announced at (%.2f, %.2f, %.2f), landing in %.0fs",
+		pickup.ID, pickup.WeaponID, pickup.Position.X, pickup.Position.Y, pickup.Position.Z, delay)
+}
+
+// randomPointInZone returns a uniformly distributed ground position well inside the safe zone
+func (sm *StateManager) randomPointInZone() types.Vector3 {
+	center := sm.state.Zone.Center
+	radius := sm.state.Zone.Radius
+	if radius <= 0 {
+		radius = sm.settings.WorldRadius
+	}
+
+	angle := sm.rng.Float64() * 2 * math.Pi
+	distance := radius * supplyDropSpread * math.Sqrt(sm.rng.Float64())
+	return sm.clampToWorld(types.Vector3{
+		X: center.X + math.Cos(angle)*distance,
+		Y: 0,
+		Z: center.Z + math.Sin(angle)*distance,
+	})
+}
+
+// collectPickups hands the player every pickup within reach of their position
+func (sm *StateManager) collectPickups(player *types.Player) {
+	for id, pickup := range sm.state.Pickups {
+		dx := pickup.Position.X - player.Position.X
+		dz := pickup.Position.Z - player.Position.Z
+		if math.Hypot(dx, dz) > sm.settings.PickupRadius {
+			continue
+		}
+
+		if !hasWeapon(player, pickup.WeaponID) {
+			player.Weapons = append(player.Weapons, pickup.WeaponID)
+			loadWeapon(player, pickup.WeaponID)
+		}
+		if player.CurrentWeapon == "" {
+			player.CurrentWeapon = pickup.WeaponID
+		}
+		sm.addShield(player, pickup.Shield)
+		delete(sm.state.Pickups, id)
+		sm.emitPlayerUpdate(player)
+		logger.InfoLogger.Printf("Player %s picked up %s and %d shield from %s (shield now %d)",
+			player.ID, pickup.WeaponID, pickup.Shield, id, player.Shield)
+	}
+}
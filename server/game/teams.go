@@ -0,0 +1,128 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// teamID returns the id of the team with the given index
+func teamID(index int) string {
+	return fmt.Sprintf("team%d", index+1)
+}
+
+// assignTeam picks the team with the fewest players, or no team in free-for-all
+func (sm *StateManager) assignTeam() string {
+	if sm.settings.TeamCount <= 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, player := range sm.state.Players {
+		counts[player.Team]++
+	}
+
+	team := teamID(0)
+	for i := 1; i < sm.settings.TeamCount; i++ {
+		if counts[teamID(i)] < counts[team] {
+			team = teamID(i)
+		}
+	}
+	return team
+}
+
+// partitionSpawnZones splits spawn points into one arc of the circle per team,
+// so teams start on opposite sides of the map instead of inside each other
+func partitionSpawnZones(spawnPoints []types.SpawnPoint, teamCount int) map[string][]types.SpawnPoint {
+	zones := make(map[string][]types.SpawnPoint)
+	if teamCount <= 0 {
+		return zones
+	}
+
+	arc := 2 * math.Pi / float64(teamCount)
+	for _, point := range spawnPoints {
+		angle := math.Atan2(point.Position.Z, point.Position.X)
+		if angle < 0 {
+			angle += 2 * math.Pi
+		}
+
+		index := int(angle / arc)
+		if index >= teamCount {
+			index = teamCount - 1
+		}
+		zones[teamID(index)] = append(zones[teamID(index)], point)
+	}
+	return zones
+}
+
+// totalTeamScores adds up the kills and score of each team's players, the caller must hold sm.mu
+func (sm *StateManager) totalTeamScores() {
+	if sm.settings.TeamCount <= 0 {
+		return
+	}
+
+	teams := make(map[string]types.TeamScore, sm.settings.TeamCount)
+	for i := 0; i < sm.settings.TeamCount; i++ {
+		teams[teamID(i)] = types.TeamScore{}
+	}
+	for _, player := range sm.state.Players {
+		if player.Team == "" {
+			continue
+		}
+		total := teams[player.Team]
+		total.Kills += player.Kills
+		total.Score += player.Score
+		teams[player.Team] = total
+	}
+	sm.state.Teams = teams
+}
+
+// teamPoints is what teams are ranked by, their score when ranking by score and their kills otherwise
+func (sm *StateManager) teamPoints(team types.TeamScore) int {
+	if sm.settings.RankBy == RankByScore {
+		return team.Score
+	}
+	return team.Kills
+}
+
+// leadingTeam returns the team with the most points, or no team when the lead is tied
+func (sm *StateManager) leadingTeam() string {
+	leader, best, tied := "", -1, false
+	for i := 0; i < sm.settings.TeamCount; i++ {
+		team := teamID(i)
+		points := sm.teamPoints(sm.state.Teams[team])
+		switch {
+		case points > best:
+			leader, best, tied = team, points, false
+		case points == best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return leader
+}
+
+// checkTeamWin ends a team match when a team reached the win target or the time limit ran out,
+// the caller must hold sm.mu and have totalled the team scores
+func (sm *StateManager) checkTeamWin() {
+	if sm.settings.TeamCount <= 0 || !sm.matchActive() {
+		return
+	}
+
+	if target := sm.settings.TeamWinTarget; target > 0 {
+		if leader := sm.leadingTeam(); leader != "" && sm.teamPoints(sm.state.Teams[leader]) >= target {
+			logger.InfoLogger.Printf("Team %s reached %d and wins match %s", leader, target, sm.state.MatchID)
+			sm.endGame()
+			return
+		}
+	}
+
+	if limit := sm.settings.TeamTimeLimit; limit > 0 && sm.state.GameTime >= limit.Seconds() {
+		logger.InfoLogger.Printf("Match %s reached its time limit of %v, leading team: %q", sm.state.MatchID, limit, sm.leadingTeam())
+		sm.endGame()
+	}
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"finalcircle/server/types"
+)
+
+// maxTimelines is how many finished matches keep their timeline for post-match review
+const maxTimelines = 20
+
+// startTimeline begins the timeline of the match that just started, the caller must hold sm.mu
+func (sm *StateManager) startTimeline() {
+	sm.timeline = nil
+	sm.recordTimeline(types.TimelineMatchStart, map[string]interface{}{
+		"players": len(sm.state.Players),
+		"seed":    sm.state.Seed,
+	})
+}
+
+// recordTimeline adds an event to the running match's timeline, the caller must hold sm.mu
+func (sm *StateManager) recordTimeline(eventType types.TimelineEventType, details interface{}) {
+	if !sm.inMatch() {
+		return
+	}
+	sm.timeline = append(sm.timeline, types.TimelineEvent{
+		Type:    eventType,
+		Time:    sm.state.GameTime,
+		Details: details,
+	})
+}
+
+// archiveTimeline keeps the timeline of the match that just ended, forgetting the oldest
+// once more than maxTimelines are kept. The caller must hold sm.mu.
+func (sm *StateManager) archiveTimeline() {
+	if sm.timeline == nil {
+		return
+	}
+	if sm.timelines == nil {
+		sm.timelines = make(map[string][]types.TimelineEvent)
+	}
+
+	sm.timelines[sm.state.MatchID] = sm.timeline
+	sm.timelineOrder = append(sm.timelineOrder, sm.state.MatchID)
+	if len(sm.timelineOrder) > maxTimelines {
+		delete(sm.timelines, sm.timelineOrder[0])
+		sm.timelineOrder = sm.timelineOrder[1:]
+	}
+	sm.timeline = nil
+}
+
+// Timeline returns the events of a finished match, or of the running one so far
+func (sm *StateManager) Timeline(matchID string) ([]types.TimelineEvent, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	events, ok := sm.timelines[matchID]
+	if !ok && sm.inMatch() && matchID == sm.state.MatchID {
+		events, ok = sm.timeline, true
+	}
+	return append([]types.TimelineEvent(nil), events...), ok
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"finalcircle/server/types"
+)
+
+// combatWindow is how many seconds after one of them last hurt the other two players count as fighting
+const combatWindow = 5.0
+
+// GetPlayerView returns the game state a player receives in the given broadcast. Players farther away
+// than the max view distance are left out so a modified client can't reveal them. With broadcast
+// prioritization, players outside the priority radius who aren't fighting the viewer are only sent every
+// few broadcasts and listed as deferred in between. It returns false when neither applies or there
+// is no such player, the full state is sent then.
+func (sm *StateManager) GetPlayerView(id string, broadcast uint64) (*types.GameState, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.settings.MaxViewDistance <= 0 && sm.settings.PriorityRadius <= 0 {
+		return nil, false
+	}
+	viewer, exists := sm.state.Players[id]
+	if !exists {
+		return nil, false
+	}
+
+	view := sm.copyState()
+	view.Deferred = nil
+	for playerID, player := range sm.state.Players {
+		distance := horizontalDistance(player.Position, viewer.Position)
+		if playerID != id && sm.settings.MaxViewDistance > 0 && distance > sm.settings.MaxViewDistance {
+			continue
+		}
+		if playerID != id && !sm.prioritized(viewer, player, distance) && !sm.distantUpdateDue(broadcast) {
+			view.Deferred = append(view.Deferred, playerID)
+			continue
+		}
+
+		view.Players[playerID] = copyPlayer(player)
+	}
+	return view, true
+}
+
+// prioritized reports whether a player is sent to the viewer in every broadcast, because prioritization
+// is off or the player is near the viewer or fighting them. The caller must hold sm.mu.
+func (sm *StateManager) prioritized(viewer, player *types.Player, distance float64) bool {
+	return sm.settings.PriorityRadius <= 0 ||
+		distance <= sm.settings.PriorityRadius ||
+		sm.recentlyHurt(viewer, player.ID) ||
+		sm.recentlyHurt(player, viewer.ID)
+}
+
+// recentlyHurt reports whether the attacker was the last to damage the player within the combat window,
+// the caller must hold sm.mu
+func (sm *StateManager) recentlyHurt(player *types.Player, attackerID string) bool {
+	last := player.LastDamagedBy
+	return last != nil && last.AttackerID == attackerID && sm.state.GameTime-last.GameTime <= combatWindow
+}
+
+// distantUpdateDue reports whether the broadcast is one that carries the players outside the priority radius
+func (sm *StateManager) distantUpdateDue(broadcast uint64) bool {
+	every := uint64(max(1, sm.settings.DistantUpdateEvery))
+	return broadcast%every == 0
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// Weapon holds the server-side stats of a weapon
+type Weapon struct {
+	ID string
+	// Damage is dealt by each pellet for weapons firing several
+	Damage int
+	// FireRate is how many shots per second the weapon fires, clients pace their shots with it
+	FireRate float64
+	// MagazineSize is how many shots the weapon fires before reloading, 0 for weapons that never
+	// run dry, and ReloadTime how many seconds refilling the magazine takes
+	MagazineSize int
+	ReloadTime   float64
+	// Range is the farthest in units a hitscan shot reaches, 0 for no limit
+	Range float64
+	// FalloffStart is the distance hitscan damage starts falling off at, dropping steadily until it's down
+	// to MinDamageFraction of full damage at FalloffEnd. A FalloffEnd of 0 deals full damage at any distance.
+	FalloffStart      float64
+	FalloffEnd        float64
+	MinDamageFraction float64
+	// Pellets is how many rays a shot fires, spread evenly in a cone PelletSpread radians wide
+	// around where it's aimed. 0 fires a single ray.
+	Pellets      int
+	PelletSpread float64
+
+	// SpreadPerShot is how much each shot widens the player's spread and MaxSpread the widest it gets,
+	// both in radians. SpreadRecovery is how many radians of spread wear off per second.
+	SpreadPerShot  float64
+	MaxSpread      float64
+	SpreadRecovery float64
+
+	// ProjectileSpeed is how fast in units per second the weapon's projectiles fly, 0 for hitscan weapons
+	ProjectileSpeed float64
+
+	// Penetration is how many players lined up behind the first one a shot goes on to hit, and
+	// PenetrationFalloff the share of its damage left after passing through each of them
+	Penetration        int
+	PenetrationFalloff float64
+	// ExplosionRadius makes a projectile explode where it stops, damaging everyone within that
+	// distance less the farther they are from it. 0 only damages the player it hits.
+	ExplosionRadius float64
+}
+
+// weapons is the registry of known weapons keyed by weapon ID - matches the client's WeaponSystem,
+// except for the SHOTGUN, ROCKET launcher and GRENADE which only exist server-side until the client has models for them
+var weapons = map[string]Weapon{
+	"RIFLE": {ID: "RIFLE", Damage: 25, FireRate: 8, MagazineSize: 30, ReloadTime: 1.8, Range: 100,
+		FalloffStart: 50, FalloffEnd: 100, MinDamageFraction: 0.6,
+		SpreadPerShot: 0.03, MaxSpread: 0.3, SpreadRecovery: 0.6, Penetration: 2, PenetrationFalloff: 0.6},
+	"SMG": {ID: "SMG", Damage: 15, FireRate: 12, MagazineSize: 25, ReloadTime: 1.2, Range: 50,
+		FalloffStart: 20, FalloffEnd: 50, MinDamageFraction: 0.5,
+		SpreadPerShot: 0.04, MaxSpread: 0.35, SpreadRecovery: 0.8},
+	"PISTOL": {ID: "PISTOL", Damage: 20, FireRate: 5, MagazineSize: 12, ReloadTime: 1, Range: 40,
+		FalloffStart: 20, FalloffEnd: 40, MinDamageFraction: 0.5,
+		SpreadPerShot: 0.05, MaxSpread: 0.25, SpreadRecovery: 0.8},
+	"SNIPER": {ID: "SNIPER", Damage: 100, FireRate: 1, MagazineSize: 5, ReloadTime: 2, Range: 200,
+		SpreadPerShot: 0.15, MaxSpread: 0.3, SpreadRecovery: 0.3},
+	"KNIFE": {ID: "KNIFE", Damage: 50, FireRate: 1.5, Range: 2},
+	"SHOTGUN": {ID: "SHOTGUN", Damage: 12, FireRate: 1.2, MagazineSize: 6, ReloadTime: 2.5, Range: 25,
+		FalloffStart: 6, FalloffEnd: 25, MinDamageFraction: 0.2,
+		Pellets: 8, PelletSpread: 0.06, SpreadPerShot: 0.1, MaxSpread: 0.2, SpreadRecovery: 0.5},
+	"ROCKET": {ID: "ROCKET", Damage: 90, FireRate: 0.5, MagazineSize: 1, ReloadTime: 3,
+		SpreadPerShot: 0.1, MaxSpread: 0.2, SpreadRecovery: 0.2, ProjectileSpeed: 60},
+	"GRENADE": {ID: "GRENADE", Damage: 80, FireRate: 1, MagazineSize: 1, ReloadTime: 1, ProjectileSpeed: 20, ExplosionRadius: 6},
+}
+
+// LookupWeapon returns the stats of the weapon with the given ID
+func LookupWeapon(id string) (Weapon, bool) {
+	weapon, ok := weapons[id]
+	return weapon, ok
+}
+
+// falloffDamage is the damage a hitscan hit the given distance away deals before zone multipliers,
+// never less than the weapon's minimum damage floor
+func falloffDamage(weapon Weapon, distance float64) float64 {
+	damage := float64(weapon.Damage)
+	if weapon.FalloffEnd <= weapon.FalloffStart || distance <= weapon.FalloffStart {
+		return damage
+	}
+
+	progress := math.Min(1, (distance-weapon.FalloffStart)/(weapon.FalloffEnd-weapon.FalloffStart))
+	return damage * (1 - progress*(1-weapon.MinDamageFraction))
+}
+
+// giveStartingWeapons fills a player's inventory with the configured loadout
+func (sm *StateManager) giveStartingWeapons(player *types.Player) {
+	player.Weapons = append([]string(nil), sm.settings.StartingWeapons...)
+	player.CurrentWeapon = ""
+	if len(player.Weapons) > 0 {
+		player.CurrentWeapon = player.Weapons[0]
+	}
+	refillAmmo(player)
+}
+
+// switchWeapon selects a weapon from the player's inventory, respecting the switch cooldown
+func (sm *StateManager) switchWeapon(player *types.Player, weaponID string) error {
+	if _, ok := LookupWeapon(weaponID); !ok {
+		return types.ErrUnknownWeapon
+	}
+
+	if !hasWeapon(player, weaponID) {
+		logger.WarningLogger.Printf("Player %s tried to switch to weapon %s they don't own", player.ID, weaponID)
+		return types.ErrWeaponNotOwned
+	}
+
+	if weaponID == player.CurrentWeapon {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Sub(player.LastWeaponSwitch) < sm.settings.WeaponSwitchCooldown {
+		return types.ErrWeaponSwitchTooSoon
+	}
+
+	// Reloading is for the weapon in hand, putting it away abandons the reload
+	cancelReload(player)
+	logger.DebugLogger.Printf("Player %s switched weapon: %s -> %s", player.ID, player.CurrentWeapon, weaponID)
+	player.CurrentWeapon = weaponID
+	player.LastWeaponSwitch = now
+	sm.emitPlayerUpdate(player)
+	return nil
+}
+
+// GiveWeapon adds a weapon to a player's inventory, e.g. when they pick up a weapon item
+func (sm *StateManager) GiveWeapon(playerID, weaponID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	player, exists := sm.state.Players[playerID]
+	if !exists {
+		return types.ErrPlayerNotFound
+	}
+
+	if _, ok := LookupWeapon(weaponID); !ok {
+		return types.ErrUnknownWeapon
+	}
+
+	if hasWeapon(player, weaponID) {
+		return nil
+	}
+
+	player.Weapons = append(player.Weapons, weaponID)
+	loadWeapon(player, weaponID)
+	if player.CurrentWeapon == "" {
+		player.CurrentWeapon = weaponID
+	}
+
+	sm.emitPlayerUpdate(player)
+	logger.DebugLogger.Printf("Player %s picked up weapon %s", playerID, weaponID)
+	return nil
+}
+
+// checkFireRate rejects a shot that comes sooner after the player's last one than the weapon can fire
+func (sm *StateManager) checkFireRate(player *types.Player, weapon Weapon) error {
+	if !sm.settings.EnforceFireRate || weapon.FireRate <= 0 {
+		return nil
+	}
+
+	interval := time.Duration(float64(time.Second) / weapon.FireRate)
+	if since := time.Since(player.LastShotTime); since < interval-sm.settings.FireRateTolerance {
+		logger.DebugLogger.Printf("Player %s fired %s %v after their last shot, faster than its %v fire interval",
+			player.ID, weapon.ID, since, interval)
+		return types.ErrFiringTooFast
+	}
+	return nil
+}
+
+// hasWeapon reports whether the weapon is in the player's inventory
+func hasWeapon(player *types.Player, weaponID string) bool {
+	for _, owned := range player.Weapons {
+		if owned == weaponID {
+			return true
+		}
+	}
+	return false
+}
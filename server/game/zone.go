@@ -0,0 +1,188 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// zoneDamageSlack is how little short of a whole point accumulated zone damage may fall and still deal it
+const zoneDamageSlack = 1e-6
+
+// ZonePhase is one step of the safe zone schedule
+type ZonePhase struct {
+	// Duration is how long the phase lasts, the last phase lasts until the match ends
+	Duration time.Duration
+	// RadiusFraction is the radius of the safe zone during the phase as a fraction of the world radius
+	RadiusFraction float64
+	// DamagePerSecond is the damage dealt to players outside the zone during the phase
+	DamagePerSecond float64
+	// Shrink is how long the zone takes at the start of the phase to close in from the previous
+	// phase's radius, 0 jumps straight to the new one
+	Shrink time.Duration
+}
+
+// DefaultZonePhases returns a schedule where the zone closes in and hurts more with every phase
+func DefaultZonePhases() []ZonePhase {
+	return []ZonePhase{
+		{Duration: 90 * time.Second, RadiusFraction: 1.0, DamagePerSecond: 1},
+		{Duration: 60 * time.Second, RadiusFraction: 0.7, DamagePerSecond: 2, Shrink: 30 * time.Second},
+		{Duration: 60 * time.Second, RadiusFraction: 0.45, DamagePerSecond: 5, Shrink: 30 * time.Second},
+		{Duration: 45 * time.Second, RadiusFraction: 0.22, DamagePerSecond: 10, Shrink: 25 * time.Second},
+		{RadiusFraction: 0.08, DamagePerSecond: 25, Shrink: 20 * time.Second},
+	}
+}
+
+// zonePhaseFile is a zone phase as written in a zone schedule file
+type zonePhaseFile struct {
+	// ShrinkSeconds is how long the zone closes in at the start of the phase and WaitSeconds how long
+	// it then holds before the next phase, the last phase holds until the match ends
+	ShrinkSeconds   float64 `json:"shrinkSeconds"`
+	WaitSeconds     float64 `json:"waitSeconds"`
+	RadiusFraction  float64 `json:"radiusFraction"`
+	DamagePerSecond float64 `json:"damagePerSecond"`
+}
+
+// LoadZonePhases reads a safe zone schedule from a JSON file containing an array of phases
+func LoadZonePhases(path string) ([]ZonePhase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []zonePhaseFile
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("the schedule has no phases")
+	}
+
+	phases := make([]ZonePhase, len(entries))
+	for i, entry := range entries {
+		if entry.RadiusFraction <= 0 || entry.RadiusFraction > 1 {
+			return nil, fmt.Errorf("phase %d: radiusFraction %v is not within (0, 1]", i, entry.RadiusFraction)
+		}
+		if entry.ShrinkSeconds < 0 || entry.WaitSeconds < 0 || entry.DamagePerSecond < 0 {
+			return nil, fmt.Errorf("phase %d: times and damage can't be negative", i)
+		}
+		phases[i] = ZonePhase{
+			Duration:        time.Duration((entry.ShrinkSeconds + entry.WaitSeconds) * float64(time.Second)),
+			RadiusFraction:  entry.RadiusFraction,
+			DamagePerSecond: entry.DamagePerSecond,
+			Shrink:          time.Duration(entry.ShrinkSeconds * float64(time.Second)),
+		}
+	}
+	return phases, nil
+}
+
+// zonePhaseAt returns the index of the zone phase active at the given game time
+func (sm *StateManager) zonePhaseAt(gameTime float64) int {
+	phases := sm.settings.ZonePhases
+	elapsed := 0.0
+	for i, phase := range phases[:len(phases)-1] {
+		elapsed += phase.Duration.Seconds()
+		if gameTime < elapsed {
+			return i
+		}
+	}
+	return len(phases) - 1
+}
+
+// zonePhaseStart returns the game time the given zone phase begins at
+func (sm *StateManager) zonePhaseStart(index int) float64 {
+	start := 0.0
+	for _, phase := range sm.settings.ZonePhases[:index] {
+		start += phase.Duration.Seconds()
+	}
+	return start
+}
+
+// setZonePhase applies the given phase of the schedule to the safe zone, which starts closing in
+// from wherever it is to the phase's radius
+func (sm *StateManager) setZonePhase(index int) {
+	phase := sm.settings.ZonePhases[index]
+	zone := &sm.state.Zone
+	zone.Phase = index
+	zone.DamagePerSecond = phase.DamagePerSecond
+	zone.StartRadius = zone.Radius
+	zone.TargetRadius = phase.RadiusFraction * sm.settings.WorldRadius
+	zone.ShrinkStart = sm.zonePhaseStart(index)
+	zone.ShrinkEnd = zone.ShrinkStart + phase.Shrink.Seconds()
+	sm.shrinkZone()
+}
+
+// shrinkZone sets the safe zone's radius for the current game time, between its start and target radius
+func (sm *StateManager) shrinkZone() {
+	zone := &sm.state.Zone
+	progress := 1.0
+	if zone.ShrinkEnd > zone.ShrinkStart {
+		progress = math.Max(0, math.Min(1, (sm.state.GameTime-zone.ShrinkStart)/(zone.ShrinkEnd-zone.ShrinkStart)))
+	}
+	zone.Radius = zone.StartRadius + (zone.TargetRadius-zone.StartRadius)*progress
+}
+
+// resetZone puts the safe zone back to the first phase of the schedule, at its full radius from the start
+func (sm *StateManager) resetZone() {
+	sm.state.Zone = types.SafeZone{}
+	if len(sm.settings.ZonePhases) == 0 {
+		return
+	}
+	sm.state.Zone.Radius = sm.settings.ZonePhases[0].RadiusFraction * sm.settings.WorldRadius
+	sm.setZonePhase(0)
+}
+
+// updateZone advances the zone phase and damages players outside the safe zone
+func (sm *StateManager) updateZone(deltaTime float64) {
+	if !sm.matchActive() || len(sm.settings.ZonePhases) == 0 {
+		return
+	}
+
+	if phase := sm.zonePhaseAt(sm.state.GameTime); phase != sm.state.Zone.Phase {
+		sm.setZonePhase(phase)
+		sm.recordTimeline(types.TimelineZonePhase, sm.state.Zone)
+		logger.InfoLogger.Printf("Zone phase %d: closing in to radius %.0f by %.0fs, %.1f damage per second",
+			phase, sm.state.Zone.TargetRadius, sm.state.Zone.ShrinkEnd, sm.state.Zone.DamagePerSecond)
+	}
+	sm.shrinkZone()
+
+	// Players are hurt in a stable order so who the zone eliminates first is the same on every run
+	for _, player := range sm.sortedPlayers() {
+		if !player.IsAlive || player.InvulnerableFor > 0 || sm.insideZone(player.Position) {
+			if player.OutsideZone {
+				player.OutsideZone = false
+				logger.DebugLogger.Printf("Player %s is no longer taking zone damage", player.ID)
+			}
+			continue
+		}
+
+		if !player.OutsideZone {
+			player.OutsideZone = true
+			logger.InfoLogger.Printf("Player %s is outside the safe zone, taking %.1f damage per second",
+				player.ID, sm.state.Zone.DamagePerSecond)
+		}
+
+		// Damage is scaled by the frame time so it doesn't depend on the tick rate, with some slack for the
+		// rounding errors of adding up many small frames
+		player.ZoneDamage += sm.state.Zone.DamagePerSecond * deltaTime
+		damage := int(player.ZoneDamage + zoneDamageSlack)
+		player.ZoneDamage -= float64(damage)
+		sm.damagePlayer(player, nil, damage, types.DamageCauseZone)
+		if !player.IsAlive {
+			player.OutsideZone = false
+			logger.InfoLogger.Printf("Player %s was killed by the zone", player.ID)
+		}
+	}
+}
+
+// insideZone reports whether a position is within the safe zone on the ground plane
+func (sm *StateManager) insideZone(pos types.Vector3) bool {
+	zone := sm.state.Zone
+	return math.Hypot(pos.X-zone.Center.X, pos.Z-zone.Center.Z) <= zone.Radius
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+)
+
+// defaultPingInterval is how often connections are pinged when no interval is configured
+const defaultPingInterval = 30 * time.Second
+
+// minPongWait is the shortest time a connection may go without answering a ping before it is dropped
+const minPongWait = 60 * time.Second
+
+// pingInterval is how often the write pump pings a connection
+func (gs *GameServer) pingInterval() time.Duration {
+	if gs.config.PingInterval <= 0 {
+		return defaultPingInterval
+	}
+	return gs.config.PingInterval
+}
+
+// pongWait is how long a connection may stay silent, long enough to miss one ping
+func (gs *GameServer) pongWait() time.Duration {
+	return max(minPongWait, 2*gs.pingInterval())
+}
+
+// pingPayload stamps a ping with the time it was sent, the client's pong echoes it back
+func pingPayload(sent time.Time) []byte {
+	return []byte(strconv.FormatInt(sent.UnixNano(), 10))
+}
+
+// handlePong keeps the connection alive and records the round trip time of the ping the pong answers.
+// Pongs that don't carry one of our timestamps, e.g. unsolicited ones, only extend the deadline.
+func (gs *GameServer) handlePong(client *WebsocketClient, appData string) error {
+	client.Conn.SetReadDeadline(time.Now().Add(gs.pongWait()))
+
+	sent, err := strconv.ParseInt(appData, 10, 64)
+	if err != nil {
+		return nil
+	}
+	rtt := time.Since(time.Unix(0, sent))
+	if rtt < 0 {
+		return nil
+	}
+
+	client.latency.Store(int64(rtt))
+	logger.DebugLogger.Printf("Latency of client %s: %v", client.ID, rtt)
+
+	if client.reportLatency.Load() {
+		latencyMsg := map[string]interface{}{
+			"type":      "latency",
+			"payload":   types.LatencyPayload{RTTMs: latencyMs(rtt)},
+			"timestamp": time.Now().Unix(),
+		}
+		latencyJSON, _ := json.Marshal(latencyMsg)
+		// A full buffer just skips this measurement, the next pong brings a fresh one
+		select {
+		case client.Send <- latencyJSON:
+		default:
+		}
+	}
+	return nil
+}
+
+// latencyMs converts a round trip time to milliseconds, with a tenth of a millisecond precision
+func latencyMs(rtt time.Duration) float64 {
+	return float64(rtt.Microseconds()/100) / 10
+}
+
+// clientLatencies lists the last measured round trip time in milliseconds of every connected player,
+// players whose first ping hasn't been answered yet are left out
+func (gs *GameServer) clientLatencies() map[string]float64 {
+	gs.clientsMu.RLock()
+	defer gs.clientsMu.RUnlock()
+
+	latencies := make(map[string]float64, len(gs.clients))
+	for id, client := range gs.clients {
+		if rtt := time.Duration(client.latency.Load()); rtt > 0 {
+			latencies[id] = latencyMs(rtt)
+		}
+	}
+	return latencies
+}
@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"finalcircle/server/config"
@@ -22,20 +32,218 @@ type WebsocketClient struct {
 	Conn   *websocket.Conn
 	Send   chan []byte
 	GameID string
+	// IP is the address the client connected from, to kick it when the address gets banned
+	IP string
+
+	// IsObserver marks read-only connections that watch the game without playing
+	IsObserver bool
+	// compact is set once the client asked for compact game states in its hello
+	compact atomic.Bool
+	// reportLatency is set once the client asked to be told its measured latency in its hello
+	reportLatency atomic.Bool
+	// latency is the round trip time in nanoseconds of the last answered ping, 0 until one is answered
+	latency atomic.Int64
+	// stateInterval is the minimum time in nanoseconds between game states the client asked for in its hello,
+	// lastState when it was last sent one in unix nanoseconds and stateFrames how many it was sent
+	stateInterval atomic.Int64
+	lastState     atomic.Int64
+	stateFrames   atomic.Uint64
+
+	// done is closed when the client is disconnected to stop its write pump
+	done chan struct{}
+	// final carries a last message the write pump sends before closing the connection
+	final chan closingMessage
+
+	// stats counts the traffic of the connection
+	stats connectionStats
 }
 
+// closingMessage is a message sent right before closing a connection with the given close code
+type closingMessage struct {
+	message   []byte
+	closeCode int
+	reason    string
+}
+
+// newWebsocketClient creates a client for an upgraded connection
+func newWebsocketClient(id string, conn *websocket.Conn) *WebsocketClient {
+	return &WebsocketClient{
+		ID:     id,
+		Conn:   conn,
+		GameID: defaultRoomID,
+		Send:   make(chan []byte, 256),
+		done:   make(chan struct{}),
+		final:  make(chan closingMessage, 1),
+	}
+}
+
+// closeWith has the write pump deliver a last message and then close the connection,
+// so the client learns why it was disconnected
+func (c *WebsocketClient) closeWith(message []byte, closeCode int, reason string) {
+	select {
+	case c.final <- closingMessage{message: message, closeCode: closeCode, reason: reason}:
+	default:
+	}
+}
+
+// livenessTimeout is how long the game loop may go without ticking before /livez reports it as dead
+const livenessTimeout = time.Second
+
+// drainingReason is what new players and /readyz are told while the server drains
+const drainingReason = "server is draining, finishing the current match before shutting down"
+
 type GameServer struct {
+	// stateManager is the game state of the default room
 	stateManager *game.StateManager
-	clients      map[string]*WebsocketClient
-	clientsMu    sync.RWMutex
-	upgrader     websocket.Upgrader
-	startTime    time.Time
+	// settings are the server defaults new rooms start from
+	settings    game.Settings
+	defaultRoom *Room
+	rooms       map[string]*Room
+	roomsMu     sync.RWMutex
+
+	clients   map[string]*WebsocketClient
+	clientsMu sync.RWMutex
+	// peakClients is the highest number of concurrent clients seen, guarded by clientsMu
+	peakClients int
+	observers   map[string]*WebsocketClient
+	observersMu sync.RWMutex
+	// held are the ranked players waiting to reconnect and reconnectTokens the tokens they can do
+	// it with, both guarded by heldMu
+	held            map[string]*heldPlayer
+	reconnectTokens map[string]string
+	heldMu          sync.Mutex
+	upgrader        websocket.Upgrader
+	startTime       time.Time
+	config          *config.Config
+
+	// slowClientDisconnects counts clients disconnected because their send buffer was full,
+	// droppedFrames counts state frames skipped for such clients instead
+	slowClientDisconnects atomic.Int64
+	droppedFrames         atomic.Int64
+
+	// inboundMessages counts client messages handled and outboundFrames frames written, for per-second rates
+	inboundMessages *rateCounter
+	outboundFrames  *rateCounter
+
+	// newPlayerID generates the ID of a connecting anonymous player
+	newPlayerID func() string
+	// auth validates the tokens players connect with, nil when anonymous play is allowed
+	auth *tokenVerifier
+
+	// lastHeartbeat is the time (in unix nanoseconds) of the last game loop tick
+	lastHeartbeat atomic.Int64
+	shuttingDown  atomic.Bool
+	// draining refuses new players while the current match plays out, ahead of a shutdown
+	draining atomic.Bool
+	// moderation are the banned words and addresses, swapped as a whole when they are reloaded
+	moderation atomic.Pointer[moderationLists]
 }
 
-func newGameServer() (*GameServer, error) {
+func newGameServer(cfg *config.Config) (*GameServer, error) {
+	settings := game.DefaultSettings()
+	settings.MaxPlayers = cfg.MaxPlayers
+	settings.TickRate = cfg.TickRate
+	if cfg.WorldRadius > 0 {
+		settings.WorldRadius = cfg.WorldRadius
+	}
+	settings.MinPlayers = cfg.MinPlayers
+	settings.AutoStart = cfg.AutoStart
+	settings.AutoStartWarmup = cfg.AutoStartWarmup
+	settings.NameCollisionPolicy = game.NameCollisionPolicy(cfg.NameCollisionPolicy)
+	settings.NameChangeCooldown = cfg.NameChangeCooldown
+	settings.GameMode = types.GameMode(cfg.GameMode)
+	settings.LateJoin = game.LateJoinPolicy(cfg.LateJoin)
+	settings.MaxViewDistance = cfg.MaxViewDistance
+	settings.PriorityRadius = cfg.PriorityRadius
+	settings.DistantUpdateEvery = cfg.DistantUpdateEvery
+	settings.TeamCount = cfg.TeamCount
+	settings.TeamWinTarget = cfg.TeamWinTarget
+	settings.TeamTimeLimit = cfg.TeamTimeLimit
+	settings.MaxMatchDuration = cfg.MaxMatchDuration
+	settings.RankBy = game.RankBy(cfg.RankBy)
+	settings.Scoring = game.Scoring{
+		Kill:          cfg.ScoreKill,
+		HeadshotBonus: cfg.ScoreHeadshotBonus,
+		Assist:        cfg.ScoreAssist,
+		Objective:     cfg.ScoreObjective,
+		ZoneKill:      cfg.ScoreZoneKill,
+	}
+	settings.MapName = cfg.MapName
+	settings.RespawnDelay = secondsToDuration(cfg.RespawnSeconds)
+	settings.RespawnWaveInterval = secondsToDuration(cfg.RespawnWaveSeconds)
+	settings.SpawnProtection = secondsToDuration(cfg.SpawnProtectionSeconds)
+	settings.SpawnCampWindow = cfg.SpawnCampWindow
+	settings.SpectatorView = game.SpectatorView(cfg.SpectatorView)
+	settings.HitRegMode = game.HitRegMode(cfg.HitRegMode)
+	settings.HeadshotMultiplier = cfg.HeadshotMultiplier
+	settings.LegshotMultiplier = cfg.LegshotMultiplier
+	settings.MaxAimDeviation = cfg.MaxAimDeviationDegrees * math.Pi / 180
+	settings.SelfDamage = cfg.SelfDamage
+	settings.FallDamage = cfg.FallDamage
+	settings.FallDamageSpeed = cfg.FallDamageSpeed
+	settings.FallDamagePerSpeed = cfg.FallDamageScale
+	settings.HealthRegen = cfg.HealthRegen
+	settings.HealthRegenDelay = cfg.HealthRegenDelay
+	settings.HealthRegenRate = cfg.HealthRegenRate
+	settings.EnforceFireRate = cfg.EnforceFireRate
+	settings.FireRateTolerance = cfg.FireRateTolerance
+	settings.SupplyDropInterval = cfg.SupplyDropInterval
+	settings.SupplyDropShield = cfg.SupplyDropShield
+	settings.StartingShield = cfg.StartingShield
+	settings.MaxShield = cfg.MaxShield
+	settings.ObstacleCollision = cfg.ObstacleCollision
+	settings.MaxMoveSpeed = cfg.MaxMoveSpeed
+	settings.CoalesceMoves = cfg.CoalesceMoves
+	settings.MaxFrameTime = cfg.MaxFrameTime
+	settings.MaxCatchUpSteps = cfg.MaxCatchUpSteps
+
+	if cfg.ObstaclesFile != "" {
+		obstacles, err := game.LoadObstacles(cfg.ObstaclesFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading obstacles from %s: %w", cfg.ObstaclesFile, err)
+		}
+		settings.Obstacles = obstacles
+		logger.InfoLogger.Printf("Loaded %d obstacles from %s", len(obstacles), cfg.ObstaclesFile)
+	}
+	if cfg.SpawnPointsFile != "" {
+		spawnPoints, err := game.LoadSpawnPoints(cfg.SpawnPointsFile, settings.WorldRadius)
+		if err != nil {
+			return nil, fmt.Errorf("loading spawn points from %s: %w", cfg.SpawnPointsFile, err)
+		}
+		settings.SpawnPoints = spawnPoints
+		logger.InfoLogger.Printf("Loaded %d spawn points from %s", len(spawnPoints), cfg.SpawnPointsFile)
+	}
+
+	if cfg.ZonePhasesFile != "" {
+		phases, err := game.LoadZonePhases(cfg.ZonePhasesFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading zone phases from %s: %w", cfg.ZonePhasesFile, err)
+		}
+		settings.ZonePhases = phases
+		logger.InfoLogger.Printf("Loaded %d zone phases from %s", len(phases), cfg.ZonePhasesFile)
+	}
+
+	auth, err := newTokenVerifier(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("loading JWT public key from %s: %w", cfg.JWTPublicKeyFile, err)
+	}
+
+	moderation, err := loadModerationLists(cfg.BannedWordsFile, cfg.BannedIPsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultRoom := newRoom(defaultRoomID, settings)
 	gs := &GameServer{
-		stateManager: game.NewStateManager(50), // Max 50 players
-		clients:      make(map[string]*WebsocketClient),
+		config:          cfg,
+		stateManager:    defaultRoom.stateManager,
+		settings:        settings,
+		defaultRoom:     defaultRoom,
+		rooms:           map[string]*Room{defaultRoomID: defaultRoom},
+		clients:         make(map[string]*WebsocketClient),
+		observers:       make(map[string]*WebsocketClient),
+		held:            make(map[string]*heldPlayer),
+		reconnectTokens: make(map[string]string),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -43,57 +251,113 @@ func newGameServer() (*GameServer, error) {
 				return true // Allow all origins for now
 			},
 		},
-		startTime: time.Now(),
+		startTime:       time.Now(),
+		newPlayerID:     func() string { return uuid.New().String() },
+		inboundMessages: newRateCounter(),
+		outboundFrames:  newRateCounter(),
+		auth:            auth,
 	}
+	gs.moderation.Store(moderation)
 
-	logger.InfoLogger.Printf("Game server initialized with max players: 50")
+	logger.InfoLogger.Printf("Game server initialized with max players: %d, game mode: %s", cfg.MaxPlayers, cfg.GameMode)
 	return gs, nil
 }
 
+// secondsToDuration converts a number of seconds from the config to a time.Duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
 // handleWebSocket upgrades HTTP connections to WebSocket connections
 func (gs *GameServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	log.Printf("WebSocket connection requested from: %s", r.RemoteAddr)
+	room, ok := gs.roomFromRequest(r)
+	if !ok {
+		http.Error(w, types.ErrRoomNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if gs.draining.Load() {
+		log.Printf("Refusing WebSocket connection from %s: %s", r.RemoteAddr, drainingReason)
+		http.Error(w, drainingReason, http.StatusServiceUnavailable)
+		return
+	}
+	if gs.moderation.Load().banned(remoteIP(r)) {
+		log.Printf("Refusing WebSocket connection from banned address %s", r.RemoteAddr)
+		http.Error(w, bannedReason, http.StatusForbidden)
+		return
+	}
+
+	// Authenticated players play under their account ID so their stats follow them across sessions
+	var playerId string
+	if gs.auth != nil {
+		subject, err := gs.auth.verify(tokenFromRequest(r))
+		if err != nil {
+			log.Printf("Rejecting WebSocket connection from %s: %v", r.RemoteAddr, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		playerId = subject
+	} else if id, ok := gs.reconnectingPlayer(r.URL.Query().Get("reconnect")); ok {
+		playerId = id
+	} else {
+		playerId = gs.newPlayerID()
+	}
+
 	conn, err := gs.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Error upgrading to WebSocket from %s: %v", r.RemoteAddr, err)
 		return
 	}
 
-	// Generate a player ID
-	playerId := uuid.New().String()
+	// A ranked player back within their grace period takes over their held player. Anyone else is added
+	// to the game state before registering the client, so a rejected connection never touches the player
+	// who may already hold the ID.
+	if heldRoom, ok := gs.reclaimPlayer(playerId); ok {
+		room = heldRoom
+		room.stateManager.ReconnectPlayer(playerId)
+	} else if err := room.stateManager.AddPlayer(playerId); err != nil {
+		gs.rejectConnection(conn, playerId, room, err)
+		return
+	} else if !gs.roomRegistered(room) {
+		// The room was reaped as empty between looking it up and joining it
+		room.stateManager.RemovePlayer(playerId)
+		gs.rejectConnection(conn, playerId, room, types.ErrRoomNotFound)
+		return
+	}
+
+	room.wakeUp()
 
 	// Create a new client
-	client := &WebsocketClient{
-		ID:   playerId,
-		Conn: conn,
-		Send: make(chan []byte, 256),
-	}
+	client := newWebsocketClient(playerId, conn)
+	client.GameID = room.ID
+	client.IP = remoteIP(r)
 
 	// Register the client
 	gs.clientsMu.Lock()
 	gs.clients[playerId] = client
-	gs.clientsMu.Unlock()
-
-	// Add player to game state
-	if err := gs.stateManager.AddPlayer(playerId); err != nil {
-		log.Printf("Error adding player %s to game state: %v", playerId, err)
-		conn.Close()
-		return
+	if len(gs.clients) > gs.peakClients {
+		gs.peakClients = len(gs.clients)
 	}
+	gs.clientsMu.Unlock()
 
-	log.Printf("Client connected: %s from %s", playerId, conn.RemoteAddr().String())
+	log.Printf("Client connected: %s from %s to room %s", playerId, conn.RemoteAddr().String(), room.ID)
 
-	// Send player ID to client
+	// Send player ID to client, along with the protocol it should answer with in its hello
 	idMsg := map[string]interface{}{
 		"type": "playerId",
-		"payload": map[string]string{
-			"id": playerId,
+		"payload": types.HandshakePayload{
+			ID:                 playerId,
+			ProtocolVersion:    types.ProtocolVersion,
+			MinProtocolVersion: types.MinProtocolVersion,
+			Features:           types.ProtocolFeatures,
+			ReconnectToken:     gs.issueReconnectToken(playerId),
 		},
 		"timestamp": time.Now().Unix(),
 	}
 	idJSON, _ := json.Marshal(idMsg)
 	client.Send <- idJSON
 	log.Printf("Sent player ID to client: %s", playerId)
+	gs.sendMOTD(client)
 
 	// Start goroutines for reading and writing
 	go gs.readPump(client)
@@ -101,7 +365,7 @@ func (gs *GameServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Started communication handlers for client: %s", playerId)
 
 	// Send initial game state
-	state := gs.stateManager.GetState()
+	state := room.stateManager.GetState()
 	stateMsg := map[string]interface{}{
 		"type":      "gameState",
 		"payload":   state,
@@ -109,27 +373,92 @@ func (gs *GameServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	stateJSON, _ := json.Marshal(stateMsg)
 	client.Send <- stateJSON
+	gs.sendPlayerUpdates(client)
 	log.Printf("Sent initial game state to client: %s", playerId)
 }
 
+// rejectConnection tells a client why it couldn't join and closes the connection. It runs before the
+// client is registered, so a rejected connection never shows up in gs.clients.
+func (gs *GameServer) rejectConnection(conn *websocket.Conn, playerId string, room *Room, err error) {
+	code, closeCode := "joinFailed", websocket.CloseInternalServerErr
+	switch {
+	case errors.Is(err, types.ErrServerFull):
+		log.Printf("Rejecting player %s: room %s full (max players: %d)", playerId, room.ID, room.stateManager.MaxPlayers())
+		code, closeCode = "serverFull", websocket.CloseTryAgainLater
+	case errors.Is(err, types.ErrPlayerAlreadyExists):
+		log.Printf("Rejecting player %s: the ID is already in use in room %s", playerId, room.ID)
+		code, closeCode = "playerIdTaken", websocket.ClosePolicyViolation
+	case errors.Is(err, types.ErrRoomNotFound):
+		log.Printf("Rejecting player %s: room %s was removed", playerId, room.ID)
+		code, closeCode = "roomNotFound", websocket.ClosePolicyViolation
+	default:
+		log.Printf("Error adding player %s to game state: %v", playerId, err)
+	}
+
+	errMsg := map[string]interface{}{
+		"type":      "error",
+		"payload":   types.ErrorMessage{Code: code, Message: err.Error()},
+		"timestamp": time.Now().Unix(),
+	}
+	errJSON, _ := json.Marshal(errMsg)
+
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	conn.WriteMessage(websocket.TextMessage, errJSON)
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, err.Error()))
+	conn.Close()
+}
+
+// motd returns the message of the day, preferring the MOTD file so it can be changed without a restart
+func (gs *GameServer) motd() string {
+	if gs.config.MOTDFile == "" {
+		return gs.config.MOTD
+	}
+
+	data, err := os.ReadFile(gs.config.MOTDFile)
+	if err != nil {
+		logger.WarningLogger.Printf("Failed to read MOTD file %s: %v", gs.config.MOTDFile, err)
+		return gs.config.MOTD
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// sendMOTD sends a newly connected client the message of the day, if there is one
+func (gs *GameServer) sendMOTD(client *WebsocketClient) {
+	motd := gs.motd()
+	if motd == "" {
+		return
+	}
+
+	motdMsg := map[string]interface{}{
+		"type":      types.MessageTypeMOTD,
+		"payload":   types.MOTDPayload{Message: motd},
+		"timestamp": time.Now().Unix(),
+	}
+	motdJSON, _ := json.Marshal(motdMsg)
+	client.Send <- motdJSON
+}
+
 // readPump pumps messages from the WebSocket to the server
 func (gs *GameServer) readPump(client *WebsocketClient) {
 	defer func() {
 		gs.clientDisconnect(client)
 	}()
 
-	client.Conn.SetReadLimit(512 * 1024) // 512KB max message size
-	client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	client.Conn.SetPongHandler(func(string) error {
-		client.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		log.Printf("Received pong from client: %s", client.ID)
-		return nil
+	// The size limit is enforced while reading below, so an oversized message can be reported to the client
+	maxMessageSize := int64(gs.config.MaxMessageSize)
+	client.Conn.SetReadDeadline(time.Now().Add(gs.pongWait()))
+	client.Conn.SetPongHandler(func(appData string) error {
+		return gs.handlePong(client, appData)
 	})
 
 	log.Printf("Started read pump for client: %s", client.ID)
 
 	for {
-		_, message, err := client.Conn.ReadMessage()
+		message, err := readLimitedMessage(client.Conn, maxMessageSize)
+		if err == errMessageTooLarge {
+			gs.rejectOversizedMessage(client, maxMessageSize)
+			break
+		}
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket read error for client %s: %v", client.ID, err)
@@ -140,20 +469,94 @@ func (gs *GameServer) readPump(client *WebsocketClient) {
 		}
 
 		// Process the message
+		client.stats.recordReceived(len(message))
+		gs.inboundMessages.add(1)
 		gs.handleMessage(client, message)
 	}
 }
 
+// errMessageTooLarge is returned by readLimitedMessage for messages over the size limit
+var errMessageTooLarge = errors.New("message too large")
+
+// readLimitedMessage reads the next message, reading no more than one byte past the size limit
+func readLimitedMessage(conn *websocket.Conn, limit int64) ([]byte, error) {
+	_, r, err := conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(message)) > limit {
+		return nil, errMessageTooLarge
+	}
+	return message, nil
+}
+
+// rejectOversizedMessage tells the client its message was too large and closes the connection
+func (gs *GameServer) rejectOversizedMessage(client *WebsocketClient, limit int64) {
+	log.Printf("Client %s sent a message larger than %d bytes, disconnecting", client.ID, limit)
+
+	errMsg := map[string]interface{}{
+		"type": "error",
+		"payload": map[string]string{
+			"code":    "messageTooLarge",
+			"message": fmt.Sprintf("messages may be at most %d bytes", limit),
+		},
+		"timestamp": time.Now().Unix(),
+	}
+	errJSON, _ := json.Marshal(errMsg)
+	client.closeWith(errJSON, websocket.CloseMessageTooBig, "message too large")
+
+	// Give the write pump a moment to deliver the error before the connection is torn down
+	select {
+	case <-client.done:
+	case <-time.After(time.Second):
+	}
+}
+
+// rejectUnsupportedVersion tells the client its protocol version isn't supported and closes the connection
+func (gs *GameServer) rejectUnsupportedVersion(client *WebsocketClient, version int) {
+	log.Printf("Client %s speaks unsupported protocol version %d, disconnecting", client.ID, version)
+
+	errMsg := map[string]interface{}{
+		"type": "error",
+		"payload": map[string]string{
+			"code": "unsupportedVersion",
+			"message": fmt.Sprintf("protocol version %d is not supported, the server supports versions %d-%d",
+				version, types.MinProtocolVersion, types.ProtocolVersion),
+		},
+		"timestamp": time.Now().Unix(),
+	}
+	errJSON, _ := json.Marshal(errMsg)
+	client.closeWith(errJSON, websocket.ClosePolicyViolation, "unsupported protocol version")
+}
+
 // writePump pumps messages from the server to the WebSocket
 func (gs *GameServer) writePump(client *WebsocketClient) {
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(gs.pingInterval())
 	defer func() {
 		ticker.Stop()
-		client.Conn.Close()
+		// A failed or stuck write means the client is gone, so remove it from the roster and the game
+		gs.clientDisconnect(client)
 	}()
 
 	for {
 		select {
+		case <-client.done:
+			return
+		case final := <-client.final:
+			client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := client.Conn.WriteMessage(websocket.TextMessage, final.message); err != nil {
+				log.Printf("Write error for client %s: %v", client.ID, err)
+				return
+			}
+			client.stats.recordSent(len(final.message), 1)
+			gs.outboundFrames.add(1)
+			client.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(final.closeCode, final.reason))
+			return
 		case message, ok := <-client.Send:
 			client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
@@ -164,23 +567,30 @@ func (gs *GameServer) writePump(client *WebsocketClient) {
 
 			w, err := client.Conn.NextWriter(websocket.TextMessage)
 			if err != nil {
+				log.Printf("Write error for client %s: %v", client.ID, err)
 				return
 			}
 			w.Write(message)
+			frameSize := len(message)
 
 			// Add queued messages to the current WebSocket message
 			n := len(client.Send)
 			for i := 0; i < n; i++ {
+				queued := <-client.Send
 				w.Write([]byte("\n"))
-				w.Write(<-client.Send)
+				w.Write(queued)
+				frameSize += 1 + len(queued)
 			}
 
 			if err := w.Close(); err != nil {
+				log.Printf("Write error for client %s: %v", client.ID, err)
 				return
 			}
+			client.stats.recordSent(frameSize, n+1)
+			gs.outboundFrames.add(1)
 		case <-ticker.C:
 			client.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := client.Conn.WriteMessage(websocket.PingMessage, pingPayload(time.Now())); err != nil {
 				return
 			}
 		}
@@ -207,7 +617,72 @@ func (gs *GameServer) handleMessage(client *WebsocketClient, message []byte) {
 		return
 	}
 
+	// Clients send their timestamp in milliseconds since the epoch
+	var timestamp time.Time
+	if ms, ok := msg["timestamp"].(float64); ok {
+		timestamp = time.UnixMilli(int64(ms))
+	}
+	if err := types.ValidateTimestamp(timestamp, time.Now(), gs.config.MaxClockSkew); err != nil {
+		logger.WarningLogger.Printf("Rejected '%s' message from client %s with timestamp %v: %v",
+			msgType, client.ID, timestamp, err)
+		errMsg := map[string]interface{}{
+			"type": "error",
+			"payload": map[string]string{
+				"code":    "INVALID_TIMESTAMP",
+				"message": err.Error(),
+			},
+			"timestamp": time.Now().Unix(),
+		}
+		errJSON, _ := json.Marshal(errMsg)
+		client.Send <- errJSON
+		return
+	}
+
+	stateManager := gs.roomOf(client).stateManager
+
 	switch msgType {
+	case "hello":
+		version, _ := payload["protocolVersion"].(float64)
+		if !types.SupportedProtocolVersion(int(version)) {
+			gs.rejectUnsupportedVersion(client, int(version))
+			return
+		}
+		log.Printf("Client %s speaks protocol version %d", client.ID, int(version))
+
+		features, _ := payload["features"].([]interface{})
+		for _, feature := range features {
+			if feature == types.FeatureCompact {
+				client.compact.Store(true)
+				log.Printf("Client %s receives compact game states", client.ID)
+			}
+			if feature == types.FeatureLatency {
+				client.reportLatency.Store(true)
+				log.Printf("Client %s is told its latency", client.ID)
+			}
+		}
+		if ms, ok := payload["minStateIntervalMs"].(float64); ok {
+			gs.requestStateInterval(client, ms)
+		}
+
+	case "whoAmI":
+		stats, ok := stateManager.GetPlayerStats(client.ID)
+		if !ok {
+			log.Printf("whoAmI from client %s that has no player", client.ID)
+			return
+		}
+		whoAmIMsg := map[string]interface{}{
+			"type": "whoAmI",
+			"payload": types.WhoAmIPayload{
+				PlayerID:    stats.ID,
+				DisplayName: stats.DisplayName,
+				Team:        stats.Team,
+				IsAlive:     stats.IsAlive,
+			},
+			"timestamp": time.Now().Unix(),
+		}
+		whoAmIJSON, _ := json.Marshal(whoAmIMsg)
+		client.Send <- whoAmIJSON
+
 	case "setName":
 		displayName, ok := payload["displayName"].(string)
 		if !ok {
@@ -215,9 +690,10 @@ func (gs *GameServer) handleMessage(client *WebsocketClient, message []byte) {
 			return
 		}
 
+		displayName = gs.moderation.Load().censor(displayName)
 		log.Printf("Client %s setting name to: '%s'", client.ID, displayName)
 
-		if err := gs.stateManager.UpdatePlayerName(client.ID, displayName); err != nil {
+		if err := stateManager.UpdatePlayerName(client.ID, displayName); err != nil {
 			log.Printf("Error updating player name for client %s: %v", client.ID, err)
 			errMsg := map[string]interface{}{
 				"type": "error",
@@ -231,6 +707,23 @@ func (gs *GameServer) handleMessage(client *WebsocketClient, message []byte) {
 			client.Send <- errJSON
 		}
 
+	case "chat":
+		text, _ := payload["text"].(string)
+		text = gs.moderation.Load().censor(text)
+		if err := stateManager.SendChat(client.ID, text); err != nil {
+			log.Printf("Rejected chat message from client %s: %v", client.ID, err)
+			errMsg := map[string]interface{}{
+				"type": "error",
+				"payload": map[string]string{
+					"code":    "CHAT_ERROR",
+					"message": err.Error(),
+				},
+				"timestamp": time.Now().Unix(),
+			}
+			errJSON, _ := json.Marshal(errMsg)
+			client.Send <- errJSON
+		}
+
 	case "playerAction":
 		action := types.PlayerAction{}
 		action.Type, _ = payload["type"].(string)
@@ -301,26 +794,36 @@ func (gs *GameServer) handleMessage(client *WebsocketClient, message []byte) {
 				action.Data.WeaponID = weaponId
 			}
 
+			// Handle targetId
+			if targetId, ok := actionData["targetId"].(string); ok {
+				action.Data.TargetID = targetId
+			}
+
+			// Handle stance
+			if stance, ok := actionData["stance"].(string); ok {
+				action.Data.Stance = types.Stance(stance)
+			}
+
+			// Handle hitPoint, where the client saw its shot hit a player or an obstacle
+			if hitPointData, ok := actionData["hitPoint"].(map[string]interface{}); ok {
+				hitPoint := &types.Vector3{}
+				if x, ok := hitPointData["x"].(float64); ok {
+					hitPoint.X = x
+				}
+				if y, ok := hitPointData["y"].(float64); ok {
+					hitPoint.Y = y
+				}
+				if z, ok := hitPointData["z"].(float64); ok {
+					hitPoint.Z = z
+				}
+				action.Data.HitPoint = hitPoint
+			}
+
 			// Handle hitObstacle
 			if hitObstacle, ok := actionData["hitObstacle"].(bool); ok {
 				boolVal := hitObstacle
 				action.Data.HitObstacle = &boolVal
 
-				// Handle hitPoint if there's an obstacle hit
-				if hitPointData, ok := actionData["hitPoint"].(map[string]interface{}); ok {
-					hitPoint := &types.Vector3{}
-					if x, ok := hitPointData["x"].(float64); ok {
-						hitPoint.X = x
-					}
-					if y, ok := hitPointData["y"].(float64); ok {
-						hitPoint.Y = y
-					}
-					if z, ok := hitPointData["z"].(float64); ok {
-						hitPoint.Z = z
-					}
-					action.Data.HitPoint = hitPoint
-				}
-
 				// Handle hitDistance
 				if hitDistance, ok := actionData["hitDistance"].(float64); ok {
 					distance := hitDistance
@@ -329,19 +832,26 @@ func (gs *GameServer) handleMessage(client *WebsocketClient, message []byte) {
 			}
 		}
 
-		if err := gs.stateManager.HandlePlayerAction(client.ID, action); err != nil {
+		if err := stateManager.HandlePlayerAction(client.ID, action); err != nil {
 			log.Printf("Error handling action '%s' from client %s: %v", action.Type, client.ID, err)
+			// Shots fired too fast get their own code so the client can resync its fire timing
+			code := "ACTION_ERROR"
+			if errors.Is(err, types.ErrFiringTooFast) {
+				code = "RATE_LIMITED"
+			}
 			errMsg := map[string]interface{}{
 				"type": "error",
 				"payload": map[string]string{
-					"code":    "ACTION_ERROR",
+					"code":    code,
 					"message": err.Error(),
 				},
 				"timestamp": time.Now().Unix(),
 			}
 			errJSON, _ := json.Marshal(errMsg)
 			client.Send <- errJSON
+			return
 		}
+
 	default:
 		log.Printf("Received unknown message type '%s' from client %s", msgType, client.ID)
 	}
@@ -349,6 +859,11 @@ func (gs *GameServer) handleMessage(client *WebsocketClient, message []byte) {
 
 // clientDisconnect handles client disconnection
 func (gs *GameServer) clientDisconnect(client *WebsocketClient) {
+	if client.IsObserver {
+		gs.observerDisconnect(client)
+		return
+	}
+
 	gs.clientsMu.Lock()
 	defer gs.clientsMu.Unlock()
 
@@ -359,11 +874,18 @@ func (gs *GameServer) clientDisconnect(client *WebsocketClient) {
 
 	log.Printf("Client disconnecting: %s", client.ID)
 
-	// Remove player from game state
-	gs.stateManager.RemovePlayer(client.ID)
+	// Remove player from game state, ranked players are held for a while so a dropped connection
+	// doesn't forfeit their place
+	room := gs.roomOf(client)
+	if gs.holdsPlayers() {
+		gs.holdPlayer(room, client.ID)
+	} else {
+		room.stateManager.RemovePlayer(client.ID)
+	}
 
-	// Close connection
+	// Close connection and stop the write pump
 	client.Conn.Close()
+	close(client.done)
 
 	// Delete client
 	delete(gs.clients, client.ID)
@@ -371,19 +893,18 @@ func (gs *GameServer) clientDisconnect(client *WebsocketClient) {
 	log.Printf("Client disconnected and removed: %s", client.ID)
 
 	// Broadcast updated game state
-	go gs.broadcastGameState(gs.stateManager.GetState())
+	go gs.broadcastGameState(room)
 }
 
-// broadcastGameState broadcasts the game state to all clients
-func (gs *GameServer) broadcastGameState(state *types.GameState) {
-	gs.clientsMu.RLock()
-	defer gs.clientsMu.RUnlock()
-
+// broadcastGameState broadcasts a room's game state to the clients in it
+func (gs *GameServer) broadcastGameState(room *Room) {
 	// Create state message
+	now := time.Now()
+	state := room.stateManager.GetState()
 	stateMsg := map[string]interface{}{
 		"type":      "gameState",
 		"payload":   state,
-		"timestamp": time.Now().Unix(),
+		"timestamp": now.Unix(),
 	}
 	stateJSON, err := json.Marshal(stateMsg)
 	if err != nil {
@@ -391,50 +912,234 @@ func (gs *GameServer) broadcastGameState(state *types.GameState) {
 		return
 	}
 
-	// Send to all clients
+	// The compact state is only marshalled when a client asked for it
+	var compactJSON []byte
+	compactMessage := func(state *types.GameState) ([]byte, error) {
+		return json.Marshal(map[string]interface{}{
+			"type":      "gameState",
+			"payload":   state.Compact(),
+			"timestamp": stateMsg["timestamp"],
+		})
+	}
+
+	gs.clientsMu.RLock()
+
+	// Send to all clients, collecting the ones that can't keep up
+	var slowClients []*WebsocketClient
 	for _, client := range gs.clients {
+		if client.GameID != room.ID || !gs.stateDue(client, now) {
+			continue
+		}
+		// Distant players go out every few states the client receives, however often that is
+		frame := client.stateFrames.Add(1) - 1
+		message := stateJSON
+		if client.compact.Load() {
+			if compactJSON == nil {
+				if compactJSON, err = compactMessage(state); err != nil {
+					log.Printf("Error marshaling compact game state: %v", err)
+					continue
+				}
+			}
+			message = compactJSON
+		}
+
+		// Spectators following a player get the state around their target, players with a
+		// view distance limit or broadcast prioritization the players relevant to them
+		view, ok := room.stateManager.GetSpectatorState(client.ID)
+		if !ok {
+			view, ok = room.stateManager.GetPlayerView(client.ID, frame)
+		}
+		if ok {
+			stateMsg["payload"] = view
+			if client.compact.Load() {
+				message, err = compactMessage(view)
+			} else {
+				message, err = json.Marshal(stateMsg)
+			}
+			if err != nil {
+				log.Printf("Error marshaling limited state for %s: %v", client.ID, err)
+				continue
+			}
+		}
+
 		select {
-		case client.Send <- stateJSON:
+		case client.Send <- message:
 			// Message sent successfully
 		default:
-			// Client send buffer is full, disconnect client
-			log.Printf("Client %s send buffer full, disconnecting", client.ID)
-			gs.clientDisconnect(client)
+			slowClients = append(slowClients, client)
 		}
 	}
+	gs.clientsMu.RUnlock()
+
+	// Observers share the same marshalled state, limited like spectators unless they can see everyone
+	stateMsg["payload"] = room.stateManager.GetObserverState()
+	if observerJSON, err := json.Marshal(stateMsg); err != nil {
+		log.Printf("Error marshaling observer state: %v", err)
+	} else {
+		slowClients = append(slowClients, gs.sendToObservers(room.ID, observerJSON)...)
+	}
+
+	// Disconnect outside the read lock, since clientDisconnect needs the write lock
+	for _, client := range slowClients {
+		gs.handleSlowClient(client)
+	}
 }
 
-// run updates and broadcasts the game state at regular intervals
-func (gs *GameServer) run() {
-	ticker := time.NewTicker(time.Second / 20) // 20 updates per second
-	defer ticker.Stop()
+// handleSlowClient applies the slow client policy to a client whose send buffer is full
+func (gs *GameServer) handleSlowClient(client *WebsocketClient) {
+	if gs.config.SlowClientPolicy == config.SlowClientDrop {
+		gs.droppedFrames.Add(1)
+		logger.DebugLogger.Printf("Client %s send buffer full, dropping state frame", client.ID)
+		return
+	}
+
+	gs.slowClientDisconnects.Add(1)
+	log.Printf("Client %s send buffer full, disconnecting", client.ID)
+	gs.clientDisconnect(client)
+}
+
+// dispatchEvents delivers the events produced by a room's game to their recipients
+func (gs *GameServer) dispatchEvents(room *Room) {
+	for _, event := range room.stateManager.DrainEvents() {
+		eventMsg := map[string]interface{}{
+			"type":      event.Type,
+			"payload":   event.Payload,
+			"timestamp": time.Now().Unix(),
+		}
+		eventJSON, err := json.Marshal(eventMsg)
+		if err != nil {
+			log.Printf("Error marshaling %s event: %v", event.Type, err)
+			continue
+		}
+
+		if event.PlayerID == "" {
+			gs.broadcastMessage(room.ID, eventJSON)
+		} else {
+			gs.sendToPlayer(event.PlayerID, eventJSON)
+		}
+	}
+}
+
+// broadcastMessage queues a message for every client and observer in a room, skipping those whose buffer is full
+func (gs *GameServer) broadcastMessage(roomID string, message []byte) {
+	gs.clientsMu.RLock()
+	for _, client := range gs.clients {
+		if client.GameID != roomID {
+			continue
+		}
+		select {
+		case client.Send <- message:
+		default:
+			log.Printf("Client %s send buffer full, dropping message", client.ID)
+		}
+	}
+	gs.clientsMu.RUnlock()
+
+	gs.observersMu.RLock()
+	for _, observer := range gs.observers {
+		if observer.GameID != roomID {
+			continue
+		}
+		select {
+		case observer.Send <- message:
+		default:
+		}
+	}
+	gs.observersMu.RUnlock()
+}
+
+// sendToPlayer queues a message for a single client, skipping it if their buffer is full
+func (gs *GameServer) sendToPlayer(playerID string, message []byte) {
+	gs.clientsMu.RLock()
+	defer gs.clientsMu.RUnlock()
 
-	log.Printf("Game server loop started at %d updates per second", 20)
+	client, ok := gs.clients[playerID]
+	if !ok {
+		return
+	}
 
-	updateCount := 0
-	for range ticker.C {
-		gs.stateManager.Update()
-		gs.broadcastGameState(gs.stateManager.GetState())
+	select {
+	case client.Send <- message:
+	default:
+		log.Printf("Client %s send buffer full, dropping message", client.ID)
+	}
+}
 
-		updateCount++
-		if updateCount%100 == 0 { // Log every 100 updates (about 5 seconds)
-			gs.clientsMu.RLock()
-			playerCount := len(gs.clients)
-			gs.clientsMu.RUnlock()
-			state := gs.stateManager.GetState()
-			log.Printf("Server status: %d clients connected, game active: %v, game time: %.2f",
-				playerCount, state.IsGameActive, state.GameTime)
+// sendPlayerUpdates sends a newly connected client the names, teams and weapons of everyone in the game
+func (gs *GameServer) sendPlayerUpdates(client *WebsocketClient) {
+	for _, update := range gs.roomOf(client).stateManager.PlayerUpdates() {
+		updateJSON, err := json.Marshal(map[string]interface{}{
+			"type":      types.MessageTypePlayerUpdate,
+			"payload":   update,
+			"timestamp": time.Now().Unix(),
+		})
+		if err != nil {
+			log.Printf("Error marshaling player update for %s: %v", update.PlayerID, err)
+			continue
 		}
+		client.Send <- updateJSON
+	}
+}
+
+// run runs the default room's game loop
+func (gs *GameServer) run() {
+	gs.runRoom(gs.defaultRoom)
+}
+
+// isLive reports whether the game loop has ticked recently
+func (gs *GameServer) isLive(now time.Time) bool {
+	lastHeartbeat := time.Unix(0, gs.lastHeartbeat.Load())
+	// An idle room ticks less often than the timeout
+	return now.Sub(lastHeartbeat) <= max(livenessTimeout, 2*gs.defaultRoom.tickInterval())
+}
+
+// isReady reports whether the server is accepting new players, and why not if it isn't
+func (gs *GameServer) isReady() (bool, string) {
+	if gs.shuttingDown.Load() {
+		return false, "shutting down"
+	}
+	if gs.draining.Load() {
+		return false, drainingReason
+	}
+
+	// New players join the default room, players in other rooms and spectators don't take its slots
+	if gs.stateManager.IsFull() {
+		return false, "server full"
+	}
+	return true, ""
+}
+
+// drain stops accepting new players while existing clients keep playing the current match
+func (gs *GameServer) drain() {
+	if gs.draining.Swap(true) {
+		return
 	}
+	logger.InfoLogger.Printf("Draining: refusing new players, existing clients keep playing")
 }
 
 func (gs *GameServer) close() {
+	gs.shuttingDown.Store(true)
+	gs.stopHolding()
+
+	gs.roomsMu.RLock()
+	for _, room := range gs.rooms {
+		room.stop()
+	}
+	gs.roomsMu.RUnlock()
+
 	gs.clientsMu.Lock()
 	for _, client := range gs.clients {
 		client.Conn.Close()
 	}
 	gs.clients = make(map[string]*WebsocketClient)
 	gs.clientsMu.Unlock()
+
+	gs.observersMu.Lock()
+	for _, observer := range gs.observers {
+		observer.Conn.Close()
+	}
+	gs.observers = make(map[string]*WebsocketClient)
+	gs.observersMu.Unlock()
 }
 
 func main() {
@@ -447,7 +1152,7 @@ func main() {
 	logger.InfoLogger.Printf("Server starting on :%s (TLS: %v, Environment: %s)",
 		cfg.Port, cfg.UseTLS, map[bool]string{true: "development", false: "production"}[cfg.IsDevelopment])
 
-	gs, err := newGameServer()
+	gs, err := newGameServer(cfg)
 	if err != nil {
 		logger.ErrorLogger.Fatalf("Failed to create game server: %v", err)
 	}
@@ -457,45 +1162,187 @@ func main() {
 	logger.InfoLogger.Printf("Game loop started")
 
 	// Set up HTTP routes
+	mux := gs.routes()
+
+	// Add CORS middleware
+	handler := corsMiddleware(mux)
+
+	// Start HTTP server
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	logger.InfoLogger.Printf("HTTP server listening on :%s", cfg.Port)
+
+	// Let the current match finish without new players on SIGUSR1, ahead of a rolling deploy
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGUSR1)
+		for range signals {
+			gs.drain()
+		}
+	}()
+
+	// Stop accepting players and shut down cleanly on SIGINT/SIGTERM
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-signals
+
+		logger.InfoLogger.Printf("Received %v, shutting down", sig)
+		gs.close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.ErrorLogger.Printf("Error during server shutdown: %v", err)
+		}
+	}()
+
+	// Use TLS if cert and key files are provided
+	if cfg.UseTLS {
+		logger.InfoLogger.Printf("Starting server with TLS using cert: %s and key: %s", cfg.CertFile, cfg.KeyFile)
+		if err := server.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile); err != nil && err != http.ErrServerClosed {
+			logger.ErrorLogger.Fatalf("Failed to start TLS server: %v", err)
+		}
+	} else {
+		logger.InfoLogger.Printf("Starting server without TLS")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.ErrorLogger.Fatalf("Failed to start server: %v", err)
+		}
+	}
+}
+
+// routes sets up the HTTP routes served by the game server
+func (gs *GameServer) routes() *http.ServeMux {
 	// Using http.ServeMux instead of gorilla/mux
 	mux := http.NewServeMux()
 	mux.HandleFunc("/ws", gs.handleWebSocket)
+	mux.HandleFunc("/ws/observe", gs.handleObserve)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		logger.DebugLogger.Printf("Health check received")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		if !gs.isLive(time.Now()) {
+			logger.WarningLogger.Printf("Liveness check failed: game loop has not ticked within %v", livenessTimeout)
+			http.Error(w, "game loop stalled", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if ready, reason := gs.isReady(); !ready {
+			logger.DebugLogger.Printf("Readiness check failed: %s", reason)
+			http.Error(w, reason, http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("/metrics", gs.handleMetrics)
 	mux.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
 		logger.DebugLogger.Printf("Status request received")
 		gs.clientsMu.RLock()
 		clientCount := len(gs.clients)
+		peakClients := gs.peakClients
 		gs.clientsMu.RUnlock()
 
 		state := gs.stateManager.GetState()
+		zone := gs.stateManager.GetZone()
 		status := map[string]interface{}{
-			"clients":      clientCount,
-			"gameActive":   state.IsGameActive,
-			"gameTime":     state.GameTime,
-			"matchId":      state.MatchID,
-			"serverUptime": time.Since(gs.startTime).String(),
+			"clients":               clientCount,
+			"peakClients":           peakClients,
+			"maxPlayers":            gs.stateManager.MaxPlayers(),
+			"gameActive":            state.IsGameActive,
+			"matchState":            state.MatchState,
+			"gameTime":              state.GameTime,
+			"matchId":               state.MatchID,
+			"serverUptime":          time.Since(gs.startTime).String(),
+			"slowClientDisconnects": gs.slowClientDisconnects.Load(),
+			"droppedFrames":         gs.droppedFrames.Load(),
+			"gameMode":              gs.stateManager.GameMode(),
+			"map":                   gs.stateManager.MapName(),
+			"zone":                  zone,
+			"phase":                 zone.Phase,
+			"messagesInPerSecond":   gs.inboundMessages.rate(),
+			"framesOutPerSecond":    gs.outboundFrames.rate(),
+			"latencyMs":             gs.clientLatencies(),
 		}
 
 		json.NewEncoder(w).Encode(status)
 		logger.DebugLogger.Printf("Status request: %d clients, game active: %v", clientCount, state.IsGameActive)
 	})
 
+	mux.HandleFunc("GET /api/serverinfo", gs.handleServerInfo)
+
+	mux.HandleFunc("GET /api/players/{id}", func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := gs.stateManager.GetPlayerStats(r.PathValue("id"))
+		if !ok {
+			writeAPIError(w, types.ErrPlayerNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	})
+	mux.HandleFunc("/api/map", func(w http.ResponseWriter, r *http.Request) {
+		obstacles := gs.stateManager.Obstacles()
+		if obstacles == nil {
+			obstacles = []types.Obstacle{}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"worldRadius": gs.stateManager.WorldRadius(),
+			"obstacles":   obstacles,
+		})
+	})
+
+	// Profiling and admin endpoints, all behind the admin token
+	if gs.config.EnablePprof {
+		gs.registerPprof(mux)
+	}
+
+	mux.HandleFunc("GET /api/admin/clients", gs.requireAdmin(gs.handleClientBandwidth))
+	mux.HandleFunc("POST /api/admin/drain", gs.requireAdmin(gs.handleAdminDrain))
+	mux.HandleFunc("POST /api/admin/reload", gs.requireAdmin(gs.handleAdminReload))
+	mux.HandleFunc("GET /api/debug/state", gs.requireAdmin(gs.handleDebugState))
+	mux.HandleFunc("POST /api/admin/player/{id}/respawn", gs.requireAdmin(gs.handleAdminPlayerAction((*game.StateManager).RespawnPlayer)))
+	mux.HandleFunc("POST /api/admin/player/{id}/heal", gs.requireAdmin(gs.handleAdminPlayerAction((*game.StateManager).HealPlayer)))
+	mux.HandleFunc("POST /api/admin/player/{id}/teleport", gs.requireAdmin(gs.handleAdminTeleport))
+	mux.HandleFunc("POST /api/admin/player/{id}/mute", gs.requireAdmin(gs.handleAdminMute))
+	mux.HandleFunc("POST /api/admin/player/{id}/unmute", gs.requireAdmin(gs.handleAdminPlayerAction((*game.StateManager).UnmutePlayer)))
+
+	// Rooms run separate matches, each with its own capacity and tick rate
+	mux.HandleFunc("GET /api/rooms", gs.handleListRooms)
+	mux.HandleFunc("GET /api/rooms/status", gs.handleRoomsStatus)
+	mux.HandleFunc("GET /api/matches/{id}/timeline", gs.handleMatchTimeline)
+	mux.HandleFunc("POST /api/rooms", gs.handleCreateRoom)
+
 	// API endpoints for game control
 	mux.HandleFunc("/api/game/start", func(w http.ResponseWriter, r *http.Request) {
 		logger.DebugLogger.Printf("API request to start game received")
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeJSONError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed", nil)
 			return
 		}
 
-		err := gs.stateManager.StartGame()
+		room, ok := gs.roomFromRequest(r)
+		if !ok {
+			writeAPIError(w, types.ErrRoomNotFound)
+			return
+		}
+
+		err := room.stateManager.StartGame()
 		if err != nil {
 			logger.ErrorLogger.Printf("Failed to start game: %v", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeAPIError(w, err)
 			return
 		}
 
@@ -504,24 +1351,51 @@ func main() {
 		w.Write([]byte("Game started"))
 
 		// Broadcast updated game state
-		go gs.broadcastGameState(gs.stateManager.GetState())
+		go gs.broadcastGameState(room)
+	})
+
+	mux.HandleFunc("POST /api/game/reset", func(w http.ResponseWriter, r *http.Request) {
+		room, ok := gs.roomFromRequest(r)
+		if !ok {
+			writeAPIError(w, types.ErrRoomNotFound)
+			return
+		}
+
+		if err := room.stateManager.ResetMatch(); err != nil {
+			logger.ErrorLogger.Printf("Failed to reset match: %v", err)
+			writeAPIError(w, err)
+			return
+		}
+
+		logger.InfoLogger.Printf("Match reset via API")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Match reset"))
+
+		// Broadcast updated game state
+		go gs.broadcastGameState(room)
 	})
 
 	mux.HandleFunc("/api/game/end", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeJSONError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed", nil)
+			return
+		}
+
+		room, ok := gs.roomFromRequest(r)
+		if !ok {
+			writeAPIError(w, types.ErrRoomNotFound)
 			return
 		}
 
 		logger.DebugLogger.Printf("API request to end game received")
-		gs.stateManager.EndGame()
+		room.stateManager.EndGame()
 		logger.InfoLogger.Printf("Game ended via API")
 
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("Game ended"))
 
 		// Broadcast updated game state
-		go gs.broadcastGameState(gs.stateManager.GetState())
+		go gs.broadcastGameState(room)
 	})
 
 	// Handle static files
@@ -529,32 +1403,7 @@ func main() {
 	staticFileServer := http.FileServer(staticDir)
 	mux.Handle("/", staticFileServer)
 
-	// Add CORS middleware
-	handler := corsMiddleware(mux)
-
-	// Start HTTP server
-	server := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	logger.InfoLogger.Printf("HTTP server listening on :%s", cfg.Port)
-
-	// Use TLS if cert and key files are provided
-	if cfg.UseTLS {
-		logger.InfoLogger.Printf("Starting server with TLS using cert: %s and key: %s", cfg.CertFile, cfg.KeyFile)
-		if err := server.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile); err != nil {
-			logger.ErrorLogger.Fatalf("Failed to start TLS server: %v", err)
-		}
-	} else {
-		logger.InfoLogger.Printf("Starting server without TLS")
-		if err := server.ListenAndServe(); err != nil {
-			logger.ErrorLogger.Fatalf("Failed to start server: %v", err)
-		}
-	}
+	return mux
 }
 
 // CORS middleware function
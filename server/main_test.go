@@ -0,0 +1,1765 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"finalcircle/server/config"
+	"finalcircle/server/game"
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(true)
+	os.Exit(m.Run())
+}
+
+// newTestServer creates a game server with its routes served over httptest
+func newTestServer(t *testing.T) (*GameServer, *httptest.Server) {
+	t.Helper()
+
+	return newTestServerWithConfig(t, config.LoadConfig())
+}
+
+// newTestServerWithConfig creates a game server from cfg with its routes served over httptest
+func newTestServerWithConfig(t *testing.T, cfg *config.Config) (*GameServer, *httptest.Server) {
+	t.Helper()
+
+	gs, err := newGameServer(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create game server: %v", err)
+	}
+
+	srv := httptest.NewServer(gs.routes())
+	t.Cleanup(func() {
+		gs.close()
+		srv.Close()
+	})
+	return gs, srv
+}
+
+func TestLivezReportsStaleHeartbeat(t *testing.T) {
+	gs, srv := newTestServer(t)
+
+	// A fresh heartbeat means the game loop is alive
+	gs.lastHeartbeat.Store(time.Now().UnixNano())
+	resp, err := http.Get(srv.URL + "/livez")
+	if err != nil {
+		t.Fatalf("Failed to query /livez: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected /livez to be OK with a fresh heartbeat, got %d", resp.StatusCode)
+	}
+
+	// A heartbeat older than the liveness timeout means the game loop has died
+	gs.lastHeartbeat.Store(time.Now().Add(-5 * time.Second).UnixNano())
+	resp, err = http.Get(srv.URL + "/livez")
+	if err != nil {
+		t.Fatalf("Failed to query /livez: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected /livez to be unavailable with a stale heartbeat, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadyzReportsShutdown(t *testing.T) {
+	gs, srv := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("Failed to query /readyz: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected /readyz to be OK, got %d", resp.StatusCode)
+	}
+
+	gs.shuttingDown.Store(true)
+	resp, err = http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("Failed to query /readyz: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz to be unavailable while shutting down, got %d", resp.StatusCode)
+	}
+}
+
+func TestReadyzCountsOnlyTheDefaultRoomsPlayers(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.MaxPlayers = 2
+	gs, srv := newTestServerWithConfig(t, cfg)
+
+	readyz := func() int {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/readyz")
+		if err != nil {
+			t.Fatalf("Failed to query /readyz: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Players connected to another room don't take the default room's slots
+	if _, err := gs.createRoom(RoomConfig{ID: "other"}); err != nil {
+		t.Fatalf("Failed to create room: %v", err)
+	}
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?room=other"
+	for i := 0; i < 2; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatalf("Failed to join the other room: %v", err)
+		}
+		defer conn.Close()
+		readMessage(t, conn, "playerId")
+	}
+	if status := readyz(); status != http.StatusOK {
+		t.Errorf("Expected /readyz to be OK with the default room empty, got %d", status)
+	}
+
+	for _, id := range []string{"player-1", "player-2"} {
+		if err := gs.stateManager.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if status := readyz(); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz to be unavailable with the default room full, got %d", status)
+	}
+}
+
+// dialTestClient connects a WebSocket client to the test server and returns it with its player ID
+func dialTestClient(t *testing.T, srv *httptest.Server) (*websocket.Conn, string) {
+	t.Helper()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", wsURL, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	idMsg := readMessage(t, conn, "playerId")
+	playerId, _ := idMsg["payload"].(map[string]interface{})["id"].(string)
+	if playerId == "" {
+		t.Fatal("Expected playerId message to carry an id")
+	}
+	return conn, playerId
+}
+
+// readMessage reads from a connection until a message of the given type arrives
+func readMessage(t *testing.T, conn *websocket.Conn, msgType string) map[string]interface{} {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed waiting for %q message: %v", msgType, err)
+		}
+
+		// The write pump batches queued messages separated by newlines
+		for _, raw := range bytes.Split(data, []byte("\n")) {
+			var msg map[string]interface{}
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if msg["type"] == msgType {
+				return msg
+			}
+		}
+	}
+}
+
+// sendClientMessage sends a message the way the client does, stamped with the given time
+func sendClientMessage(t *testing.T, conn *websocket.Conn, msgType string, payload map[string]interface{}, timestamp time.Time) {
+	t.Helper()
+
+	err := conn.WriteJSON(map[string]interface{}{
+		"type":      msgType,
+		"payload":   payload,
+		"timestamp": timestamp.UnixMilli(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to send %q message: %v", msgType, err)
+	}
+}
+
+// waitFor polls a condition until it holds or the timeout expires
+func waitFor(t *testing.T, condition func() bool) bool {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestHandleMessageRejectsStaleTimestamp(t *testing.T) {
+	gs, srv := newTestServer(t)
+	conn, playerId := dialTestClient(t, srv)
+
+	// A message dated an hour ago is rejected without being applied
+	sendClientMessage(t, conn, "setName", map[string]interface{}{"displayName": "Replayed"}, time.Now().Add(-time.Hour))
+
+	errMsg := readMessage(t, conn, "error")
+	if code := errMsg["payload"].(map[string]interface{})["code"]; code != "INVALID_TIMESTAMP" {
+		t.Errorf("Expected INVALID_TIMESTAMP error, got %v", code)
+	}
+
+	// A current message goes through
+	sendClientMessage(t, conn, "setName", map[string]interface{}{"displayName": "Current"}, time.Now())
+	renamed := waitFor(t, func() bool {
+		name, _ := gs.stateManager.GetPlayerName(playerId)
+		return name == "Current"
+	})
+	if !renamed {
+		t.Error("Expected name to be updated by the current message")
+	}
+}
+
+// newStalledClient registers a player whose connection has no pumps running, so nothing drains its send buffer
+func newStalledClient(t *testing.T, gs *GameServer, id string) *WebsocketClient {
+	t.Helper()
+
+	// Hand the server side of a WebSocket connection to the test without starting any pumps
+	serverConns := make(chan *websocket.Conn, 1)
+	upgradeSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := gs.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade: %v", err)
+			return
+		}
+		serverConns <- conn
+	}))
+	t.Cleanup(upgradeSrv.Close)
+
+	clientConn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(upgradeSrv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { clientConn.Close() })
+
+	client := newWebsocketClient(id, <-serverConns)
+	gs.clientsMu.Lock()
+	gs.clients[client.ID] = client
+	gs.clientsMu.Unlock()
+	if err := gs.stateManager.AddPlayer(client.ID); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	return client
+}
+
+func TestWriteFailureCleansUpPlayer(t *testing.T) {
+	gs, _ := newTestServer(t)
+	client := newStalledClient(t, gs, "stuck-player")
+
+	// Break the underlying connection so the next write fails
+	client.Conn.NetConn().Close()
+	go gs.writePump(client)
+	client.Send <- []byte(`{"type":"gameState"}`)
+
+	cleanedUp := waitFor(t, func() bool {
+		gs.clientsMu.RLock()
+		_, stillClient := gs.clients[client.ID]
+		gs.clientsMu.RUnlock()
+		_, stillPlayer := gs.stateManager.GetPlayerPosition(client.ID)
+		return !stillClient && !stillPlayer
+	})
+	if !cleanedUp {
+		t.Error("Expected a failed write to remove the player from the clients and the game state")
+	}
+}
+
+func TestObserverReceivesStateButCannotAct(t *testing.T) {
+	gs, srv := newTestServer(t)
+	_, playerId := dialTestClient(t, srv)
+
+	observeURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/observe"
+	observer, _, err := websocket.DefaultDialer.Dial(observeURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect observer: %v", err)
+	}
+	defer observer.Close()
+
+	stateMsg := readMessage(t, observer, "gameState")
+	players := stateMsg["payload"].(map[string]interface{})["players"].(map[string]interface{})
+	if _, ok := players[playerId]; !ok {
+		t.Errorf("Expected observer state to include player %s", playerId)
+	}
+
+	// Actions from an observer are ignored
+	sendClientMessage(t, observer, "playerAction", map[string]interface{}{
+		"type": "move",
+		"data": map[string]interface{}{"position": map[string]interface{}{"x": 1.0, "y": 0.0, "z": 1.0}},
+	}, time.Now())
+	sendClientMessage(t, observer, "setName", map[string]interface{}{"displayName": "Caster"}, time.Now())
+
+	// Observers keep receiving broadcasts without becoming players
+	gs.broadcastGameState(gs.defaultRoom)
+	readMessage(t, observer, "gameState")
+
+	if count := len(gs.stateManager.GetState().Players); count != 1 {
+		t.Errorf("Expected only the real player in the game, got %d players", count)
+	}
+	gs.clientsMu.RLock()
+	clientCount := len(gs.clients)
+	gs.clientsMu.RUnlock()
+	if clientCount != 1 {
+		t.Errorf("Expected observer not to be registered as a client, got %d clients", clientCount)
+	}
+	gs.observersMu.RLock()
+	observerCount := len(gs.observers)
+	gs.observersMu.RUnlock()
+	if observerCount != 1 {
+		t.Errorf("Expected 1 observer, got %d", observerCount)
+	}
+}
+
+// getJSON fetches a URL and decodes its JSON body
+func getJSON(t *testing.T, url string) map[string]interface{} {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response from %s: %v", url, err)
+	}
+	return body
+}
+
+// isHeld reports whether a player is held after their connection dropped. It reads the locked copy
+// DebugState makes, since the disconnect handler writes the live player concurrently.
+func isHeld(sm *game.StateManager, id string) bool {
+	players, _ := sm.DebugState()["state"].(map[string]interface{})["players"].(map[string]interface{})
+	player, _ := players[id].(map[string]interface{})
+	return player["disconnected"] == true
+}
+
+// clientCount returns the number of registered clients
+func clientCount(gs *GameServer) int {
+	gs.clientsMu.RLock()
+	defer gs.clientsMu.RUnlock()
+	return len(gs.clients)
+}
+
+func TestConnectingToAFullServerIsRejectedWithoutAGhostClient(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.MaxPlayers = 1
+	gs, srv := newTestServerWithConfig(t, cfg)
+	dialTestClient(t, srv)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", wsURL, err)
+	}
+	defer conn.Close()
+
+	errMsg := readMessage(t, conn, "error")
+	if code := errMsg["payload"].(map[string]interface{})["code"]; code != "serverFull" {
+		t.Errorf("Expected a serverFull error, got %v", code)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+		t.Errorf("Expected the connection to be closed with try again later, got %v", err)
+	}
+
+	if count := clientCount(gs); count != 1 {
+		t.Errorf("Expected only the first player to be registered, got %d clients", count)
+	}
+	if count := len(gs.stateManager.GetState().Players); count != 1 {
+		t.Errorf("Expected only the first player in the game, got %d players", count)
+	}
+}
+
+func TestServerInfoReportsVersionAndFeatureFlags(t *testing.T) {
+	_, srv := newTestServer(t)
+	info := getJSON(t, srv.URL+"/api/serverinfo")
+
+	for _, field := range []string{"version", "build", "protocolVersion", "protocolFeatures", "featureFlags", "maxPlayers", "tickRate"} {
+		if _, ok := info[field]; !ok {
+			t.Errorf("Expected server info to carry %q, got %v", field, info)
+		}
+	}
+	if info["version"] != version || info["maxPlayers"] != float64(config.DefaultMaxPlayers) {
+		t.Errorf("Expected version %s and %d players, got %v and %v", version, config.DefaultMaxPlayers, info["version"], info["maxPlayers"])
+	}
+	if flags := info["featureFlags"].(map[string]interface{}); flags["fallDamage"] != false {
+		t.Errorf("Expected fall damage to be off by default, got %v", flags["fallDamage"])
+	}
+
+	cfg := config.LoadConfig()
+	cfg.FallDamage = true
+	_, srv = newTestServerWithConfig(t, cfg)
+	flags := getJSON(t, srv.URL+"/api/serverinfo")["featureFlags"].(map[string]interface{})
+	if flags["fallDamage"] != true {
+		t.Errorf("Expected the fallDamage flag to follow the config, got %v", flags["fallDamage"])
+	}
+}
+
+// dialReconnectableClient connects a player to a ranked server, returning their ID and reconnect token
+func dialReconnectableClient(t *testing.T, srv *httptest.Server, token string) (*websocket.Conn, string, string) {
+	t.Helper()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?reconnect=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", wsURL, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	payload := readMessage(t, conn, "playerId")["payload"].(map[string]interface{})
+	id, _ := payload["id"].(string)
+	reconnectToken, _ := payload["reconnectToken"].(string)
+	if reconnectToken == "" {
+		t.Fatal("Expected a ranked server to issue a reconnect token")
+	}
+	return conn, id, reconnectToken
+}
+
+func TestRankedPlayerReconnectingWithinGraceKeepsTheirPlayer(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.Ranked = true
+	cfg.DisconnectGrace = 5 * time.Second
+	// The kill below takes shots fired back to back
+	cfg.EnforceFireRate = false
+	gs, srv := newTestServerWithConfig(t, cfg)
+	sm := gs.stateManager
+
+	conn, playerId, token := dialReconnectableClient(t, srv, "")
+	_, victimId, _ := dialReconnectableClient(t, srv, "")
+	_, bystanderId, _ := dialReconnectableClient(t, srv, "")
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	sm.TeleportPlayer(playerId, types.Vector3{X: 0, Y: 0, Z: 0})
+	sm.TeleportPlayer(victimId, types.Vector3{X: 5, Y: 0, Z: 0})
+	sm.TeleportPlayer(bystanderId, types.Vector3{X: 0, Y: 0, Z: 5})
+	for i := 0; i < 4; i++ {
+		shot := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &types.Vector3{X: 5, Y: 1, Z: 0}}}
+		if err := sm.HandlePlayerAction(playerId, shot); err != nil {
+			t.Fatalf("Failed to shoot: %v", err)
+		}
+	}
+	before, _ := sm.GetPlayerStats(playerId)
+	if before.Kills != 1 {
+		t.Fatalf("Expected the player to have a kill before disconnecting, got %d", before.Kills)
+	}
+
+	conn.Close()
+	held := waitFor(t, func() bool { return isHeld(sm, playerId) })
+	if !held {
+		t.Fatal("Expected the disconnected player to be held in the game")
+	}
+
+	// Held players can't be hurt
+	shot := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &types.Vector3{X: 0, Y: 1, Z: 0}}}
+	if err := sm.HandlePlayerAction(bystanderId, shot); err != nil {
+		t.Fatalf("Failed to shoot: %v", err)
+	}
+
+	_, reconnectedId, _ := dialReconnectableClient(t, srv, token)
+	if reconnectedId != playerId {
+		t.Fatalf("Expected to reclaim player %s, got %s", playerId, reconnectedId)
+	}
+	after, ok := sm.GetPlayerStats(playerId)
+	if !ok || after.Kills != 1 || after.Health != 100 || !after.IsAlive {
+		t.Errorf("Expected the player to keep their kill and full health, got %+v", after)
+	}
+	if isHeld(sm, playerId) {
+		t.Error("Expected the player to no longer be disconnected")
+	}
+	if count := clientCount(gs); count != 3 {
+		t.Errorf("Expected 3 clients after reconnecting, got %d", count)
+	}
+}
+
+func TestPeakClientsIsHighWaterMark(t *testing.T) {
+	gs, srv := newTestServer(t)
+
+	var conns []*websocket.Conn
+	for i := 0; i < 3; i++ {
+		conn, _ := dialTestClient(t, srv)
+		conns = append(conns, conn)
+	}
+
+	conns[0].Close()
+	conns[1].Close()
+	if !waitFor(t, func() bool { return clientCount(gs) == 1 }) {
+		t.Fatalf("Expected 1 client after disconnects, got %d", clientCount(gs))
+	}
+
+	status := getJSON(t, srv.URL+"/api/status")
+	if status["peakClients"] != 3.0 {
+		t.Errorf("Expected peakClients 3 in /api/status, got %v", status["peakClients"])
+	}
+	if status["clients"] != 1.0 {
+		t.Errorf("Expected clients 1 in /api/status, got %v", status["clients"])
+	}
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to query /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	metrics, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(metrics), "finalcircle_clients_peak 3\n") {
+		t.Errorf("Expected peak of 3 in /metrics, got:\n%s", metrics)
+	}
+}
+
+func TestOversizedMessageIsReported(t *testing.T) {
+	gs, srv := newTestServer(t)
+	gs.config.MaxMessageSize = 1024
+	conn, playerId := dialTestClient(t, srv)
+
+	sendClientMessage(t, conn, "setName", map[string]interface{}{"displayName": strings.Repeat("x", 2048)}, time.Now())
+
+	errMsg := readMessage(t, conn, "error")
+	if code := errMsg["payload"].(map[string]interface{})["code"]; code != "messageTooLarge" {
+		t.Errorf("Expected messageTooLarge error, got %v", code)
+	}
+
+	// The connection is closed afterwards, and the player removed
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+				t.Errorf("Expected the connection to close with CloseMessageTooBig, got %v", err)
+			}
+			break
+		}
+	}
+	removed := waitFor(t, func() bool {
+		_, exists := gs.stateManager.GetPlayerPosition(playerId)
+		return !exists
+	})
+	if !removed {
+		t.Error("Expected the player to be removed after sending an oversized message")
+	}
+}
+
+func TestPlayerStatsEndpoint(t *testing.T) {
+	gs, srv := newTestServer(t)
+	_, playerId := dialTestClient(t, srv)
+	if err := gs.stateManager.UpdatePlayerName(playerId, "Profile"); err != nil {
+		t.Fatalf("Failed to set name: %v", err)
+	}
+
+	stats := getJSON(t, srv.URL+"/api/players/"+playerId)
+	if stats["id"] != playerId || stats["displayName"] != "Profile" {
+		t.Errorf("Expected stats of %s named Profile, got %v", playerId, stats)
+	}
+	if stats["health"] != 100.0 || stats["isAlive"] != true || stats["kd"] != 0.0 {
+		t.Errorf("Expected a fresh player's stats, got %v", stats)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/players/nobody-here")
+	if err != nil {
+		t.Fatalf("Failed to query unknown player: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown player, got %d", resp.StatusCode)
+	}
+}
+
+// postJSON posts a JSON body and returns the response
+func postJSON(t *testing.T, url string, body interface{}) *http.Response {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request body: %v", err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to POST %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestRoomsEnforceTheirOwnCapacity(t *testing.T) {
+	gs, _ := newTestServer(t)
+
+	duel, err := gs.createRoom(RoomConfig{ID: "duel", MaxPlayers: 2, TickRate: 10})
+	if err != nil {
+		t.Fatalf("Failed to create the duel room: %v", err)
+	}
+	royale, err := gs.createRoom(RoomConfig{ID: "royale", MaxPlayers: 4})
+	if err != nil {
+		t.Fatalf("Failed to create the royale room: %v", err)
+	}
+	if _, err := gs.createRoom(RoomConfig{ID: "duel"}); err != types.ErrRoomExists {
+		t.Errorf("Expected a duplicate room to be rejected, got %v", err)
+	}
+	if _, err := gs.createRoom(RoomConfig{ID: "crowded", MaxPlayers: config.MaxPlayersCeiling + 1}); err != types.ErrInvalidRoomConfig {
+		t.Errorf("Expected a room over the player ceiling to be rejected, got %v", err)
+	}
+
+	if duel.stateManager.MaxPlayers() != 2 || duel.stateManager.TickRate() != 10 {
+		t.Errorf("Expected duel room with 2 players at 10 ticks, got %d players at %d ticks",
+			duel.stateManager.MaxPlayers(), duel.stateManager.TickRate())
+	}
+	if royale.stateManager.MaxPlayers() != 4 || royale.stateManager.TickRate() != gs.settings.TickRate {
+		t.Errorf("Expected royale room with 4 players at the default tick rate, got %d players at %d ticks",
+			royale.stateManager.MaxPlayers(), royale.stateManager.TickRate())
+	}
+
+	// Each room enforces its own capacity
+	for _, id := range []string{"duelist-1", "duelist-2"} {
+		if err := duel.stateManager.AddPlayer(id); err != nil {
+			t.Errorf("Expected the duel room to accept %s, got %v", id, err)
+		}
+	}
+	if err := duel.stateManager.AddPlayer("duelist-3"); err != types.ErrServerFull {
+		t.Errorf("Expected the full duel room to reject a third player, got %v", err)
+	}
+	for _, id := range []string{"royal-1", "royal-2", "royal-3"} {
+		if err := royale.stateManager.AddPlayer(id); err != nil {
+			t.Errorf("Expected the royale room to accept %s, got %v", id, err)
+		}
+	}
+	if players := len(gs.stateManager.GetState().Players); players != 0 {
+		t.Errorf("Expected the default room to stay empty, got %d players", players)
+	}
+}
+
+func TestSlowClientPolicies(t *testing.T) {
+	for _, policy := range []string{config.SlowClientDisconnect, config.SlowClientDrop} {
+		t.Run(policy, func(t *testing.T) {
+			gs, srv := newTestServer(t)
+			gs.config.SlowClientPolicy = policy
+			client := newStalledClient(t, gs, "stalled-player")
+
+			// Fill the send buffer so the next broadcast can't be queued
+			for len(client.Send) < cap(client.Send) {
+				client.Send <- []byte(`{"type":"gameState"}`)
+			}
+			gs.broadcastGameState(gs.defaultRoom)
+
+			status := getJSON(t, srv.URL+"/api/status")
+			_, stillPlayer := gs.stateManager.GetPlayerPosition(client.ID)
+			switch policy {
+			case config.SlowClientDisconnect:
+				if stillPlayer || clientCount(gs) != 0 {
+					t.Error("Expected the stalled client to be disconnected")
+				}
+				if status["slowClientDisconnects"] != 1.0 || status["droppedFrames"] != 0.0 {
+					t.Errorf("Expected 1 disconnect and no dropped frames, got %v", status)
+				}
+			case config.SlowClientDrop:
+				if !stillPlayer || clientCount(gs) != 1 {
+					t.Error("Expected the stalled client to be kept")
+				}
+				if status["slowClientDisconnects"] != 0.0 || status["droppedFrames"] != 1.0 {
+					t.Errorf("Expected 1 dropped frame and no disconnects, got %v", status)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusReportsModeMapAndZone(t *testing.T) {
+	gs, srv := newTestServer(t)
+	dialTestClient(t, srv)
+	dialTestClient(t, srv)
+
+	if resp := postJSON(t, srv.URL+"/api/game/start", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to start game: %d", resp.StatusCode)
+	}
+	// Move past the first zone phase, in steps so the zone doesn't eliminate anyone in one go
+	for i := 0; i < 91; i++ {
+		gs.stateManager.UpdateWithDelta(1)
+	}
+
+	status := getJSON(t, srv.URL+"/api/status")
+	if status["gameMode"] != "elimination" || status["map"] != "default" {
+		t.Errorf("Expected the default mode and map, got %v and %v", status["gameMode"], status["map"])
+	}
+	if status["phase"] != 1.0 {
+		t.Errorf("Expected zone phase 1, got %v", status["phase"])
+	}
+	zone, ok := status["zone"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a zone object, got %v", status["zone"])
+	}
+	if radius := zone["radius"].(float64); radius <= 0 || radius >= gs.stateManager.WorldRadius() {
+		t.Errorf("Expected the zone to have shrunk inside the world, got radius %v", radius)
+	}
+	if _, ok := zone["center"].(map[string]interface{}); !ok {
+		t.Errorf("Expected a zone center, got %v", zone["center"])
+	}
+
+	// Existing fields are still there
+	if status["gameActive"] != true || status["matchId"] == "" {
+		t.Errorf("Expected the existing fields to be reported, got %v", status)
+	}
+}
+
+func TestHandshakeNegotiatesProtocolVersion(t *testing.T) {
+	gs, srv := newTestServer(t)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", wsURL, err)
+	}
+	defer conn.Close()
+
+	handshake := readMessage(t, conn, "playerId")["payload"].(map[string]interface{})
+	if handshake["protocolVersion"] != float64(types.ProtocolVersion) || handshake["features"] == nil {
+		t.Fatalf("Expected the handshake to report protocol version %d and features, got %v", types.ProtocolVersion, handshake)
+	}
+
+	// A client speaking the current version keeps playing
+	sendClientMessage(t, conn, "hello", map[string]interface{}{"protocolVersion": types.ProtocolVersion}, time.Now())
+	sendClientMessage(t, conn, "setName", map[string]interface{}{"displayName": "Current"}, time.Now())
+	named := waitFor(t, func() bool {
+		stats, ok := gs.stateManager.GetPlayerStats(handshake["id"].(string))
+		return ok && stats.DisplayName == "Current"
+	})
+	if !named {
+		t.Error("Expected a client speaking the current version to keep playing")
+	}
+
+	// An outdated client is told why and disconnected
+	old, _ := dialTestClient(t, srv)
+	sendClientMessage(t, old, "hello", map[string]interface{}{"protocolVersion": types.MinProtocolVersion - 1}, time.Now())
+
+	errMsg := readMessage(t, old, "error")
+	if code := errMsg["payload"].(map[string]interface{})["code"]; code != "unsupportedVersion" {
+		t.Errorf("Expected unsupportedVersion error, got %v", code)
+	}
+	old.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := old.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+				t.Errorf("Expected the connection to close with ClosePolicyViolation, got %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestBandwidthIsAccountedPerClient(t *testing.T) {
+	gs, srv := newTestServer(t)
+	conn, playerId := dialTestClient(t, srv)
+
+	gs.clientsMu.RLock()
+	client := gs.clients[playerId]
+	gs.clientsMu.RUnlock()
+
+	// Wait for the handshake and initial state to be written before measuring
+	waitFor(t, func() bool { return len(client.Send) == 0 })
+	before := client.stats.bytesSent.Load()
+
+	frame := []byte(`{"type":"ping","payload":{}}`)
+	for i := 0; i < 5; i++ {
+		client.Send <- frame
+		readMessage(t, conn, "ping")
+	}
+	grown := waitFor(t, func() bool { return client.stats.bytesSent.Load() >= before+int64(5*len(frame)) })
+	if !grown {
+		t.Errorf("Expected bytes sent to grow by at least %d, got %d -> %d", 5*len(frame), before, client.stats.bytesSent.Load())
+	}
+
+	sendClientMessage(t, conn, "setName", map[string]interface{}{"displayName": "Counted"}, time.Now())
+	waitFor(t, func() bool { return client.stats.messagesReceived.Load() == 1 })
+
+	resp, err := http.Get(srv.URL + "/api/admin/clients")
+	if err != nil {
+		t.Fatalf("Failed to query client bandwidth: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var usage []ClientBandwidth
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		t.Fatalf("Failed to decode client bandwidth: %v", err)
+	}
+	if len(usage) != 1 || usage[0].ID != playerId || usage[0].MessagesReceived != 1 || usage[0].BytesSent < before {
+		t.Errorf("Expected the client's traffic to be listed, got %+v", usage)
+	}
+
+	// Client IDs and addresses are only for admins once a token is set
+	gs.config.AdminToken = "secret"
+	unauthorized, err := http.Get(srv.URL + "/api/admin/clients")
+	if err != nil {
+		t.Fatalf("Failed to query client bandwidth: %v", err)
+	}
+	unauthorized.Body.Close()
+	if unauthorized.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the client list to require the admin token, got %d", unauthorized.StatusCode)
+	}
+}
+
+// messagesUntil reads message types from a connection up to and including the first message of the given type
+func messagesUntil(t *testing.T, conn *websocket.Conn, msgType string) []string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var seen []string
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed waiting for %q message: %v", msgType, err)
+		}
+		for _, raw := range bytes.Split(data, []byte("\n")) {
+			var msg struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			seen = append(seen, msg.Type)
+			if msg.Type == msgType {
+				return seen
+			}
+		}
+	}
+}
+
+func TestMOTDIsSentOnConnect(t *testing.T) {
+	gs, srv := newTestServer(t)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	for _, motd := range []string{"Welcome to the final circle", ""} {
+		gs.config.MOTD = motd
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatalf("Failed to connect to %s: %v", wsURL, err)
+		}
+		seen := messagesUntil(t, conn, "gameState")
+		conn.Close()
+
+		want := []string{"playerId", "gameState"}
+		if motd != "" {
+			want = []string{"playerId", "motd", "gameState"}
+		}
+		if strings.Join(seen, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected messages %v with MOTD %q, got %v", want, motd, seen)
+		}
+	}
+}
+
+func TestMessageRatesAreReported(t *testing.T) {
+	gs, srv := newTestServer(t)
+
+	// Pin the inbound counter to a clock the test moves by hand
+	var clock atomic.Int64
+	clock.Store(time.Unix(1000, 0).UnixNano())
+	gs.inboundMessages.now = func() time.Time { return time.Unix(0, clock.Load()) }
+
+	conn, playerId := dialTestClient(t, srv)
+	gs.clientsMu.RLock()
+	client := gs.clients[playerId]
+	gs.clientsMu.RUnlock()
+
+	// 30 messages in each of two seconds average to 6 per second over the 10 second window
+	for second := 0; second < 2; second++ {
+		for i := 0; i < 30; i++ {
+			sendClientMessage(t, conn, "ping", map[string]interface{}{}, time.Now())
+		}
+		want := int64(30 * (second + 1))
+		if !waitFor(t, func() bool { return client.stats.messagesReceived.Load() == want }) {
+			t.Fatalf("Expected %d messages to be handled, got %d", want, client.stats.messagesReceived.Load())
+		}
+		clock.Add(int64(time.Second))
+	}
+
+	status := getJSON(t, srv.URL+"/api/status")
+	if rate := status["messagesInPerSecond"].(float64); rate < 5 || rate > 7 {
+		t.Errorf("Expected about 6 messages per second, got %v", rate)
+	}
+	if rate := status["framesOutPerSecond"].(float64); rate < 0 {
+		t.Errorf("Expected a frame rate, got %v", rate)
+	}
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to fetch metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "finalcircle_messages_in_per_second 6") {
+		t.Errorf("Expected the inbound rate in /metrics, got:\n%s", body)
+	}
+}
+
+func TestDuplicatePlayerIDIsReported(t *testing.T) {
+	gs, srv := newTestServer(t)
+	gs.newPlayerID = func() string { return "duplicate-player" }
+
+	first, playerId := dialTestClient(t, srv)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	second, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", wsURL, err)
+	}
+	defer second.Close()
+
+	errMsg := readMessage(t, second, "error")
+	if code := errMsg["payload"].(map[string]interface{})["code"]; code != "playerIdTaken" {
+		t.Errorf("Expected playerIdTaken error, got %v", code)
+	}
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := second.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("Expected the connection to close with ClosePolicyViolation, got %v", err)
+	}
+
+	// The player already holding the ID keeps playing
+	if _, exists := gs.stateManager.GetPlayerPosition(playerId); !exists || clientCount(gs) != 1 {
+		t.Errorf("Expected the first connection to be unaffected, player exists: %v, clients: %d", exists, clientCount(gs))
+	}
+	sendClientMessage(t, first, "setName", map[string]interface{}{"displayName": "Original"}, time.Now())
+	if !waitFor(t, func() bool {
+		stats, _ := gs.stateManager.GetPlayerStats(playerId)
+		return stats.DisplayName == "Original"
+	}) {
+		t.Error("Expected the first connection to still be served")
+	}
+}
+
+// adminPost sends an admin request with the given bearer token, none when empty
+func adminPost(t *testing.T, url, token string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to POST %s: %v", url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAdminPlayerEndpointsRequireToken(t *testing.T) {
+	gs, srv := newTestServer(t)
+	gs.config.AdminToken = "secret"
+	if err := gs.stateManager.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	for _, tc := range []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/api/admin/player/player1/heal", "", http.StatusUnauthorized},
+		{"/api/admin/player/player1/heal", "wrong", http.StatusUnauthorized},
+		{"/api/admin/player/player1/heal", "secret", http.StatusOK},
+		{"/api/admin/player/player1/respawn", "secret", http.StatusOK},
+		{"/api/admin/player/nobody/respawn", "secret", http.StatusNotFound},
+	} {
+		if resp := adminPost(t, srv.URL+tc.path, tc.token); resp.StatusCode != tc.status {
+			t.Errorf("POST %s with token %q: expected %d, got %d", tc.path, tc.token, tc.status, resp.StatusCode)
+		}
+	}
+}
+
+func TestPprofIsOnlyMountedWhenEnabled(t *testing.T) {
+	_, srv := newTestServer(t)
+	resp, err := http.Get(srv.URL + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("Failed to query pprof: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected pprof to be absent by default, got %d", resp.StatusCode)
+	}
+
+	cfg := config.LoadConfig()
+	cfg.EnablePprof = true
+	cfg.AdminToken = "secret"
+	_, srv = newTestServerWithConfig(t, cfg)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/debug/pprof/", nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	for _, tc := range []struct {
+		token  string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"secret", http.StatusOK},
+	} {
+		req.Header.Del("Authorization")
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to query pprof: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("Expected pprof with token %q to return %d, got %d", tc.token, tc.status, resp.StatusCode)
+		}
+	}
+}
+
+func TestRoomsStatusReportsEveryRoom(t *testing.T) {
+	gs, srv := newTestServer(t)
+
+	for room, players := range map[string]int{"alpha": 2, "bravo": 3} {
+		created, err := gs.createRoom(RoomConfig{ID: room})
+		if err != nil {
+			t.Fatalf("Failed to create room %s: %v", room, err)
+		}
+		for i := 0; i < players; i++ {
+			if err := created.stateManager.AddPlayer(fmt.Sprintf("%s-player%d", room, i)); err != nil {
+				t.Fatalf("Failed to add player to %s: %v", room, err)
+			}
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/api/rooms/status")
+	if err != nil {
+		t.Fatalf("Failed to query rooms status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status RoomsStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode rooms status: %v", err)
+	}
+
+	rooms := make(map[string]RoomStatus)
+	for _, room := range status.Rooms {
+		rooms[room.ID] = room
+	}
+	if alpha := rooms["alpha"]; alpha.Players != 2 || alpha.PeakPlayers != 2 {
+		t.Errorf("Expected alpha with 2 players, got %+v", alpha)
+	}
+	if bravo := rooms["bravo"]; bravo.Players != 3 || bravo.PeakPlayers != 3 {
+		t.Errorf("Expected bravo with 3 players, got %+v", bravo)
+	}
+	if status.TotalRooms != 3 || status.TotalPlayers != 5 {
+		t.Errorf("Expected 3 rooms with 5 players in total, got %d rooms with %d players", status.TotalRooms, status.TotalPlayers)
+	}
+}
+
+func TestStartWithTooFewPlayersExplainsWhy(t *testing.T) {
+	gs, srv := newTestServer(t)
+	if err := gs.stateManager.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	resp := postJSON(t, srv.URL+"/api/game/start", nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected 409 when starting with one player, got %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON error, got Content-Type %q", contentType)
+	}
+	var failed struct {
+		Code    string         `json:"code"`
+		Message string         `json:"message"`
+		Details map[string]int `json:"details"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&failed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if failed.Code != "notEnoughPlayers" || failed.Message == "" {
+		t.Errorf("Expected a notEnoughPlayers error with a message, got %+v", failed)
+	}
+	if failed.Details["current"] != 1 || failed.Details["required"] != 2 {
+		t.Errorf("Expected details of 1 of 2 players, got %+v", failed.Details)
+	}
+}
+
+func TestAPIErrorsUseTheJSONEnvelope(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	resp := postJSON(t, srv.URL+"/api/admin/player/nobody/heal", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected 404 for an unknown player, got %d", resp.StatusCode)
+	}
+	var failed types.ErrorMessage
+	if err := json.NewDecoder(resp.Body).Decode(&failed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if failed.Code != "playerNotFound" || failed.Message != types.ErrPlayerNotFound.Error() {
+		t.Errorf("Expected a playerNotFound error, got %+v", failed)
+	}
+}
+
+func TestBroadcastThrottleWidensWhenTicksRunLong(t *testing.T) {
+	throttle := newBroadcastThrottle("slow-room", 60, 10)
+	tick := time.Second / 60
+	if throttle.interval() != tick {
+		t.Fatalf("expected to start broadcasting every tick, got every %v", throttle.interval())
+	}
+
+	// A slow Update takes longer than the tick interval, so broadcasts have to thin out
+	for i := 0; i < 10; i++ {
+		throttle.record(3 * tick)
+	}
+	if throttle.interval() <= tick {
+		t.Fatalf("expected the broadcast interval to widen, still every %v", throttle.interval())
+	}
+	if throttle.interval() > time.Second/10 {
+		t.Fatalf("expected the broadcast rate to stay above the 10/s floor, got every %v", throttle.interval())
+	}
+
+	broadcasts := 0
+	for i := 0; i < 60; i++ {
+		if throttle.due() {
+			broadcasts++
+		}
+	}
+	if broadcasts != 10 {
+		t.Fatalf("expected 10 broadcasts in a second of ticks at the floor, got %d", broadcasts)
+	}
+
+	// Once the room keeps up again the rate recovers back to every tick
+	for i := 0; i < 60*throttleRecoverySeconds*5; i++ {
+		throttle.record(tick / 10)
+	}
+	if throttle.interval() != tick {
+		t.Fatalf("expected broadcasts every tick after recovering, got every %v", throttle.interval())
+	}
+}
+
+func TestWhoAmIReturnsTheClientsIdentity(t *testing.T) {
+	_, srv := newTestServer(t)
+	conn, playerId := dialTestClient(t, srv)
+
+	sendClientMessage(t, conn, "setName", map[string]interface{}{"displayName": "Lost"}, time.Now())
+	sendClientMessage(t, conn, "whoAmI", map[string]interface{}{}, time.Now())
+
+	payload := readMessage(t, conn, "whoAmI")["payload"].(map[string]interface{})
+	if payload["playerId"] != playerId {
+		t.Errorf("Expected playerId %s, got %v", playerId, payload["playerId"])
+	}
+	if payload["displayName"] != "Lost" {
+		t.Errorf("Expected displayName Lost, got %v", payload["displayName"])
+	}
+	if payload["isAlive"] != true {
+		t.Errorf("Expected a freshly connected player to be alive, got %v", payload["isAlive"])
+	}
+}
+
+// signTestToken creates an HS256 JWT for subject expiring at exp
+func signTestToken(t *testing.T, secret, subject string, exp time.Time) string {
+	t.Helper()
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{"sub": subject, "exp": exp.Unix()})
+	if err != nil {
+		t.Fatalf("Failed to encode claims: %v", err)
+	}
+	signed := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticatedPlayersUseTheirAccountID(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.JWTSecret = "test-secret"
+	_, srv := newTestServerWithConfig(t, cfg)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	// A valid token connects and plays under its subject
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+signTestToken(t, "test-secret", "account-42", time.Now().Add(time.Hour)))
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("Expected a valid token to connect, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if id := readMessage(t, conn, "playerId")["payload"].(map[string]interface{})["id"]; id != "account-42" {
+		t.Errorf("Expected the player ID to be the token subject, got %v", id)
+	}
+
+	// The token may also be passed as a query parameter
+	queryToken := signTestToken(t, "test-secret", "account-43", time.Now().Add(time.Hour))
+	queryConn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+queryToken, nil)
+	if err != nil {
+		t.Fatalf("Expected a valid query token to connect, got %v", err)
+	}
+	t.Cleanup(func() { queryConn.Close() })
+
+	// An expired token, a token signed with another secret and no token at all are refused
+	rejected := map[string]string{
+		"expired":      signTestToken(t, "test-secret", "account-44", time.Now().Add(-time.Minute)),
+		"wrong secret": signTestToken(t, "other-secret", "account-45", time.Now().Add(time.Hour)),
+		"missing":      "",
+	}
+	for name, token := range rejected {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token="+token, nil)
+		if err == nil {
+			t.Errorf("Expected a %s token to be refused", name)
+			continue
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected a %s token to get 401, got %v", name, resp)
+		}
+	}
+}
+
+func TestAnonymousPlayWhenAuthIsDisabled(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.JWTSecret = ""
+	cfg.JWTPublicKeyFile = ""
+	_, srv := newTestServerWithConfig(t, cfg)
+
+	// No token is needed and the player gets a generated ID
+	_, playerId := dialTestClient(t, srv)
+	if len(playerId) < 5 {
+		t.Errorf("Expected an anonymous player to get a generated ID, got %q", playerId)
+	}
+}
+
+func TestDrainRefusesNewPlayersButServesExistingOnes(t *testing.T) {
+	gs, srv := newTestServer(t)
+	conn, _ := dialTestClient(t, srv)
+
+	resp := adminPost(t, srv.URL+"/api/admin/drain", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected drain to succeed, got %d", resp.StatusCode)
+	}
+
+	// New upgrades are refused with the reason
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	_, refused, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("Expected a new connection to be refused while draining")
+	}
+	if refused == nil || refused.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 while draining, got %v", refused)
+	}
+	body, _ := io.ReadAll(refused.Body)
+	if !strings.Contains(string(body), "draining") {
+		t.Errorf("Expected the refusal to explain the server is draining, got %q", body)
+	}
+
+	// /readyz reports not ready
+	ready, err := http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("Failed to query /readyz: %v", err)
+	}
+	ready.Body.Close()
+	if ready.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected /readyz to report not ready while draining, got %d", ready.StatusCode)
+	}
+
+	// The existing client still gets state
+	gs.broadcastGameState(gs.defaultRoom)
+	readMessage(t, conn, "gameState")
+}
+
+func TestDebugStateDumpsInternalPlayerFields(t *testing.T) {
+	gs, srv := newTestServer(t)
+	gs.config.AdminToken = "secret"
+	_, playerId := dialTestClient(t, srv)
+
+	if err := gs.stateManager.UpdatePlayerName(playerId, "Suspect"); err != nil {
+		t.Fatalf("Failed to name player: %v", err)
+	}
+	if err := gs.stateManager.MutePlayer(playerId, time.Minute); err != nil {
+		t.Fatalf("Failed to mute player: %v", err)
+	}
+
+	// The dump is admin only
+	resp, err := http.Get(srv.URL + "/api/debug/state")
+	if err != nil {
+		t.Fatalf("Failed to GET debug state: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the admin token, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/debug/state", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to GET debug state: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var dump map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&dump); err != nil {
+		t.Fatalf("Failed to decode debug state: %v", err)
+	}
+	players := dump["state"].(map[string]interface{})["players"].(map[string]interface{})
+	player, ok := players[playerId].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected the dump to include player %s, got %v", playerId, players)
+	}
+
+	// Fields the broadcast leaves out are in the dump
+	if player["displayName"] != "Suspect" {
+		t.Errorf("Expected displayName Suspect, got %v", player["displayName"])
+	}
+	if player["muted"] != true || player["mutedFor"] != float64(60) {
+		t.Errorf("Expected the player muted for 60s, got muted=%v mutedFor=%v", player["muted"], player["mutedFor"])
+	}
+	if _, ok := player["spread"]; !ok {
+		t.Error("Expected the dump to include the player's spread")
+	}
+	if _, ok := dump["nextSupplyDrop"]; !ok {
+		t.Error("Expected the dump to include the state manager's internal timers")
+	}
+}
+
+func TestEmptyRoomTicksSlowlyUntilAPlayerJoins(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.TickRate = 20
+	cfg.IdleTickRate = 2
+	gs, srv := newTestServerWithConfig(t, cfg)
+	go gs.run()
+
+	// With nobody connected and no match the loop slows down to the idle rate
+	idle := waitFor(t, func() bool { return gs.defaultRoom.tickInterval() == 500*time.Millisecond })
+	if !idle {
+		t.Fatalf("Expected the empty room to tick every 500ms, got every %v", gs.defaultRoom.tickInterval())
+	}
+
+	// A connecting player brings it back to the full rate right away
+	dialTestClient(t, srv)
+	active := waitFor(t, func() bool { return gs.defaultRoom.tickInterval() == 50*time.Millisecond })
+	if !active {
+		t.Errorf("Expected the room to tick every 50ms once a player joined, got every %v", gs.defaultRoom.tickInterval())
+	}
+}
+
+func TestRoomLimitAndEmptyRoomsAreReaped(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.MaxRooms = 2
+	cfg.EmptyRoomTTL = 100 * time.Millisecond
+	cfg.IdleTickRate = 0
+	gs, _ := newTestServerWithConfig(t, cfg)
+
+	// The default room and one created room fill the limit
+	if _, err := gs.createRoom(RoomConfig{ID: "first"}); err != nil {
+		t.Fatalf("Expected the first room to be created, got %v", err)
+	}
+	if _, err := gs.createRoom(RoomConfig{ID: "second"}); err != types.ErrTooManyRooms {
+		t.Fatalf("Expected room creation past the limit to be refused, got %v", err)
+	}
+
+	// The empty room is removed after its TTL, which makes room for another one
+	reaped := waitFor(t, func() bool {
+		_, exists := gs.getRoom("first")
+		return !exists
+	})
+	if !reaped {
+		t.Fatal("Expected the empty room to be reaped")
+	}
+	if _, exists := gs.getRoom(defaultRoomID); !exists {
+		t.Error("Expected the default room to be kept even though it is empty")
+	}
+	if _, err := gs.createRoom(RoomConfig{ID: "second"}); err != nil {
+		t.Errorf("Expected a room to be created once the empty one was reaped, got %v", err)
+	}
+}
+
+func TestRoomIsOnlyReapedWhileEmpty(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.EmptyRoomTTL = 0
+	gs, _ := newTestServerWithConfig(t, cfg)
+
+	room, err := gs.createRoom(RoomConfig{ID: "busy"})
+	if err != nil {
+		t.Fatalf("Failed to create room: %v", err)
+	}
+	if err := room.stateManager.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	// A player who joined before the check keeps the room
+	if gs.removeRoomIfEmpty(room) || !gs.roomRegistered(room) {
+		t.Fatal("Expected a room with a player in it to be kept")
+	}
+
+	// Once removed, a join still holding the room finds it gone
+	room.stateManager.RemovePlayer("player1")
+	if !gs.removeRoomIfEmpty(room) {
+		t.Fatal("Expected the empty room to be removed")
+	}
+	if gs.roomRegistered(room) {
+		t.Error("Expected the removed room to refuse joins")
+	}
+	if _, err := gs.createRoom(RoomConfig{ID: "busy"}); err != nil {
+		t.Fatalf("Failed to recreate room: %v", err)
+	}
+	if gs.roomRegistered(room) {
+		t.Error("Expected the removed room to stay closed after a new room took its ID")
+	}
+}
+
+// countMessages reads messages of the given type until the connection goes quiet, returning how many arrived.
+// The connection can't be read from afterwards.
+func countMessages(t *testing.T, conn *websocket.Conn, msgType string) int {
+	t.Helper()
+
+	count := 0
+	for {
+		conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return count
+		}
+		for _, raw := range bytes.Split(data, []byte("\n")) {
+			var msg map[string]interface{}
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				t.Fatalf("Failed to unmarshal message: %v", err)
+			}
+			if msg["type"] == msgType {
+				count++
+			}
+		}
+	}
+}
+
+func TestClientAskingForSlowerStatesGetsFewerFramesWithoutBeingDropped(t *testing.T) {
+	gs, srv := newTestServer(t)
+	slowConn, slowId := dialTestClient(t, srv)
+	fastConn, _ := dialTestClient(t, srv)
+
+	sendClientMessage(t, slowConn, "hello", map[string]interface{}{
+		"protocolVersion":    types.ProtocolVersion,
+		"minStateIntervalMs": 200,
+	}, time.Now())
+	asked := waitFor(t, func() bool {
+		gs.clientsMu.RLock()
+		defer gs.clientsMu.RUnlock()
+		return gs.clients[slowId].stateInterval.Load() == int64(200*time.Millisecond)
+	})
+	if !asked {
+		t.Fatal("Expected the client's state interval to be applied")
+	}
+
+	// Twenty broadcasts over half a second
+	for i := 0; i < 20; i++ {
+		gs.broadcastGameState(gs.defaultRoom)
+		time.Sleep(25 * time.Millisecond)
+	}
+
+	slow, fast := countMessages(t, slowConn, "gameState"), countMessages(t, fastConn, "gameState")
+	if fast < 20 {
+		t.Errorf("Expected the default client to get every state, got %d", fast)
+	}
+	if slow < 2 || slow > 6 {
+		t.Errorf("Expected the slow client to get a state about every 200ms, got %d of %d", slow, fast)
+	}
+
+	gs.clientsMu.RLock()
+	_, connected := gs.clients[slowId]
+	gs.clientsMu.RUnlock()
+	if !connected {
+		t.Error("Expected the slow client to stay connected")
+	}
+}
+
+func TestClientOptingIntoCompactStatesReceivesThem(t *testing.T) {
+	gs, srv := newTestServer(t)
+	compactConn, compactId := dialTestClient(t, srv)
+	verboseConn, _ := dialTestClient(t, srv)
+
+	sendClientMessage(t, compactConn, "hello", map[string]interface{}{
+		"protocolVersion": types.ProtocolVersion,
+		"features":        []string{types.FeatureCompact},
+	}, time.Now())
+	opted := waitFor(t, func() bool {
+		gs.clientsMu.RLock()
+		defer gs.clientsMu.RUnlock()
+		return gs.clients[compactId].compact.Load()
+	})
+	if !opted {
+		t.Fatal("Expected the client to opt into compact states")
+	}
+
+	gs.broadcastGameState(gs.defaultRoom)
+
+	payload, _ := json.Marshal(readMessage(t, compactConn, "gameState")["payload"])
+	var state types.CompactGameState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		t.Fatalf("Failed to decode compact state: %v", err)
+	}
+	player, ok := state.Players[compactId]
+	if !ok || player.Health != 100 || !player.IsAlive {
+		t.Errorf("Expected the compact state to carry the client's player, got %+v", state.Players)
+	}
+
+	// Clients that didn't opt in keep getting the verbose state
+	verbose := readMessage(t, verboseConn, "gameState")["payload"].(map[string]interface{})
+	if _, ok := verbose["players"]; !ok {
+		t.Errorf("Expected the verbose state for a client that didn't opt in, got keys %v", verbose)
+	}
+}
+
+func TestMatchTimelineListsStartAndKillInOrder(t *testing.T) {
+	gs, srv := newTestServer(t)
+	sm := gs.stateManager
+	for _, id := range []string{"killer", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	matchID := sm.Summary().MatchID
+
+	for id, position := range map[string]types.Vector3{"killer": {}, "victim": {X: 10}} {
+		if _, err := sm.TeleportPlayer(id, position); err != nil {
+			t.Fatalf("Failed to place %s: %v", id, err)
+		}
+	}
+	target := types.Vector3{X: 10, Y: 1.2}
+	shot := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{WeaponID: "SNIPER", Target: &target}}
+	if err := sm.HandlePlayerAction("killer", shot); err != nil {
+		t.Fatalf("Failed to shoot: %v", err)
+	}
+	sm.EndGame()
+
+	timeline := getJSON(t, srv.URL+"/api/matches/"+matchID+"/timeline")
+	events, _ := timeline["events"].([]interface{})
+	var kinds []string
+	for _, event := range events {
+		kinds = append(kinds, event.(map[string]interface{})["type"].(string))
+	}
+	if len(kinds) < 3 || kinds[0] != "matchStart" || kinds[1] != "kill" || kinds[len(kinds)-1] != "matchEnd" {
+		t.Fatalf("Expected matchStart, kill, ..., matchEnd, got %v", kinds)
+	}
+	kill := events[1].(map[string]interface{})["details"].(map[string]interface{})
+	if kill["killerId"] != "killer" || kill["victimId"] != "victim" {
+		t.Errorf("Expected the kill of victim by killer, got %v", kill)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/matches/unknown/timeline")
+	if err != nil {
+		t.Fatalf("Failed to GET timeline: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown match, got %d", resp.StatusCode)
+	}
+}
+
+func TestPongRecordsTheClientsLatency(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.PingInterval = 50 * time.Millisecond
+	_, srv := newTestServerWithConfig(t, cfg)
+	conn, playerId := dialTestClient(t, srv)
+
+	// Answer pings late, so the measured round trip time has a known lower bound
+	const pongDelay = 40 * time.Millisecond
+	conn.SetPingHandler(func(appData string) error {
+		time.Sleep(pongDelay)
+		return conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(time.Second))
+	})
+	sendClientMessage(t, conn, "hello", map[string]interface{}{
+		"protocolVersion": types.ProtocolVersion,
+		"features":        []string{types.FeatureLatency},
+	}, time.Now())
+
+	latency := readMessage(t, conn, "latency")["payload"].(map[string]interface{})
+	if rtt, _ := latency["rttMs"].(float64); rtt < float64(pongDelay.Milliseconds()) {
+		t.Errorf("Expected a reported latency of at least %v, got %v ms", pongDelay, latency["rttMs"])
+	}
+
+	status := getJSON(t, srv.URL+"/api/status")
+	latencies, _ := status["latencyMs"].(map[string]interface{})
+	if rtt, _ := latencies[playerId].(float64); rtt < float64(pongDelay.Milliseconds()) {
+		t.Errorf("Expected /api/status to list the client's latency of at least %v, got %v", pongDelay, latencies)
+	}
+}
+
+func TestReloadPicksUpNewBansAndKicksBannedClients(t *testing.T) {
+	dir := t.TempDir()
+	wordsFile := filepath.Join(dir, "banned-words.txt")
+	ipsFile := filepath.Join(dir, "banned-ips.txt")
+	if err := os.WriteFile(wordsFile, []byte("# one per line\ndarn\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write banned words: %v", err)
+	}
+	if err := os.WriteFile(ipsFile, []byte("203.0.113.7\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write banned IPs: %v", err)
+	}
+
+	cfg := config.LoadConfig()
+	cfg.BannedWordsFile = wordsFile
+	cfg.BannedIPsFile = ipsFile
+	_, srv := newTestServerWithConfig(t, cfg)
+	conn, _ := dialTestClient(t, srv)
+
+	// Ban the address the test client connects from
+	if err := os.WriteFile(ipsFile, []byte("203.0.113.7\n127.0.0.1\n"), 0o644); err != nil {
+		t.Fatalf("Failed to update banned IPs: %v", err)
+	}
+	resp := adminPost(t, srv.URL+"/api/admin/reload?kick=true", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected reload to succeed, got %d", resp.StatusCode)
+	}
+	var result ReloadResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode reload result: %v", err)
+	}
+	if result != (ReloadResult{BannedWords: 1, BannedIPs: 2, Kicked: 1}) {
+		t.Errorf("Expected 1 word, 2 IPs and 1 kick, got %+v", result)
+	}
+
+	kicked := readMessage(t, conn, "error")["payload"].(map[string]interface{})
+	if kicked["code"] != "banned" {
+		t.Errorf("Expected the kicked client to be told it's banned, got %v", kicked)
+	}
+
+	// The new ban applies to new connections too
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	_, refused, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("Expected a connection from a banned address to be refused")
+	}
+	if refused == nil || refused.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for a banned address, got %v", refused)
+	}
+}
+
+func TestShotsFasterThanTheFireRateAreRateLimited(t *testing.T) {
+	gs, srv := newTestServer(t)
+	sm := gs.stateManager
+	conn, playerId := dialTestClient(t, srv)
+	_, bystanderId := dialTestClient(t, srv)
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	// Keep the bystander out of the line of fire, killing them would end the match
+	sm.TeleportPlayer(playerId, types.Vector3{X: 0, Y: 0, Z: 0})
+	sm.TeleportPlayer(bystanderId, types.Vector3{X: 0, Y: 0, Z: 50})
+
+	// The sniper fires once a second, the second shot comes far too soon
+	for i := 0; i < 2; i++ {
+		sendClientMessage(t, conn, "playerAction", map[string]interface{}{
+			"type": "shoot",
+			"data": map[string]interface{}{
+				"weaponId":  "SNIPER",
+				"direction": map[string]interface{}{"x": 1.0, "y": 0.0, "z": 0.0},
+			},
+		}, time.Now())
+	}
+
+	errMsg := readMessage(t, conn, "error")
+	if code := errMsg["payload"].(map[string]interface{})["code"]; code != "RATE_LIMITED" {
+		t.Errorf("Expected a RATE_LIMITED error, got %v", code)
+	}
+	player := sm.GetState().Players[playerId]
+	if ammo := player.Ammo["SNIPER"]; ammo != 4 {
+		t.Errorf("Expected only the first shot to use a round, %d left in the magazine", ammo)
+	}
+}
+
+func TestRoomsAreCreatedListedAndJoinedOverHTTP(t *testing.T) {
+	cfg := config.LoadConfig()
+	cfg.MaxRooms = 2
+	gs, srv := newTestServerWithConfig(t, cfg)
+
+	resp := postJSON(t, srv.URL+"/api/rooms", RoomConfig{ID: "duel", MaxPlayers: 2, TickRate: 10})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the duel room to be created, got %d", resp.StatusCode)
+	}
+	var created RoomInfo
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode the created room: %v", err)
+	}
+	if created.ID != "duel" || created.MaxPlayers != 2 || created.TickRate != 10 {
+		t.Errorf("Expected the created room's id and settings, got %+v", created)
+	}
+	if resp := postJSON(t, srv.URL+"/api/rooms", RoomConfig{ID: "duel"}); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected a duplicate room to be rejected with 409, got %d", resp.StatusCode)
+	}
+	if resp := postJSON(t, srv.URL+"/api/rooms", RoomConfig{ID: "royale"}); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected room creation past the limit to be refused with 503, got %d", resp.StatusCode)
+	}
+
+	// Clients joining a room play in that room only
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?room=duel", nil)
+	if err != nil {
+		t.Fatalf("Failed to join the duel room: %v", err)
+	}
+	defer conn.Close()
+	readMessage(t, conn, "playerId")
+	duel, _ := gs.getRoom("duel")
+	if players := len(duel.stateManager.GetState().Players); players != 1 {
+		t.Errorf("Expected 1 player in the duel room, got %d", players)
+	}
+	if players := len(gs.stateManager.GetState().Players); players != 0 {
+		t.Errorf("Expected the default room to stay empty, got %d players", players)
+	}
+
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL+"?room=missing", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected joining an unknown room to fail with 404, got %v", err)
+	}
+
+	resp, err = http.Get(srv.URL + "/api/rooms")
+	if err != nil {
+		t.Fatalf("Failed to list rooms: %v", err)
+	}
+	defer resp.Body.Close()
+	var rooms []RoomInfo
+	if err := json.NewDecoder(resp.Body).Decode(&rooms); err != nil {
+		t.Fatalf("Failed to decode rooms: %v", err)
+	}
+	if len(rooms) != 2 || rooms[0].ID != defaultRoomID || rooms[1].ID != "duel" {
+		t.Fatalf("Expected the default and duel rooms to be listed, got %+v", rooms)
+	}
+	if rooms[1].Players != 1 || rooms[1].MaxPlayers != 2 || rooms[1].MatchState != types.MatchStateLobby {
+		t.Errorf("Expected the duel room with 1 of 2 players in the lobby, got %+v", rooms[1])
+	}
+
+	// The room parameter picks the room for the game API too
+	resp = postJSON(t, srv.URL+"/api/game/start?room=nowhere", nil)
+	var failed types.ErrorMessage
+	if err := json.NewDecoder(resp.Body).Decode(&failed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound || failed.Code != "roomNotFound" {
+		t.Errorf("Expected a roomNotFound error with 404, got %d %+v", resp.StatusCode, failed)
+	}
+}
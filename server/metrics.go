@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// handleMetrics serves server metrics in the Prometheus text exposition format
+func (gs *GameServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	gs.clientsMu.RLock()
+	clientCount := len(gs.clients)
+	peakClients := gs.peakClients
+	gs.clientsMu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	writeMetric(w, "finalcircle_clients", "gauge", "Number of connected clients.", clientCount)
+	writeMetric(w, "finalcircle_clients_peak", "gauge", "Highest number of concurrent clients since the server started.", peakClients)
+	writeMetric(w, "finalcircle_max_players", "gauge", "Number of player slots.", gs.stateManager.MaxPlayers())
+	writeMetric(w, "finalcircle_slow_client_disconnects_total", "counter", "Clients disconnected because their send buffer was full.", gs.slowClientDisconnects.Load())
+	writeMetric(w, "finalcircle_dropped_frames_total", "counter", "State frames skipped for clients whose send buffer was full.", gs.droppedFrames.Load())
+	writeMetric(w, "finalcircle_messages_in_per_second", "gauge", "Client messages handled per second, averaged over the last 10 seconds.", gs.inboundMessages.rate())
+	writeMetric(w, "finalcircle_frames_out_per_second", "gauge", "Frames sent to clients per second, averaged over the last 10 seconds.", gs.outboundFrames.rate())
+	writeMetric(w, "finalcircle_uptime_seconds", "counter", "Seconds since the server started.", time.Since(gs.startTime).Seconds())
+}
+
+// writeMetric writes a single metric with its HELP and TYPE lines
+func writeMetric(w http.ResponseWriter, name, metricType, help string, value interface{}) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+	fmt.Fprintf(w, "%s %v\n", name, value)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+
+	"github.com/gorilla/websocket"
+)
+
+// bannedReason is what banned addresses are told when they connect or are kicked
+const bannedReason = "banned from this server"
+
+// moderationLists are the banned words and addresses the server enforces. A loaded set is never
+// changed, reloading swaps in a new one so readers always see both lists from the same load.
+type moderationLists struct {
+	words map[string]bool
+	ips   map[string]bool
+}
+
+// loadModerationLists reads the banned words and banned IPs files, either may be unset
+func loadModerationLists(wordsFile, ipsFile string) (*moderationLists, error) {
+	words, err := readListFile(wordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading banned words from %s: %w", wordsFile, err)
+	}
+	ips, err := readListFile(ipsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading banned IPs from %s: %w", ipsFile, err)
+	}
+
+	lists := &moderationLists{words: make(map[string]bool), ips: make(map[string]bool)}
+	for _, word := range words {
+		lists.words[strings.ToLower(word)] = true
+	}
+	for _, ip := range ips {
+		lists.ips[ip] = true
+	}
+	return lists, nil
+}
+
+// readListFile reads one entry per line, skipping blank lines and # comments. No file is an empty list.
+func readListFile(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, scanner.Err()
+}
+
+// censor masks the banned words in a text, matching whole words regardless of case
+func (lists *moderationLists) censor(text string) string {
+	if len(lists.words) == 0 {
+		return text
+	}
+
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if lists.words[strings.ToLower(string(runes[start:end]))] {
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes)
+}
+
+// isWordRune reports whether a rune is part of a word for censoring
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// banned reports whether connections from the address are refused
+func (lists *moderationLists) banned(ip string) bool {
+	return lists.ips[ip]
+}
+
+// remoteIP is the address a request comes from, without its port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ReloadResult reports how many moderation list entries a reload loaded and how many clients it kicked
+type ReloadResult struct {
+	BannedWords int `json:"bannedWords"`
+	BannedIPs   int `json:"bannedIps"`
+	Kicked      int `json:"kicked"`
+}
+
+// handleAdminReload re-reads the moderation lists without a restart. New bans apply to new connections
+// right away, with ?kick=true connected players and observers from newly banned addresses are
+// disconnected too. A list that fails to load keeps both of the current ones in place.
+func (gs *GameServer) handleAdminReload(w http.ResponseWriter, r *http.Request) {
+	lists, err := loadModerationLists(gs.config.BannedWordsFile, gs.config.BannedIPsFile)
+	if err != nil {
+		logger.ErrorLogger.Printf("Reloading moderation lists failed: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "reloadFailed", err.Error(), nil)
+		return
+	}
+	gs.moderation.Store(lists)
+
+	result := ReloadResult{BannedWords: len(lists.words), BannedIPs: len(lists.ips)}
+	if r.URL.Query().Get("kick") == "true" {
+		result.Kicked = gs.kickBanned(lists)
+	}
+	logger.InfoLogger.Printf("Reloaded moderation lists: %d banned words, %d banned IPs, %d clients kicked",
+		result.BannedWords, result.BannedIPs, result.Kicked)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// kickBanned disconnects the players and observers connected from banned addresses, returning how many
+func (gs *GameServer) kickBanned(lists *moderationLists) int {
+	errMsg := map[string]interface{}{
+		"type":      "error",
+		"payload":   types.ErrorMessage{Code: "banned", Message: "You are " + bannedReason},
+		"timestamp": time.Now().Unix(),
+	}
+	errJSON, _ := json.Marshal(errMsg)
+
+	kicked := 0
+	kick := func(clients map[string]*WebsocketClient) {
+		for _, client := range clients {
+			if lists.banned(client.IP) {
+				logger.InfoLogger.Printf("Kicking %s connected from banned address %s", client.ID, client.IP)
+				client.closeWith(errJSON, websocket.ClosePolicyViolation, bannedReason)
+				kicked++
+			}
+		}
+	}
+
+	gs.clientsMu.RLock()
+	kick(gs.clients)
+	gs.clientsMu.RUnlock()
+
+	gs.observersMu.RLock()
+	kick(gs.observers)
+	gs.observersMu.RUnlock()
+	return kicked
+}
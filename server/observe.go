@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// handleObserve upgrades a connection to a read-only observer stream of the game state.
+// Observers are not players: they don't count against capacity and their messages are ignored.
+func (gs *GameServer) handleObserve(w http.ResponseWriter, r *http.Request) {
+	room, ok := gs.roomFromRequest(r)
+	if !ok {
+		http.Error(w, types.ErrRoomNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if gs.moderation.Load().banned(remoteIP(r)) {
+		http.Error(w, bannedReason, http.StatusForbidden)
+		return
+	}
+
+	conn, err := gs.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.ErrorLogger.Printf("Error upgrading observer connection from %s: %v", r.RemoteAddr, err)
+		return
+	}
+
+	observer := newWebsocketClient("observer-"+uuid.New().String(), conn)
+	observer.IsObserver = true
+	observer.GameID = room.ID
+	observer.IP = remoteIP(r)
+
+	gs.observersMu.Lock()
+	gs.observers[observer.ID] = observer
+	observerCount := len(gs.observers)
+	gs.observersMu.Unlock()
+
+	logger.InfoLogger.Printf("Observer connected: %s from %s (%d observers)", observer.ID, r.RemoteAddr, observerCount)
+
+	go gs.observerReadPump(observer)
+	go gs.writePump(observer)
+
+	// Send the current state right away instead of waiting for the next broadcast
+	stateMsg := map[string]interface{}{
+		"type":      "gameState",
+		"payload":   room.stateManager.GetObserverState(),
+		"timestamp": time.Now().Unix(),
+	}
+	stateJSON, _ := json.Marshal(stateMsg)
+	observer.Send <- stateJSON
+	gs.sendPlayerUpdates(observer)
+}
+
+// observerReadPump drains an observer's connection, discarding anything it sends
+func (gs *GameServer) observerReadPump(observer *WebsocketClient) {
+	defer gs.clientDisconnect(observer)
+
+	observer.Conn.SetReadLimit(4 * 1024)
+	observer.Conn.SetReadDeadline(time.Now().Add(gs.pongWait()))
+	observer.Conn.SetPongHandler(func(appData string) error {
+		return gs.handlePong(observer, appData)
+	})
+
+	for {
+		_, message, err := observer.Conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logger.ErrorLogger.Printf("Observer read error for %s: %v", observer.ID, err)
+			}
+			return
+		}
+		observer.stats.recordReceived(len(message))
+		logger.DebugLogger.Printf("Ignoring message from read-only observer %s", observer.ID)
+	}
+}
+
+// sendToObservers queues already marshalled state for every observer of a room, returning the ones whose buffer is full
+func (gs *GameServer) sendToObservers(roomID string, stateJSON []byte) []*WebsocketClient {
+	gs.observersMu.RLock()
+	defer gs.observersMu.RUnlock()
+
+	var slowObservers []*WebsocketClient
+	for _, observer := range gs.observers {
+		if observer.GameID != roomID {
+			continue
+		}
+		select {
+		case observer.Send <- stateJSON:
+		default:
+			slowObservers = append(slowObservers, observer)
+		}
+	}
+	return slowObservers
+}
+
+// observerDisconnect removes an observer and closes its connection
+func (gs *GameServer) observerDisconnect(observer *WebsocketClient) {
+	gs.observersMu.Lock()
+	defer gs.observersMu.Unlock()
+
+	if _, ok := gs.observers[observer.ID]; !ok {
+		return
+	}
+
+	observer.Conn.Close()
+	close(observer.done)
+	delete(gs.observers, observer.ID)
+
+	logger.InfoLogger.Printf("Observer disconnected: %s", observer.ID)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// rateWindow is the number of seconds message rates are averaged over
+const rateWindow = 10
+
+// rateCounter counts events in one-second buckets to report a rolling per-second rate.
+// It has its own lock so counting never waits on the rest of the server.
+type rateCounter struct {
+	mu sync.Mutex
+	// counts holds the events of the second in seconds at the same index
+	counts  [rateWindow]int64
+	seconds [rateWindow]int64
+
+	// now is the clock, replaceable in tests
+	now func() time.Time
+}
+
+// newRateCounter creates a counter on the wall clock
+func newRateCounter() *rateCounter {
+	return &rateCounter{now: time.Now}
+}
+
+// add counts n events in the current second
+func (c *rateCounter) add(n int) {
+	second := c.now().Unix()
+	i := second % rateWindow
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seconds[i] != second {
+		c.seconds[i] = second
+		c.counts[i] = 0
+	}
+	c.counts[i] += int64(n)
+}
+
+// rate returns the average events per second over the last rateWindow completed seconds
+func (c *rateCounter) rate() float64 {
+	current := c.now().Unix()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total int64
+	for i, second := range c.seconds {
+		if second < current && second >= current-rateWindow {
+			total += c.counts[i]
+		}
+	}
+	return float64(total) / rateWindow
+}
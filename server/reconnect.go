@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// heldPlayer is a ranked player whose connection dropped, kept in their room until the grace period runs out
+type heldPlayer struct {
+	room  *Room
+	timer *time.Timer
+}
+
+// holdsPlayers reports whether dropped players are held for a reconnect instead of removed right away
+func (gs *GameServer) holdsPlayers() bool {
+	return gs.config.Ranked && gs.config.DisconnectGrace > 0
+}
+
+// issueReconnectToken gives a player the token to reclaim them with after a dropped connection,
+// replacing any earlier one. Casual servers don't hold players, so they issue none.
+func (gs *GameServer) issueReconnectToken(playerId string) string {
+	if !gs.holdsPlayers() {
+		return ""
+	}
+
+	token := uuid.New().String()
+	gs.heldMu.Lock()
+	gs.forgetReconnectTokens(playerId)
+	gs.reconnectTokens[token] = playerId
+	gs.heldMu.Unlock()
+	return token
+}
+
+// forgetReconnectTokens drops the tokens issued to a player, the caller must hold gs.heldMu
+func (gs *GameServer) forgetReconnectTokens(playerId string) {
+	for token, id := range gs.reconnectTokens {
+		if id == playerId {
+			delete(gs.reconnectTokens, token)
+		}
+	}
+}
+
+// reconnectingPlayer looks up the player a reconnect token was issued to
+func (gs *GameServer) reconnectingPlayer(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	gs.heldMu.Lock()
+	defer gs.heldMu.Unlock()
+	playerId, ok := gs.reconnectTokens[token]
+	return playerId, ok
+}
+
+// holdPlayer freezes a disconnected player in their room and removes them once the grace period runs out
+func (gs *GameServer) holdPlayer(room *Room, playerId string) {
+	if err := room.stateManager.DisconnectPlayer(playerId); err != nil {
+		return
+	}
+
+	gs.heldMu.Lock()
+	defer gs.heldMu.Unlock()
+	gs.held[playerId] = &heldPlayer{
+		room:  room,
+		timer: time.AfterFunc(gs.config.DisconnectGrace, func() { gs.releasePlayer(playerId) }),
+	}
+	log.Printf("Holding player %s in room %s for %v", playerId, room.ID, gs.config.DisconnectGrace)
+}
+
+// reclaimPlayer hands a player reconnecting within their grace period back the room they are held in
+func (gs *GameServer) reclaimPlayer(playerId string) (*Room, bool) {
+	gs.heldMu.Lock()
+	defer gs.heldMu.Unlock()
+
+	held, ok := gs.held[playerId]
+	// A timer that already fired is removing the player, they join as a new one
+	if !ok || !held.timer.Stop() {
+		return nil, false
+	}
+	delete(gs.held, playerId)
+	return held.room, true
+}
+
+// releasePlayer removes a held player whose grace period ran out without them reconnecting
+func (gs *GameServer) releasePlayer(playerId string) {
+	gs.heldMu.Lock()
+	held, ok := gs.held[playerId]
+	delete(gs.held, playerId)
+	gs.forgetReconnectTokens(playerId)
+	gs.heldMu.Unlock()
+	if !ok {
+		return
+	}
+
+	held.room.stateManager.RemovePlayer(playerId)
+	log.Printf("Player %s didn't reconnect in time and was removed", playerId)
+	gs.broadcastGameState(held.room)
+}
+
+// stopHolding cancels the removal of every held player, for shutdown
+func (gs *GameServer) stopHolding() {
+	gs.heldMu.Lock()
+	defer gs.heldMu.Unlock()
+
+	for playerId, held := range gs.held {
+		held.timer.Stop()
+		delete(gs.held, playerId)
+	}
+}
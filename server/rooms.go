@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"finalcircle/server/config"
+	"finalcircle/server/game"
+	"finalcircle/server/logger"
+	"finalcircle/server/types"
+
+	"github.com/google/uuid"
+)
+
+// defaultRoomID is the room clients join when they don't ask for a specific one
+const defaultRoomID = "default"
+
+// Room is an independent match with its own game state, capacity and tick rate
+type Room struct {
+	ID           string
+	stateManager *game.StateManager
+
+	// done is closed to stop the room's game loop
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// wake brings an idle room's game loop back to full speed as soon as a player joins
+	wake chan struct{}
+	// interval is the current time between two ticks of the room's game loop, in nanoseconds
+	interval atomic.Int64
+}
+
+// RoomConfig is the per-room configuration accepted when creating a room, zero values use the server defaults
+type RoomConfig struct {
+	ID         string `json:"id"`
+	MaxPlayers int    `json:"maxPlayers"`
+	TickRate   int    `json:"tickRate"`
+}
+
+// RoomInfo describes a room and its configuration
+type RoomInfo struct {
+	ID         string           `json:"id"`
+	MaxPlayers int              `json:"maxPlayers"`
+	TickRate   int              `json:"tickRate"`
+	Players    int              `json:"players"`
+	GameActive bool             `json:"gameActive"`
+	MatchState types.MatchState `json:"matchState"`
+}
+
+// RoomStatus is the health of a room as reported by /api/rooms/status
+type RoomStatus struct {
+	ID          string         `json:"id"`
+	Players     int            `json:"players"`
+	PeakPlayers int            `json:"peakPlayers"`
+	MaxPlayers  int            `json:"maxPlayers"`
+	GameActive  bool           `json:"gameActive"`
+	GameTime    float64        `json:"gameTime"`
+	MatchID     string         `json:"matchId"`
+	GameMode    types.GameMode `json:"gameMode"`
+	Map         string         `json:"map"`
+}
+
+// RoomsStatus is the health of every room along with server-wide totals
+type RoomsStatus struct {
+	Rooms         []RoomStatus `json:"rooms"`
+	TotalRooms    int          `json:"totalRooms"`
+	TotalPlayers  int          `json:"totalPlayers"`
+	ActiveMatches int          `json:"activeMatches"`
+	Clients       int          `json:"clients"`
+	PeakClients   int          `json:"peakClients"`
+}
+
+// newRoom creates a room with its own state manager
+func newRoom(id string, settings game.Settings) *Room {
+	return &Room{
+		ID:           id,
+		stateManager: game.NewStateManagerWithSettings(settings),
+		done:         make(chan struct{}),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// stop ends the room's game loop
+func (room *Room) stop() {
+	room.stopOnce.Do(func() { close(room.done) })
+}
+
+// wakeUp makes an idle room tick right away instead of waiting for its next slow tick
+func (room *Room) wakeUp() {
+	select {
+	case room.wake <- struct{}{}:
+	default:
+	}
+}
+
+// tickInterval is the current time between two ticks of the room's game loop
+func (room *Room) tickInterval() time.Duration {
+	return time.Duration(room.interval.Load())
+}
+
+// info returns the room's configuration and occupancy
+func (room *Room) info() RoomInfo {
+	summary := room.stateManager.Summary()
+	return RoomInfo{
+		ID:         room.ID,
+		MaxPlayers: room.stateManager.MaxPlayers(),
+		TickRate:   room.stateManager.TickRate(),
+		Players:    summary.Players,
+		GameActive: summary.GameActive,
+		MatchState: room.stateManager.MatchState(),
+	}
+}
+
+// createRoom adds a room on top of the server defaults and starts its game loop
+func (gs *GameServer) createRoom(roomConfig RoomConfig) (*Room, error) {
+	settings := gs.settings
+	if roomConfig.MaxPlayers != 0 {
+		if roomConfig.MaxPlayers < 1 || roomConfig.MaxPlayers > config.MaxPlayersCeiling {
+			return nil, types.ErrInvalidRoomConfig
+		}
+		settings.MaxPlayers = roomConfig.MaxPlayers
+	}
+	if roomConfig.TickRate != 0 {
+		if roomConfig.TickRate < 1 || roomConfig.TickRate > config.MaxTickRate {
+			return nil, types.ErrInvalidRoomConfig
+		}
+		settings.TickRate = roomConfig.TickRate
+	}
+
+	id := roomConfig.ID
+	if id == "" {
+		id = uuid.New().String()[:8]
+	}
+
+	gs.roomsMu.Lock()
+	if _, exists := gs.rooms[id]; exists {
+		gs.roomsMu.Unlock()
+		return nil, types.ErrRoomExists
+	}
+	if gs.config.MaxRooms > 0 && len(gs.rooms) >= gs.config.MaxRooms {
+		gs.roomsMu.Unlock()
+		logger.WarningLogger.Printf("Refused to create room %s: the limit of %d rooms is reached", id, gs.config.MaxRooms)
+		return nil, types.ErrTooManyRooms
+	}
+	room := newRoom(id, settings)
+	gs.rooms[id] = room
+	gs.roomsMu.Unlock()
+
+	go gs.runRoom(room)
+	logger.InfoLogger.Printf("Room %s created (max players: %d, tick rate: %d)", id, settings.MaxPlayers, settings.TickRate)
+	return room, nil
+}
+
+// removeRoomIfEmpty stops an empty room's game loop and forgets it, reporting whether it did. The check
+// and the removal happen under roomsMu, so a player joining meanwhile either keeps the room or finds it gone.
+func (gs *GameServer) removeRoomIfEmpty(room *Room) bool {
+	gs.roomsMu.Lock()
+	defer gs.roomsMu.Unlock()
+
+	if !gs.roomIsEmpty(room) {
+		return false
+	}
+	delete(gs.rooms, room.ID)
+	room.stop()
+	return true
+}
+
+// roomRegistered reports whether a room is still open to players, it isn't once it has been removed
+func (gs *GameServer) roomRegistered(room *Room) bool {
+	current, ok := gs.getRoom(room.ID)
+	return ok && current == room
+}
+
+// roomIsEmpty reports whether nobody is playing or watching in a room and no match is running
+func (gs *GameServer) roomIsEmpty(room *Room) bool {
+	if summary := room.stateManager.Summary(); summary.Players > 0 || summary.GameActive {
+		return false
+	}
+
+	gs.observersMu.RLock()
+	defer gs.observersMu.RUnlock()
+	for _, observer := range gs.observers {
+		if observer.GameID == room.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// getRoom looks up a room by ID
+func (gs *GameServer) getRoom(id string) (*Room, bool) {
+	gs.roomsMu.RLock()
+	defer gs.roomsMu.RUnlock()
+
+	room, ok := gs.rooms[id]
+	return room, ok
+}
+
+// roomOf returns the room a client is playing or watching in
+func (gs *GameServer) roomOf(client *WebsocketClient) *Room {
+	if room, ok := gs.getRoom(client.GameID); ok {
+		return room
+	}
+	return gs.defaultRoom
+}
+
+// roomFromRequest returns the room named by the request's "room" query parameter, or the default room
+func (gs *GameServer) roomFromRequest(r *http.Request) (*Room, bool) {
+	id := r.URL.Query().Get("room")
+	if id == "" {
+		return gs.defaultRoom, true
+	}
+	return gs.getRoom(id)
+}
+
+// allRooms returns every room, ordered by ID
+func (gs *GameServer) allRooms() []*Room {
+	gs.roomsMu.RLock()
+	rooms := make([]*Room, 0, len(gs.rooms))
+	for _, room := range gs.rooms {
+		rooms = append(rooms, room)
+	}
+	gs.roomsMu.RUnlock()
+
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms
+}
+
+// roomInfos lists every room, ordered by ID
+func (gs *GameServer) roomInfos() []RoomInfo {
+	rooms := gs.allRooms()
+	infos := make([]RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		infos = append(infos, room.info())
+	}
+	return infos
+}
+
+// roomsStatus reports the health of every room and the totals across them
+func (gs *GameServer) roomsStatus() RoomsStatus {
+	rooms := gs.allRooms()
+	status := RoomsStatus{Rooms: make([]RoomStatus, 0, len(rooms)), TotalRooms: len(rooms)}
+	for _, room := range rooms {
+		summary := room.stateManager.Summary()
+		status.Rooms = append(status.Rooms, RoomStatus{
+			ID:          room.ID,
+			Players:     summary.Players,
+			PeakPlayers: summary.PeakPlayers,
+			MaxPlayers:  room.stateManager.MaxPlayers(),
+			GameActive:  summary.GameActive,
+			GameTime:    summary.GameTime,
+			MatchID:     summary.MatchID,
+			GameMode:    summary.GameMode,
+			Map:         summary.MapName,
+		})
+		status.TotalPlayers += summary.Players
+		if summary.GameActive {
+			status.ActiveMatches++
+		}
+	}
+
+	gs.clientsMu.RLock()
+	status.Clients = len(gs.clients)
+	status.PeakClients = gs.peakClients
+	gs.clientsMu.RUnlock()
+	return status
+}
+
+// handleListRooms reports every room with its configuration
+func (gs *GameServer) handleListRooms(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gs.roomInfos())
+}
+
+// handleRoomsStatus reports the health of every room, the multi-room counterpart of /api/status
+func (gs *GameServer) handleRoomsStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gs.roomsStatus())
+}
+
+// handleMatchTimeline returns the timeline of a match played in any room
+func (gs *GameServer) handleMatchTimeline(w http.ResponseWriter, r *http.Request) {
+	matchID := r.PathValue("id")
+	for _, room := range gs.allRooms() {
+		if events, ok := room.stateManager.Timeline(matchID); ok {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"matchId": matchID,
+				"roomId":  room.ID,
+				"events":  events,
+			})
+			return
+		}
+	}
+	writeAPIError(w, types.ErrMatchNotFound)
+}
+
+// handleCreateRoom creates a room from a JSON RoomConfig
+func (gs *GameServer) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
+	var roomConfig RoomConfig
+	if err := json.NewDecoder(r.Body).Decode(&roomConfig); err != nil {
+		writeAPIError(w, types.ErrInvalidRoomConfig)
+		return
+	}
+
+	room, err := gs.createRoom(roomConfig)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(room.info())
+}
+
+// roomTickInterval is how long a room's game loop should wait between ticks, longer while the room
+// is empty and has no match running
+func (gs *GameServer) roomTickInterval(room *Room, activeInterval time.Duration) time.Duration {
+	idleRate := gs.config.IdleTickRate
+	if idleRate <= 0 || time.Second/time.Duration(idleRate) <= activeInterval {
+		return activeInterval
+	}
+
+	summary := room.stateManager.Summary()
+	if summary.Players > 0 || summary.GameActive {
+		return activeInterval
+	}
+	return time.Second / time.Duration(idleRate)
+}
+
+// runRoom updates and broadcasts a room's game state at its tick rate until the room is stopped
+func (gs *GameServer) runRoom(room *Room) {
+	tickRate := room.stateManager.TickRate()
+	activeInterval := time.Second / time.Duration(tickRate)
+	interval := activeInterval
+	room.interval.Store(int64(interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Room %s game loop started at %d updates per second", room.ID, tickRate)
+
+	var throttle *broadcastThrottle
+	if gs.config.AdaptiveBroadcast {
+		throttle = newBroadcastThrottle(room.ID, tickRate, gs.config.MinBroadcastRate)
+	}
+
+	// emptySince is when the room was last seen empty, for reaping rooms nobody uses
+	var emptySince time.Time
+
+	updateCount := 0
+	for {
+		select {
+		case <-room.done:
+			log.Printf("Room %s game loop stopped", room.ID)
+			return
+		case <-ticker.C:
+		case <-room.wake:
+		}
+
+		// The default room's loop is the one liveness checks watch
+		if room == gs.defaultRoom {
+			gs.lastHeartbeat.Store(time.Now().UnixNano())
+		}
+		start := time.Now()
+		room.stateManager.Update()
+		gs.dispatchEvents(room)
+		if throttle == nil || throttle.due() {
+			gs.broadcastGameState(room)
+		}
+		if throttle != nil {
+			throttle.record(time.Since(start))
+		}
+
+		// Empty rooms without a match tick slowly until a player joins
+		if next := gs.roomTickInterval(room, activeInterval); next != interval {
+			interval = next
+			ticker.Reset(interval)
+			room.interval.Store(int64(interval))
+			if interval == activeInterval {
+				logger.InfoLogger.Printf("Room %s is back to %d updates per second", room.ID, tickRate)
+			} else {
+				logger.InfoLogger.Printf("Room %s is idle, slowing down to one update every %v", room.ID, interval)
+			}
+		}
+
+		// Created rooms nobody has used for a while are removed, the default room stays
+		if room != gs.defaultRoom && gs.config.EmptyRoomTTL > 0 {
+			if !gs.roomIsEmpty(room) {
+				emptySince = time.Time{}
+			} else if emptySince.IsZero() {
+				emptySince = time.Now()
+			} else if time.Since(emptySince) >= gs.config.EmptyRoomTTL && gs.removeRoomIfEmpty(room) {
+				logger.InfoLogger.Printf("Room %s has been empty for %v, removed it", room.ID, gs.config.EmptyRoomTTL)
+				return
+			}
+		}
+
+		updateCount++
+		if updateCount%(tickRate*5) == 0 { // Log about every 5 seconds
+			state := room.stateManager.GetState()
+			log.Printf("Room %s status: %d players, game active: %v, game time: %.2f",
+				room.ID, len(state.Players), state.IsGameActive, state.GameTime)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"finalcircle/server/config"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+)
+
+// version and build identify the running server, release builds set them with
+// -ldflags "-X main.version=<version> -X main.build=<commit>"
+var (
+	version = "dev"
+	build   = "unknown"
+)
+
+// ServerInfo describes what a server is and what it supports, so clients can check they are compatible
+// and adapt to it. It is public and must never carry anything secret.
+type ServerInfo struct {
+	Version            string          `json:"version"`
+	Build              string          `json:"build"`
+	ProtocolVersion    int             `json:"protocolVersion"`
+	MinProtocolVersion int             `json:"minProtocolVersion"`
+	ProtocolFeatures   []string        `json:"protocolFeatures"`
+	FeatureFlags       map[string]bool `json:"featureFlags"`
+	MaxPlayers         int             `json:"maxPlayers"`
+	TickRate           int             `json:"tickRate"`
+	GameMode           types.GameMode  `json:"gameMode"`
+	Map                string          `json:"map"`
+}
+
+// serverInfo reports the default room's game and which optional features the server runs with
+func (gs *GameServer) serverInfo() ServerInfo {
+	cfg := gs.config
+	return ServerInfo{
+		Version:            version,
+		Build:              build,
+		ProtocolVersion:    types.ProtocolVersion,
+		MinProtocolVersion: types.MinProtocolVersion,
+		ProtocolFeatures:   types.ProtocolFeatures,
+		FeatureFlags: map[string]bool{
+			"ranked":            cfg.Ranked,
+			"teams":             cfg.TeamCount > 0,
+			"zone":              gs.stateManager.ZoneEnabled(),
+			"supplyDrops":       cfg.SupplyDropInterval > 0,
+			"respawnWaves":      cfg.RespawnWaveSeconds > 0,
+			"autoStart":         cfg.AutoStart,
+			"lateJoinSpectate":  cfg.LateJoin == string(game.LateJoinSpectate),
+			"selfDamage":        cfg.SelfDamage,
+			"fallDamage":        cfg.FallDamage,
+			"healthRegen":       cfg.HealthRegen,
+			"fireRateLimit":     cfg.EnforceFireRate,
+			"obstacleCollision": cfg.ObstacleCollision,
+			"moveSpeedCheck":    cfg.MaxMoveSpeed > 0,
+			"clientHitReg":      cfg.HitRegMode == string(game.HitRegClientAssisted),
+			"coalesceMoves":     cfg.CoalesceMoves,
+			"adaptiveBroadcast": cfg.AdaptiveBroadcast,
+			"accounts":          gs.auth != nil,
+			"slowClientDrop":    cfg.SlowClientPolicy == config.SlowClientDrop,
+		},
+		MaxPlayers: gs.stateManager.MaxPlayers(),
+		TickRate:   gs.stateManager.TickRate(),
+		GameMode:   gs.stateManager.GameMode(),
+		Map:        gs.stateManager.MapName(),
+	}
+}
+
+// handleServerInfo serves GET /api/serverinfo
+func (gs *GameServer) handleServerInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gs.serverInfo())
+}
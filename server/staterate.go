@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// maxStateInterval is the longest a client may ask to go between two game states, so asking can't
+// stop them altogether
+const maxStateInterval = time.Second
+
+// stateInterval is the minimum time between two game states sent to a client, the longer of the
+// server's MIN_CLIENT_STATE_INTERVAL and what the client asked for in its hello
+func (gs *GameServer) stateInterval(client *WebsocketClient) time.Duration {
+	return max(gs.config.MinClientStateInterval, time.Duration(client.stateInterval.Load()))
+}
+
+// stateDue reports whether a client gets the state broadcast at now, recording it when it does. Every
+// state is complete, so the broadcasts a slow client skips are simply coalesced into the next one it
+// gets instead of filling its send buffer until it's disconnected. A tenth of the interval is forgiven
+// so ticks arriving a little early don't make the client skip one more broadcast than it asked for.
+func (gs *GameServer) stateDue(client *WebsocketClient, now time.Time) bool {
+	interval := gs.stateInterval(client)
+	if last := client.lastState.Load(); interval > 0 && last != 0 && now.Sub(time.Unix(0, last)) < interval*9/10 {
+		return false
+	}
+	client.lastState.Store(now.UnixNano())
+	return true
+}
+
+// requestStateInterval applies the minimum time between game states a client asked for in its hello
+func (gs *GameServer) requestStateInterval(client *WebsocketClient, ms float64) {
+	interval := min(time.Duration(ms*float64(time.Millisecond)), maxStateInterval)
+	if interval <= 0 {
+		return
+	}
+	client.stateInterval.Store(int64(interval))
+	log.Printf("Client %s receives game states at most every %v", client.ID, gs.stateInterval(client))
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+// achievementsOf returns the achievements announced for a player in a batch of events
+func achievementsOf(events []types.GameEvent, playerID string) []types.AchievementPayload {
+	var achievements []types.AchievementPayload
+	for _, event := range events {
+		if event.Type != types.MessageTypeAchievement {
+			continue
+		}
+		if achievement := event.Payload.(types.AchievementPayload); achievement.PlayerID == playerID {
+			achievements = append(achievements, achievement)
+		}
+	}
+	return achievements
+}
+
+// killVictim has the killer shoot the victim, who respawns instantly in front of them
+func killVictim(t *testing.T, sm *game.StateManager, killer, victim string) {
+	t.Helper()
+
+	victimPos := types.Vector3{X: 10, Y: 0, Z: 0}
+	placePlayer(t, sm, killer, types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, victim, victimPos)
+	shootAt(t, sm, killer, bodyOf(victimPos))
+}
+
+func TestKillstreakAchievement(t *testing.T) {
+	sm := newDeathmatch(t, 0, 0)
+
+	killVictim(t, sm, "killer", "victim")
+	killVictim(t, sm, "killer", "victim")
+	if achievements := achievementsOf(sm.DrainEvents(), "killer"); len(achievements) != 0 {
+		t.Errorf("Expected no achievement before the first milestone, got %v", achievements)
+	}
+
+	killVictim(t, sm, "killer", "victim")
+	achievements := achievementsOf(sm.DrainEvents(), "killer")
+	if len(achievements) != 1 {
+		t.Fatalf("Expected one achievement at a 3 killstreak, got %v", achievements)
+	}
+	if achievements[0].Achievement != game.AchievementKillstreak || achievements[0].Streak != 3 {
+		t.Errorf("Expected killstreak achievement with streak 3, got %+v", achievements[0])
+	}
+}
+
+func TestKillstreakAchievementCooldown(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.StartingWeapons = []string{"SNIPER"}
+	settings.RespawnDelay = 0
+	settings.SpawnProtection = 0
+	settings.StreakMilestones = []int{1, 2}
+	settings.AchievementCooldown = 10 * time.Second
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"killer", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	// Rapid kills hit both milestones but only the first is announced
+	killVictim(t, sm, "killer", "victim")
+	killVictim(t, sm, "killer", "victim")
+	if achievements := achievementsOf(sm.DrainEvents(), "killer"); len(achievements) != 1 {
+		t.Errorf("Expected cooldown to suppress the second achievement, got %v", achievements)
+	}
+}
+
+func TestRevengeAchievement(t *testing.T) {
+	sm := newDeathmatch(t, 0, 0)
+
+	killVictim(t, sm, "killer", "victim")
+	sm.DrainEvents()
+
+	// The victim gets back at their killer
+	killVictim(t, sm, "victim", "killer")
+	achievements := achievementsOf(sm.DrainEvents(), "victim")
+	if len(achievements) != 1 || achievements[0].Achievement != game.AchievementRevenge {
+		t.Fatalf("Expected a revenge achievement, got %v", achievements)
+	}
+	if achievements[0].VictimID != "killer" {
+		t.Errorf("Expected revenge on killer, got %q", achievements[0].VictimID)
+	}
+
+	// Killing them again is no longer revenge
+	killVictim(t, sm, "victim", "killer")
+	for _, achievement := range achievementsOf(sm.DrainEvents(), "victim") {
+		if achievement.Achievement == game.AchievementRevenge {
+			t.Error("Expected revenge to only be awarded once per death")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAdminRespawnRevivesDeadPlayer(t *testing.T) {
+	sm := newDeathmatch(t, time.Minute, 0)
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+	if sm.GetState().Players["victim"].IsAlive {
+		t.Fatal("Expected the victim to be dead")
+	}
+
+	if err := sm.RespawnPlayer("victim"); err != nil {
+		t.Fatalf("Failed to respawn: %v", err)
+	}
+	victim := sm.GetState().Players["victim"]
+	if !victim.IsAlive || victim.Health != 100 {
+		t.Errorf("Expected the victim alive with full health, got alive=%v health=%d", victim.IsAlive, victim.Health)
+	}
+
+	if err := sm.RespawnPlayer("nobody"); err != types.ErrPlayerNotFound {
+		t.Errorf("Expected ErrPlayerNotFound for an unknown player, got %v", err)
+	}
+}
+
+func TestAdminHealRestoresHealth(t *testing.T) {
+	sm := newShooterAndTarget(t, game.DefaultSettings())
+	shootAt(t, sm, "shooter", types.Vector3{X: 10, Y: 1, Z: 0})
+	if health := sm.GetState().Players["target"].Health; health >= 100 {
+		t.Fatalf("Expected the target to be damaged, got health %d", health)
+	}
+
+	if err := sm.HealPlayer("target"); err != nil {
+		t.Fatalf("Failed to heal: %v", err)
+	}
+	if health := sm.GetState().Players["target"].Health; health != 100 {
+		t.Errorf("Expected full health after healing, got %d", health)
+	}
+
+	if err := sm.HealPlayer("nobody"); err != types.ErrPlayerNotFound {
+		t.Errorf("Expected ErrPlayerNotFound for an unknown player, got %v", err)
+	}
+}
+
+func TestAdminTeleportClampsIntoWorld(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	target := types.Vector3{X: 20, Y: 0, Z: -15}
+	position, err := sm.TeleportPlayer("player1", target)
+	if err != nil {
+		t.Fatalf("Failed to teleport: %v", err)
+	}
+	if position != target || sm.GetState().Players["player1"].Position != target {
+		t.Errorf("Expected the player at %v, got %v", target, sm.GetState().Players["player1"].Position)
+	}
+
+	position, err = sm.TeleportPlayer("player1", types.Vector3{X: 5000, Y: 0, Z: 0})
+	if err != nil {
+		t.Fatalf("Failed to teleport: %v", err)
+	}
+	radius := game.DefaultSettings().WorldRadius
+	if position.X >= radius || position.X <= 0 || position.Z != 0 {
+		t.Errorf("Expected an out of bounds target clamped inside radius %.0f on the same bearing, got %v", radius, position)
+	}
+
+	if _, err := sm.TeleportPlayer("player1", types.Vector3{X: math.NaN()}); err != types.ErrInvalidPosition {
+		t.Errorf("Expected ErrInvalidPosition for a NaN target, got %v", err)
+	}
+}
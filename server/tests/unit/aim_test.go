@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"errors"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"math"
+	"testing"
+)
+
+// face turns a player to the given yaw, where 0 faces -Z
+func face(t *testing.T, sm *game.StateManager, id string, yaw float64) {
+	t.Helper()
+
+	rotation := types.Vector3{Y: yaw}
+	action := types.PlayerAction{Type: "move", Data: types.PlayerActionData{Rotation: &rotation}}
+	if err := sm.HandlePlayerAction(id, action); err != nil {
+		t.Fatalf("Failed to turn player %s: %v", id, err)
+	}
+}
+
+func TestShotPointingAwayFromFacingIsRejected(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.MaxAimDeviation = math.Pi / 4
+	sm := newShooterAndTarget(t, settings)
+
+	// Facing -Z while shooting at a target along +X is 90° off
+	face(t, sm, "shooter", 0)
+	target := types.Vector3{X: 10, Y: 1.2, Z: 0}
+	err := sm.HandlePlayerAction("shooter", types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &target}})
+	if !errors.Is(err, types.ErrShotMisaligned) {
+		t.Fatalf("Expected the shot to be rejected as misaligned, got %v", err)
+	}
+	if stats, _ := sm.GetPlayerStats("target"); stats.Health != 100 {
+		t.Errorf("Expected the rejected shot to do no damage, target health %d", stats.Health)
+	}
+
+	// Facing the target, the same shot lands, even with the aim a little off the facing
+	face(t, sm, "shooter", -math.Pi/2+0.2)
+	shootAt(t, sm, "shooter", target)
+	if stats, _ := sm.GetPlayerStats("target"); stats.Health >= 100 {
+		t.Errorf("Expected the aligned shot to hit, target health %d", stats.Health)
+	}
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+// chatRecipients returns who the chat events drained from the state manager are addressed to,
+// an empty ID meaning everyone
+func chatRecipients(sm *game.StateManager) []string {
+	var recipients []string
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeChat {
+			recipients = append(recipients, event.PlayerID)
+		}
+	}
+	return recipients
+}
+
+func TestMutedPlayerChatIsNotDelivered(t *testing.T) {
+	sm := game.NewStateManager(10)
+	for _, id := range []string{"talker", "listener"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	sm.DrainEvents()
+
+	if err := sm.SendChat("talker", "hello"); err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+	if recipients := chatRecipients(sm); len(recipients) != 1 || recipients[0] != "" {
+		t.Errorf("Expected an unmuted message broadcast to everyone, got recipients %q", recipients)
+	}
+
+	if err := sm.MutePlayer("talker", 10*time.Second); err != nil {
+		t.Fatalf("Failed to mute: %v", err)
+	}
+	if err := sm.SendChat("talker", "hello again"); err != nil {
+		t.Fatalf("Expected a muted player to still be able to send, got %v", err)
+	}
+	if recipients := chatRecipients(sm); len(recipients) != 1 || recipients[0] != "talker" {
+		t.Errorf("Expected a muted message echoed only to its sender, got recipients %q", recipients)
+	}
+
+	// Timed mutes lift on their own
+	sm.UpdateWithDelta(11)
+	if err := sm.SendChat("talker", "back"); err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+	if recipients := chatRecipients(sm); len(recipients) != 1 || recipients[0] != "" {
+		t.Errorf("Expected the mute to expire, got recipients %q", recipients)
+	}
+
+	if err := sm.SendChat("talker", ""); err != types.ErrInvalidChatMessage {
+		t.Errorf("Expected ErrInvalidChatMessage for an empty message, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"encoding/json"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"fmt"
+	"testing"
+)
+
+func TestCompactStateIsSmallerThanVerbose(t *testing.T) {
+	sm := game.NewStateManager(50)
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("player-%02d", i)
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+		placePlayer(t, sm, id, types.Vector3{X: float64(i) * 3.14159, Y: 0, Z: float64(i) * -2.71828})
+	}
+	state := sm.GetState()
+
+	verbose, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Failed to marshal verbose state: %v", err)
+	}
+	compact, err := json.Marshal(state.Compact())
+	if err != nil {
+		t.Fatalf("Failed to marshal compact state: %v", err)
+	}
+
+	t.Logf("verbose: %d bytes, compact: %d bytes", len(verbose), len(compact))
+	if len(compact)*2 > len(verbose) {
+		t.Errorf("Expected the compact state to be less than half the verbose size, got %d vs %d bytes", len(compact), len(verbose))
+	}
+
+	// Nothing a client needs is lost
+	var decoded types.CompactGameState
+	if err := json.Unmarshal(compact, &decoded); err != nil {
+		t.Fatalf("Failed to decode compact state: %v", err)
+	}
+	player := decoded.Players["player-03"]
+	if player.Position != [3]float64{9.425, 0, -8.155} || player.Health != 100 || !player.IsAlive {
+		t.Errorf("Expected player-03 to round-trip, got %+v", player)
+	}
+}
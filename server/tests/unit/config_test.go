@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"finalcircle/server/config"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"fmt"
+	"testing"
+)
+
+func TestLoadConfigMaxPlayers(t *testing.T) {
+	t.Setenv("MAX_PLAYERS", "3")
+	cfg := config.LoadConfig()
+	if cfg.MaxPlayers != 3 {
+		t.Fatalf("Expected MaxPlayers 3, got %d", cfg.MaxPlayers)
+	}
+
+	// The configured capacity is enforced by AddPlayer
+	sm := game.NewStateManager(cfg.MaxPlayers)
+	for i := 0; i < cfg.MaxPlayers; i++ {
+		if err := sm.AddPlayer(fmt.Sprintf("player%d", i)); err != nil {
+			t.Fatalf("Failed to add player %d: %v", i, err)
+		}
+	}
+	if err := sm.AddPlayer("onetoomany"); err != types.ErrServerFull {
+		t.Errorf("Expected ErrServerFull beyond MaxPlayers, got %v", err)
+	}
+}
+
+func TestLoadConfigRejectsInvalidMaxPlayers(t *testing.T) {
+	for _, value := range []string{"0", "-5", "100000", "lots"} {
+		t.Setenv("MAX_PLAYERS", value)
+		if cfg := config.LoadConfig(); cfg.MaxPlayers != config.DefaultMaxPlayers {
+			t.Errorf("Expected default MaxPlayers for %q, got %d", value, cfg.MaxPlayers)
+		}
+	}
+}
+
+func TestLoadConfigRejectsInvalidWorldRadius(t *testing.T) {
+	for _, value := range []string{"0", "-100", "0.3", "NaN", "Inf", "huge"} {
+		t.Setenv("WORLD_RADIUS", value)
+		if cfg := config.LoadConfig(); cfg.WorldRadius != config.DefaultWorldRadius {
+			t.Errorf("Expected default WorldRadius for %q, got %v", value, cfg.WorldRadius)
+		}
+	}
+
+	t.Setenv("WORLD_RADIUS", "250")
+	if cfg := config.LoadConfig(); cfg.WorldRadius != 250 {
+		t.Errorf("Expected WorldRadius 250, got %v", cfg.WorldRadius)
+	}
+}
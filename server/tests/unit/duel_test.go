@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestDuelEndsOnFirstKill(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDuel
+	settings.StartingWeapons = []string{"SNIPER"}
+	settings.RespawnDelay = 0
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"challenger", "defender"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	sm.DrainEvents()
+
+	killVictim(t, sm, "defender", "challenger")
+	sm.UpdateWithDelta(1)
+
+	state := sm.GetState()
+	if state.IsGameActive {
+		t.Error("Expected the duel to end on the first kill")
+	}
+	if challenger := state.Players["challenger"]; challenger.IsAlive || challenger.IsSpectator {
+		t.Errorf("Expected the loser to stay dead without respawning, got alive=%v spectator=%v",
+			challenger.IsAlive, challenger.IsSpectator)
+	}
+	if state.Players["defender"].Placement != 1 || state.Players["challenger"].Placement != 2 {
+		t.Errorf("Expected defender first and challenger second, got %d and %d",
+			state.Players["defender"].Placement, state.Players["challenger"].Placement)
+	}
+
+	winner := ""
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeMatchEnd {
+			winner = event.Payload.(types.MatchEndPayload).WinnerID
+		}
+	}
+	if winner != "defender" {
+		t.Errorf("Expected the matchEnd broadcast to name defender the winner, got %q", winner)
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestBurnDamagesOverTimeAndCanKill(t *testing.T) {
+	sm := game.NewStateManager(10)
+	for _, id := range []string{"arsonist", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+
+	burn := types.StatusEffect{Type: game.EffectBurn, SourceID: "arsonist", Remaining: 3, DamagePerSecond: 10}
+	if err := sm.ApplyEffect("victim", burn); err != nil {
+		t.Fatalf("Failed to apply burn: %v", err)
+	}
+
+	for _, want := range []int{90, 80, 70, 70} {
+		sm.UpdateWithDelta(1)
+		if health := sm.GetState().Players["victim"].Health; health != want {
+			t.Fatalf("Expected health %d, got %d", want, health)
+		}
+	}
+	if effects := sm.GetState().Players["victim"].Effects; len(effects) != 0 {
+		t.Errorf("Expected the burn to have run out, got %v", effects)
+	}
+
+	// A second burn refreshes the first instead of stacking
+	sm.ApplyEffect("victim", burn)
+	sm.ApplyEffect("victim", types.StatusEffect{Type: game.EffectBurn, SourceID: "arsonist", Remaining: 10, DamagePerSecond: 50})
+	if effects := sm.GetState().Players["victim"].Effects; len(effects) != 1 {
+		t.Fatalf("Expected burns not to stack, got %v", effects)
+	}
+
+	sm.UpdateWithDelta(1)
+	sm.UpdateWithDelta(1)
+
+	victim := sm.GetState().Players["victim"]
+	if victim.IsAlive || victim.Deaths != 1 || len(victim.Effects) != 0 {
+		t.Errorf("Expected the burn to kill the victim and clear, got alive=%v deaths=%d effects=%v",
+			victim.IsAlive, victim.Deaths, victim.Effects)
+	}
+	if kills := sm.GetState().Players["arsonist"].Kills; kills != 1 {
+		t.Errorf("Expected the kill to be credited to whoever applied the burn, got %d kills", kills)
+	}
+}
+
+func TestPoisonStacks(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("victim"); err != nil {
+		t.Fatalf("Failed to add victim: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		sm.ApplyEffect("victim", types.StatusEffect{Type: game.EffectPoison, Remaining: 5, DamagePerSecond: 2})
+	}
+	sm.UpdateWithDelta(1)
+
+	if health := sm.GetState().Players["victim"].Health; health != 94 {
+		t.Errorf("Expected three stacked doses to deal 6 damage, got health %d", health)
+	}
+}
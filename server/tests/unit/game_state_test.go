@@ -1,12 +1,21 @@
 package tests
 
 import (
+	"errors"
 	"finalcircle/server/game"
+	"finalcircle/server/logger"
 	"finalcircle/server/types"
+	"math"
+	"os"
 	"testing"
 	"time"
 )
 
+func TestMain(m *testing.M) {
+	logger.Init(true)
+	os.Exit(m.Run())
+}
+
 func TestNewStateManager(t *testing.T) {
 	maxPlayers := 100
 	sm := game.NewStateManager(maxPlayers)
@@ -120,12 +129,8 @@ func TestPlayerActions(t *testing.T) {
 	// Test movement action
 	moveAction := types.PlayerAction{
 		Type: "move",
-		Data: map[string]interface{}{
-			"direction": map[string]interface{}{
-				"x": 1.0,
-				"y": 0.0,
-				"z": 0.0,
-			},
+		Data: types.PlayerActionData{
+			Direction: &types.Vector3{X: 1.0, Y: 0.0, Z: 0.0},
 		},
 	}
 
@@ -168,6 +173,14 @@ func TestPlayerActions(t *testing.T) {
 func TestGameLifecycle(t *testing.T) {
 	sm := game.NewStateManager(10)
 
+	// A game needs at least two players to start
+	if err := sm.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player1: %v", err)
+	}
+	if err := sm.AddPlayer("player2"); err != nil {
+		t.Fatalf("Failed to add player2: %v", err)
+	}
+
 	// Test starting the game
 	err := sm.StartGame()
 	if err != nil {
@@ -191,12 +204,15 @@ func TestGameLifecycle(t *testing.T) {
 func TestUpdateGameState(t *testing.T) {
 	sm := game.NewStateManager(10)
 
-	// Add a player
+	// Add players
 	playerId := "testPlayer"
 	err := sm.AddPlayer(playerId)
 	if err != nil {
 		t.Fatalf("Failed to add player: %v", err)
 	}
+	if err := sm.AddPlayer("otherPlayer"); err != nil {
+		t.Fatalf("Failed to add second player: %v", err)
+	}
 
 	// Start game
 	err = sm.StartGame()
@@ -230,6 +246,9 @@ func TestUpdatePlayerName(t *testing.T) {
 
 	// Get the initial name
 	initialName := sm.GetState().Players[playerId].DisplayName
+	if initialName == "" {
+		t.Error("Expected player to have a default name")
+	}
 
 	// Update name
 	newName := "UpdatedPlayerName"
@@ -250,3 +269,219 @@ func TestUpdatePlayerName(t *testing.T) {
 		t.Error("Expected error when updating name for non-existent player, got nil")
 	}
 }
+
+func TestMoveClampedToRingWall(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.MaxMoveSpeed = 0 // walk out of the ring in one step
+	sm := game.NewStateManagerWithSettings(settings)
+	playerId := "testPlayer"
+
+	if err := sm.AddPlayer(playerId); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	// Try to walk well outside the 800 unit ring
+	moveAction := types.PlayerAction{
+		Type: "move",
+		Data: types.PlayerActionData{
+			Position: &types.Vector3{X: 1200.0, Y: 2.0, Z: 900.0},
+		},
+	}
+	if err := sm.HandlePlayerAction(playerId, moveAction); err != nil {
+		t.Fatalf("Failed to handle move action: %v", err)
+	}
+
+	position, ok := sm.GetPlayerPosition(playerId)
+	if !ok {
+		t.Fatal("Expected player position to be available")
+	}
+
+	distance := math.Sqrt(position.X*position.X + position.Z*position.Z)
+	if distance > 800.0 {
+		t.Errorf("Expected position to be clamped inside the ring, got distance %.2f", distance)
+	}
+	if distance < 799.0 {
+		t.Errorf("Expected position to be clamped to the ring boundary, got distance %.2f", distance)
+	}
+
+	// The clamp should keep the direction from the center and the height
+	if math.Abs(position.X/position.Z-1200.0/900.0) > 1e-9 {
+		t.Errorf("Expected clamped position to keep its direction, got (%.2f, %.2f)", position.X, position.Z)
+	}
+	if position.Y != 2.0 {
+		t.Errorf("Expected height to be preserved, got %.2f", position.Y)
+	}
+
+	// A move inside the ring is applied unchanged
+	inside := types.Vector3{X: 100.0, Y: 0.0, Z: -50.0}
+	moveAction.Data.Position = &inside
+	if err := sm.HandlePlayerAction(playerId, moveAction); err != nil {
+		t.Fatalf("Failed to handle move action: %v", err)
+	}
+	if position, _ := sm.GetPlayerPosition(playerId); position != inside {
+		t.Errorf("Expected position %v, got %v", inside, position)
+	}
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	now := time.Now()
+	maxSkew := time.Minute
+
+	if err := types.ValidateTimestamp(now.Add(-time.Hour), now, maxSkew); err != types.ErrStaleTimestamp {
+		t.Errorf("Expected ErrStaleTimestamp for a message from an hour ago, got %v", err)
+	}
+	if err := types.ValidateTimestamp(now.Add(time.Hour), now, maxSkew); err != types.ErrFutureTimestamp {
+		t.Errorf("Expected ErrFutureTimestamp for a message an hour ahead, got %v", err)
+	}
+	if err := types.ValidateTimestamp(time.Time{}, now, maxSkew); err != types.ErrInvalidTimestamp {
+		t.Errorf("Expected ErrInvalidTimestamp for a missing timestamp, got %v", err)
+	}
+	if err := types.ValidateTimestamp(now.Add(-time.Second), now, maxSkew); err != nil {
+		t.Errorf("Expected a recent timestamp to be valid, got %v", err)
+	}
+	if err := types.ValidateTimestamp(now.Add(-time.Hour), now, 0); err != nil {
+		t.Errorf("Expected skew check to be disabled with zero skew, got %v", err)
+	}
+}
+
+func TestResetMatchClearsStats(t *testing.T) {
+	sm := newDeathmatch(t, time.Hour, 0)
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+	oldMatchID := sm.GetState().MatchID
+	oldSeed := sm.GetState().Seed
+
+	if err := sm.ResetMatch(); err != nil {
+		t.Fatalf("Failed to reset match: %v", err)
+	}
+
+	state := sm.GetState()
+	if state.MatchID == oldMatchID || state.Seed == oldSeed {
+		t.Errorf("Expected a new match ID and seed, got %s/%d", state.MatchID, state.Seed)
+	}
+	for id, player := range state.Players {
+		if !player.IsAlive || player.Health != 100 {
+			t.Errorf("Expected %s to be alive at full health, got alive=%v health=%d", id, player.IsAlive, player.Health)
+		}
+		if player.Kills != 0 || player.Deaths != 0 || player.Assists != 0 || player.Killstreak != 0 {
+			t.Errorf("Expected %s to have zeroed stats, got %+v", id, player)
+		}
+	}
+
+	reset := false
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeMatchReset {
+			reset = true
+		}
+	}
+	if !reset {
+		t.Error("Expected a matchReset event")
+	}
+}
+
+func TestAdvanceClampsLongStalls(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.MaxFrameTime = 250 * time.Millisecond
+
+	for _, steps := range []int{1, 4} {
+		settings.MaxCatchUpSteps = steps
+		sm := game.NewStateManagerWithSettings(settings)
+
+		before := sm.GetState().GameTime
+		sm.Advance(10 * time.Second)
+
+		advanced := sm.GetState().GameTime - before
+		limit := (time.Duration(steps) * settings.MaxFrameTime).Seconds()
+		if math.Abs(advanced-limit) > 1e-9 {
+			t.Errorf("Expected a 10s stall to advance the game by %.2fs with %d steps, got %.2fs", limit, steps, advanced)
+		}
+	}
+}
+
+func TestLobbyAllowsMovementButNotCombat(t *testing.T) {
+	sm := game.NewStateManager(10)
+	for _, id := range []string{"shooter", "target"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+
+	placePlayer(t, sm, "shooter", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "target", types.Vector3{X: 10, Y: 0, Z: 0})
+
+	target := types.Vector3{X: 10, Y: 0, Z: 0}
+	action := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &target}}
+	if err := sm.HandlePlayerAction("shooter", action); err != types.ErrCombatDisabled {
+		t.Errorf("Expected shooting in the lobby to fail with ErrCombatDisabled, got %v", err)
+	}
+	if health := sm.GetState().Players["target"].Health; health != 100 {
+		t.Errorf("Expected a lobby shot to deal no damage, got health %d", health)
+	}
+}
+
+func TestDeadPlayerCannotAct(t *testing.T) {
+	sm := newDeathmatch(t, time.Hour, 0)
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+
+	position := types.Vector3{X: 20, Y: 0, Z: 0}
+	for _, action := range []types.PlayerAction{
+		{Type: "move", Data: types.PlayerActionData{Position: &position}},
+		{Type: "shoot", Data: types.PlayerActionData{Target: &position}},
+	} {
+		if err := sm.HandlePlayerAction("victim", action); err != types.ErrPlayerDead {
+			t.Errorf("Expected %q from a dead player to fail with ErrPlayerDead, got %v", action.Type, err)
+		}
+	}
+}
+
+func TestMatchExceedingMaxDurationIsForceEnded(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.ZonePhases = nil
+	settings.MaxMatchDuration = 10 * time.Second
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"player1", "player2"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	sm.UpdateWithDelta(5)
+	if !sm.GetState().IsGameActive {
+		t.Fatal("Expected the match to still run before the cap")
+	}
+
+	sm.UpdateWithDelta(6)
+	if sm.GetState().IsGameActive {
+		t.Error("Expected the match to be force-ended past the cap")
+	}
+}
+
+func TestStartGameWithOnePlayerReportsPlayerCount(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	sm.DrainEvents()
+
+	err := sm.StartGame()
+	if !errors.Is(err, types.ErrNotEnoughPlayers) {
+		t.Fatalf("Expected ErrNotEnoughPlayers, got %v", err)
+	}
+	var notEnough *types.NotEnoughPlayersError
+	if !errors.As(err, &notEnough) || notEnough.Current != 1 || notEnough.Required != 2 {
+		t.Errorf("Expected 1 of 2 required players, got %+v", notEnough)
+	}
+	if want := "not enough players to start: 1 of 2 required"; err.Error() != want {
+		t.Errorf("Expected message %q, got %q", want, err.Error())
+	}
+
+	var failed []types.StartFailedPayload
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeStartFailed {
+			failed = append(failed, event.Payload.(types.StartFailedPayload))
+		}
+	}
+	if len(failed) != 1 || failed[0].Message != err.Error() {
+		t.Errorf("Expected players to be told why the match didn't start, got %+v", failed)
+	}
+}
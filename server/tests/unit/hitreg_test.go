@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+// newHitRegMatch starts a match in the given mode with a shooter at the origin and a target 10 units away
+func newHitRegMatch(t *testing.T, mode game.HitRegMode) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.HitRegMode = mode
+	return newShooterAndTarget(t, settings)
+}
+
+// claimHit fires at aim while reporting that the shot hit targetID at hitPoint
+func claimHit(t *testing.T, sm *game.StateManager, targetID string, aim, hitPoint types.Vector3) {
+	t.Helper()
+
+	action := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{
+		Target:   &aim,
+		TargetID: targetID,
+		HitPoint: &hitPoint,
+	}}
+	if err := sm.HandlePlayerAction("shooter", action); err != nil {
+		t.Fatalf("Failed to shoot: %v", err)
+	}
+}
+
+func targetHealth(t *testing.T, sm *game.StateManager) int {
+	t.Helper()
+
+	stats, ok := sm.GetPlayerStats("target")
+	if !ok {
+		t.Fatal("Target not found")
+	}
+	return stats.Health
+}
+
+func TestClaimedHitIsOnlyTrustedInClientAssistedMode(t *testing.T) {
+	// The shot is aimed away from the target, but the client reports it hit the target
+	aim := types.Vector3{X: 0, Y: 1.7, Z: 20}
+	hitPoint := types.Vector3{X: 10, Y: 1, Z: 0}
+
+	// The server raycasts the shot itself and ignores the claim
+	server := newHitRegMatch(t, game.HitRegServer)
+	claimHit(t, server, "target", aim, hitPoint)
+	if health := targetHealth(t, server); health != 100 {
+		t.Errorf("Expected the server-authoritative mode to ignore the claimed hit, target health %d", health)
+	}
+
+	// Client-assisted mode accepts the claim since the hit point is on the target
+	client := newHitRegMatch(t, game.HitRegClientAssisted)
+	claimHit(t, client, "target", aim, hitPoint)
+	if health := targetHealth(t, client); health >= 100 {
+		t.Errorf("Expected the client-assisted mode to accept the claimed hit, target health %d", health)
+	}
+}
+
+func TestClaimedHitFarFromTheTargetIsRejected(t *testing.T) {
+	sm := newHitRegMatch(t, game.HitRegClientAssisted)
+
+	// The claimed hit point is nowhere near where the server has the target
+	claimHit(t, sm, "target", types.Vector3{X: 0, Y: 1.7, Z: 20}, types.Vector3{X: 60, Y: 1, Z: 40})
+	if health := targetHealth(t, sm); health != 100 {
+		t.Errorf("Expected the claimed hit to be rejected, target health %d", health)
+	}
+
+	// A rejected claim falls back to the server raycast, which still registers a real hit
+	claimHit(t, sm, "target", types.Vector3{X: 10, Y: 1.2, Z: 0}, types.Vector3{X: 60, Y: 1, Z: 40})
+	if health := targetHealth(t, sm); health >= 100 {
+		t.Errorf("Expected the server raycast to hit after the claim was rejected, target health %d", health)
+	}
+}
@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+func TestMovesCoalescedPerTick(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.CoalesceMoves = true
+	settings.MaxMoveSpeed = 0 // the moves start from a random spawn point
+	sm := game.NewStateManagerWithSettings(settings)
+	if err := sm.AddPlayer("runner"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	start, _ := sm.GetPlayerPosition("runner")
+
+	// Several moves arrive between two ticks, the last one only turning the player
+	for _, x := range []float64{10, 20, 30} {
+		movePlayer(t, sm, "runner", types.Vector3{X: x, Y: 0, Z: 5})
+	}
+	rotation := types.Vector3{X: 0, Y: 1.5, Z: 0}
+	turn := types.PlayerAction{Type: "move", Data: types.PlayerActionData{Rotation: &rotation}}
+	if err := sm.HandlePlayerAction("runner", turn); err != nil {
+		t.Fatalf("Failed to turn: %v", err)
+	}
+
+	if pos, _ := sm.GetPlayerPosition("runner"); pos != start {
+		t.Fatalf("Expected moves to wait for the next tick, player moved to %+v", pos)
+	}
+
+	sm.UpdateWithDelta(0.05)
+	player := sm.GetState().Players["runner"]
+	if want := (types.Vector3{X: 30, Y: 0, Z: 5}); player.Position != want {
+		t.Errorf("Expected the latest move %+v to be applied, got %+v", want, player.Position)
+	}
+	if player.Rotation != rotation {
+		t.Errorf("Expected rotation %+v, got %+v", rotation, player.Rotation)
+	}
+}
+
+func TestFallDamageOnlyWhenEnabled(t *testing.T) {
+	for _, fallDamage := range []bool{false, true} {
+		settings := game.DefaultSettings()
+		settings.FallDamage = fallDamage
+		sm := game.NewStateManagerWithSettings(settings)
+		if err := sm.AddPlayer("jumper"); err != nil {
+			t.Fatalf("Failed to add player: %v", err)
+		}
+
+		// A drop spread over several moves counts from where it started
+		placePlayer(t, sm, "jumper", types.Vector3{})
+		for _, y := range []float64{10, 7, 3, 0} {
+			movePlayer(t, sm, "jumper", types.Vector3{X: 0, Y: y, Z: 0})
+		}
+
+		// Landing at about 15.5 units per second is 5.5 beyond the safe speed
+		health := sm.GetState().Players["jumper"].Health
+		if fallDamage && health != 45 {
+			t.Errorf("Expected a 10 unit fall to deal 55 damage, got health %d", health)
+		}
+		if !fallDamage && health != 100 {
+			t.Errorf("Expected no fall damage when disabled, got health %d", health)
+		}
+	}
+}
+
+func TestMovesFasterThanTheSpeedLimitSnapBack(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.MaxMoveSpeed = 10
+	sm := game.NewStateManagerWithSettings(settings)
+	if err := sm.AddPlayer("runner"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	// Even the first move after joining is checked
+	spawn, _ := sm.GetPlayerPosition("runner")
+	movePlayer(t, sm, "runner", types.Vector3{X: spawn.X + 50, Y: 0, Z: spawn.Z})
+	if pos, _ := sm.GetPlayerPosition("runner"); pos != spawn {
+		t.Errorf("Expected the first move away from the spawn point to be checked, got %+v", pos)
+	}
+
+	if _, err := sm.TeleportPlayer("runner", types.Vector3{}); err != nil {
+		t.Fatalf("Failed to teleport player: %v", err)
+	}
+	sm.DrainEvents()
+
+	// Covering 50 units at once is a speed hack
+	movePlayer(t, sm, "runner", types.Vector3{X: 50, Y: 0, Z: 0})
+	if pos, _ := sm.GetPlayerPosition("runner"); pos != (types.Vector3{}) {
+		t.Errorf("Expected the player to be snapped back to the origin, got %+v", pos)
+	}
+	corrected := false
+	for _, event := range sm.DrainEvents() {
+		corrected = corrected || (event.Type == types.MessageTypePositionCorrection && event.PlayerID == "runner")
+	}
+	if !corrected {
+		t.Error("Expected the player to be told their position was corrected")
+	}
+
+	// Standing still doesn't save up distance, only the last two ticks and the jitter allowance count
+	time.Sleep(500 * time.Millisecond)
+	movePlayer(t, sm, "runner", types.Vector3{X: 5, Y: 0, Z: 0})
+	if pos, _ := sm.GetPlayerPosition("runner"); pos != (types.Vector3{}) {
+		t.Errorf("Expected a long move after standing still to be snapped back, got %+v", pos)
+	}
+
+	// A step within the jitter allowance and a run at walking pace both go through
+	movePlayer(t, sm, "runner", types.Vector3{X: 1, Y: 0, Z: 0})
+	time.Sleep(100 * time.Millisecond)
+	movePlayer(t, sm, "runner", types.Vector3{X: 3, Y: 0, Z: 0})
+	if pos, _ := sm.GetPlayerPosition("runner"); pos != (types.Vector3{X: 3}) {
+		t.Errorf("Expected moves within the speed limit to be accepted, got %+v", pos)
+	}
+}
+
+func TestJumpRisesAndLandsBackOnTheGround(t *testing.T) {
+	sm := game.NewStateManagerWithSettings(game.DefaultSettings())
+	if err := sm.AddPlayer("jumper"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	placePlayer(t, sm, "jumper", types.Vector3{X: 0, Y: 0, Z: 0})
+
+	jump := types.PlayerAction{Type: "jump"}
+	if err := sm.HandlePlayerAction("jumper", jump); err != nil {
+		t.Fatalf("Failed to jump: %v", err)
+	}
+	if err := sm.HandlePlayerAction("jumper", jump); err != types.ErrAlreadyAirborne {
+		t.Errorf("Expected a second jump mid-air to be refused, got %v", err)
+	}
+
+	// Moving mid-air keeps the height of the jump arc
+	movePlayer(t, sm, "jumper", types.Vector3{X: 1, Y: 0, Z: 0})
+	peak := 0.0
+	for i := 0; i < 30; i++ {
+		sm.UpdateWithDelta(0.05)
+		pos, _ := sm.GetPlayerPosition("jumper")
+		peak = max(peak, pos.Y)
+	}
+	if peak < 1.5 || peak > 2 {
+		t.Errorf("Expected the jump to peak around 1.76 units high, got %.2f", peak)
+	}
+
+	player := sm.GetState().Players["jumper"]
+	if player.Airborne || player.Position != (types.Vector3{X: 1}) {
+		t.Errorf("Expected the player to land back on the ground, got %+v (airborne %v)", player.Position, player.Airborne)
+	}
+	if err := sm.HandlePlayerAction("jumper", jump); err != nil {
+		t.Errorf("Expected the player to jump again after landing, got %v", err)
+	}
+}
+
+func TestFallDamageFromJumpsFollowsLandingSpeed(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.FallDamage = true
+	settings.MaxMoveSpeed = 0 // the steps off the obstacles happen in one move
+	settings.Obstacles = []types.Obstacle{
+		{Min: types.Vector3{X: -5, Y: 0, Z: -5}, Max: types.Vector3{X: 5, Y: 5, Z: 5}},
+		{Min: types.Vector3{X: 20, Y: 0, Z: -5}, Max: types.Vector3{X: 30, Y: 20, Z: 5}},
+	}
+	sm := game.NewStateManagerWithSettings(settings)
+	if err := sm.AddPlayer("jumper"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	// jumpOff has the player jump from a spot and step to another one mid-air, returning their health on landing
+	jumpOff := func(from, to types.Vector3) int {
+		t.Helper()
+		if _, err := sm.TeleportPlayer("jumper", from); err != nil {
+			t.Fatalf("Failed to teleport player: %v", err)
+		}
+		if err := sm.HandlePlayerAction("jumper", types.PlayerAction{Type: "jump"}); err != nil {
+			t.Fatalf("Failed to jump: %v", err)
+		}
+		movePlayer(t, sm, "jumper", to)
+		for i := 0; i < 200 && sm.GetState().Players["jumper"].Airborne; i++ {
+			sm.UpdateWithDelta(0.02)
+		}
+		return sm.GetState().Players["jumper"].Health
+	}
+
+	// A jump on flat ground lands as fast as it took off, well below the safe speed
+	if health := jumpOff(types.Vector3{X: 10}, types.Vector3{X: 11}); health != 100 {
+		t.Errorf("Expected a jump on flat ground to be harmless, got health %d", health)
+	}
+
+	// Jumping off a 5 unit box lands at about 12.7 units per second
+	health := jumpOff(types.Vector3{Y: 5}, types.Vector3{X: 8, Y: 5})
+	if health < 68 || health > 78 {
+		t.Errorf("Expected jumping off the box to deal about 27 damage, got health %d", health)
+	}
+	sm.DrainEvents()
+
+	// Jumping off the 20 unit tower is lethal, and nobody gets the kill
+	jumpOff(types.Vector3{X: 25, Y: 20}, types.Vector3{X: 35, Y: 20})
+	player := sm.GetState().Players["jumper"]
+	if player.IsAlive {
+		t.Fatalf("Expected the fall from the tower to kill, got health %d", player.Health)
+	}
+
+	var death *types.KillEvent
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeDeath && event.PlayerID == "jumper" {
+			kill := event.Payload.(types.KillEvent)
+			death = &kill
+		}
+	}
+	if death == nil {
+		t.Fatal("Expected the jumper to get a death message")
+	}
+	if death.Cause != types.DamageCauseFall || death.KillerID != "" {
+		t.Errorf("Expected a fall death without a killer, got cause %q and killer %q", death.Cause, death.KillerID)
+	}
+}
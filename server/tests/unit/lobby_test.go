@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"testing"
+	"time"
+)
+
+// newAutoStartLobby creates a state manager that starts matches on its own after the given warmup
+func newAutoStartLobby(warmup time.Duration) *game.StateManager {
+	settings := game.DefaultSettings()
+	settings.AutoStart = true
+	settings.AutoStartWarmup = warmup
+	return game.NewStateManagerWithSettings(settings)
+}
+
+func TestAutoStartBeginsMatchWithEnoughPlayers(t *testing.T) {
+	sm := newAutoStartLobby(0)
+
+	if err := sm.AddPlayer("first"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	sm.UpdateWithDelta(0.05)
+	if sm.GetState().IsGameActive {
+		t.Fatal("Expected no match with a single player")
+	}
+
+	if err := sm.AddPlayer("second"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	sm.UpdateWithDelta(0.05)
+	if !sm.GetState().IsGameActive {
+		t.Fatal("Expected the match to start once the minimum player count was reached")
+	}
+
+	// The match goes back to the lobby when a player leaves
+	if err := sm.RemovePlayer("second"); err != nil {
+		t.Fatalf("Failed to remove player: %v", err)
+	}
+	sm.UpdateWithDelta(0.05)
+	if sm.GetState().IsGameActive {
+		t.Error("Expected the match to end when players dropped below the minimum")
+	}
+}
+
+func TestAutoStartWaitsForWarmup(t *testing.T) {
+	sm := newAutoStartLobby(5 * time.Second)
+	for _, id := range []string{"first", "second"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+
+	sm.UpdateWithDelta(4)
+	if sm.GetState().IsGameActive {
+		t.Fatal("Expected the match to wait for the warmup")
+	}
+	sm.UpdateWithDelta(1)
+	if !sm.GetState().IsGameActive {
+		t.Error("Expected the match to start once the warmup elapsed")
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"errors"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+func TestStartAndEndGameMoveThroughMatchStates(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"RIFLE"}
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"alpha", "bravo"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	target := types.Vector3{X: 50, Y: 0, Z: 0}
+	shot := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &target}}
+
+	// Players can walk around the lobby but not fight
+	if state := sm.MatchState(); state != types.MatchStateLobby {
+		t.Fatalf("Expected a new game to be in the lobby, got %s", state)
+	}
+	placePlayer(t, sm, "alpha", types.Vector3{X: 5, Y: 0, Z: 5})
+	if err := sm.HandlePlayerAction("alpha", shot); !errors.Is(err, types.ErrCombatDisabled) {
+		t.Errorf("Expected shooting in the lobby to be refused, got %v", err)
+	}
+
+	startMatch(t, sm)
+	if state := sm.MatchState(); state != types.MatchStateActive || !sm.GetState().IsGameActive {
+		t.Fatalf("Expected an active match, got %s (isGameActive=%v)", state, sm.GetState().IsGameActive)
+	}
+	if err := sm.HandlePlayerAction("alpha", shot); err != nil {
+		t.Errorf("Expected shooting during the match to be allowed, got %v", err)
+	}
+
+	sm.EndGame()
+	if state := sm.MatchState(); state != types.MatchStateEnded || sm.GetState().IsGameActive {
+		t.Fatalf("Expected the match to have ended, got %s (isGameActive=%v)", state, sm.GetState().IsGameActive)
+	}
+	if err := sm.HandlePlayerAction("alpha", shot); !errors.Is(err, types.ErrCombatDisabled) {
+		t.Errorf("Expected shooting after the match to be refused, got %v", err)
+	}
+	placePlayer(t, sm, "alpha", types.Vector3{X: 10, Y: 0, Z: 10})
+
+	// Another match can follow
+	startMatch(t, sm)
+	if state := sm.MatchState(); state != types.MatchStateActive {
+		t.Errorf("Expected the next match to be active, got %s", state)
+	}
+}
+
+func TestAutoStartWarmsUpBeforeTheMatch(t *testing.T) {
+	sm := newAutoStartLobby(time.Second)
+	for _, id := range []string{"alpha", "bravo"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+
+	sm.UpdateWithDelta(0.5)
+	if state := sm.MatchState(); state != types.MatchStateWarmup {
+		t.Fatalf("Expected the warmup to run with enough players, got %s", state)
+	}
+
+	// Losing a player during the warmup returns to the lobby
+	if err := sm.RemovePlayer("bravo"); err != nil {
+		t.Fatalf("Failed to remove bravo: %v", err)
+	}
+	sm.UpdateWithDelta(0.05)
+	if state := sm.MatchState(); state != types.MatchStateLobby {
+		t.Fatalf("Expected to be back in the lobby, got %s", state)
+	}
+
+	if err := sm.AddPlayer("bravo"); err != nil {
+		t.Fatalf("Failed to re-add bravo: %v", err)
+	}
+	sm.UpdateWithDelta(0.5)
+	sm.UpdateWithDelta(0.6)
+	if state := sm.MatchState(); state != types.MatchStateActive {
+		t.Errorf("Expected the match to start after the warmup, got %s", state)
+	}
+}
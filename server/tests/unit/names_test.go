@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+// newNamedPlayers creates three players with the given collision policy, the first one called "Ace"
+func newNamedPlayers(t *testing.T, policy game.NameCollisionPolicy) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.NameCollisionPolicy = policy
+	settings.NameChangeCooldown = 0
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"first", "second", "third"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.UpdatePlayerName("first", "Ace"); err != nil {
+		t.Fatalf("Failed to name the first player: %v", err)
+	}
+	return sm
+}
+
+func TestNameCollisionAllow(t *testing.T) {
+	sm := newNamedPlayers(t, game.NameCollisionAllow)
+
+	if err := sm.UpdatePlayerName("second", "Ace"); err != nil {
+		t.Fatalf("Expected duplicate names to be allowed, got %v", err)
+	}
+	if name, _ := sm.GetPlayerName("second"); name != "Ace" {
+		t.Errorf("Expected the second player to be called Ace, got %q", name)
+	}
+}
+
+func TestNameCollisionReject(t *testing.T) {
+	sm := newNamedPlayers(t, game.NameCollisionReject)
+
+	if err := sm.UpdatePlayerName("second", "ace"); err != types.ErrNameTaken {
+		t.Fatalf("Expected ErrNameTaken for a taken name, got %v", err)
+	}
+	if name, _ := sm.GetPlayerName("second"); name == "ace" {
+		t.Error("Expected the rejected name not to be applied")
+	}
+
+	// Keeping your own name is not a collision
+	if err := sm.UpdatePlayerName("first", "Ace"); err != nil {
+		t.Errorf("Expected a player to be able to keep their own name, got %v", err)
+	}
+}
+
+func TestNameCollisionSuffix(t *testing.T) {
+	sm := newNamedPlayers(t, game.NameCollisionSuffix)
+
+	if err := sm.UpdatePlayerName("second", "Ace"); err != nil {
+		t.Fatalf("Expected a taken name to be suffixed, got %v", err)
+	}
+	if err := sm.UpdatePlayerName("third", "Ace"); err != nil {
+		t.Fatalf("Expected a taken name to be suffixed, got %v", err)
+	}
+
+	for id, want := range map[string]string{"first": "Ace", "second": "Ace (2)", "third": "Ace (3)"} {
+		if name, _ := sm.GetPlayerName(id); name != want {
+			t.Errorf("Expected %s to be called %q, got %q", id, want, name)
+		}
+	}
+}
+
+func TestNameChangeCooldown(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.NameChangeCooldown = 50 * time.Millisecond
+	sm := game.NewStateManagerWithSettings(settings)
+	if err := sm.AddPlayer("renamer"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	if err := sm.UpdatePlayerName("renamer", "First"); err != nil {
+		t.Fatalf("Expected the first name change to apply immediately, got %v", err)
+	}
+	if err := sm.UpdatePlayerName("renamer", "Second"); err != types.ErrNameChangeTooSoon {
+		t.Errorf("Expected a rapid rename to fail with ErrNameChangeTooSoon, got %v", err)
+	}
+	if name := sm.GetState().Players["renamer"].DisplayName; name != "First" {
+		t.Errorf("Expected the rejected rename to leave the name alone, got %q", name)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := sm.UpdatePlayerName("renamer", "Third"); err != nil {
+		t.Errorf("Expected a rename after the cooldown to succeed, got %v", err)
+	}
+}
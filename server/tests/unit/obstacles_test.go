@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"math"
+	"testing"
+)
+
+// newObstacleMap creates a game with a single wall between x=20 and x=30
+func newObstacleMap(t *testing.T) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.Obstacles = []types.Obstacle{
+		{Min: types.Vector3{X: 20, Y: 0, Z: -10}, Max: types.Vector3{X: 30, Y: 5, Z: 10}},
+	}
+	settings.MaxMoveSpeed = 0 // the moves cross the wall in one step
+	sm := game.NewStateManagerWithSettings(settings)
+
+	if err := sm.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	placePlayer(t, sm, "player1", types.Vector3{X: 0, Y: 0, Z: 0})
+	return sm
+}
+
+func TestMoveStopsAtObstacleSurface(t *testing.T) {
+	sm := newObstacleMap(t)
+
+	// Walking straight through the wall stops at its surface, offset by the player radius
+	movePlayer(t, sm, "player1", types.Vector3{X: 50, Y: 0, Z: 0})
+	position, _ := sm.GetPlayerPosition("player1")
+	if math.Abs(position.X-19.5) > 1e-9 || position.Z != 0 {
+		t.Errorf("Expected player stopped at the wall surface (19.5, 0), got (%.2f, %.2f)", position.X, position.Z)
+	}
+}
+
+func TestMoveSlidesAlongObstacle(t *testing.T) {
+	sm := newObstacleMap(t)
+
+	// A diagonal move into the wall keeps the sideways part of the movement
+	movePlayer(t, sm, "player1", types.Vector3{X: 50, Y: 0, Z: 30})
+	position, _ := sm.GetPlayerPosition("player1")
+	if math.Abs(position.X-19.5) > 1e-9 || position.Z != 30 {
+		t.Errorf("Expected player to slide along the wall to (19.5, 30), got (%.2f, %.2f)", position.X, position.Z)
+	}
+}
+
+func TestMoveOverLowObstacle(t *testing.T) {
+	sm := newObstacleMap(t)
+
+	// A player above the wall isn't blocked by it
+	movePlayer(t, sm, "player1", types.Vector3{X: 0, Y: 6, Z: 0})
+	movePlayer(t, sm, "player1", types.Vector3{X: 50, Y: 6, Z: 0})
+	position, _ := sm.GetPlayerPosition("player1")
+	if position.X != 50 {
+		t.Errorf("Expected player above the wall to pass over it, got x=%.2f", position.X)
+	}
+}
+
+// newWalledDuel starts a match in the given hit registration mode with a shooter and a target on either
+// side of a wall of the given height
+func newWalledDuel(t *testing.T, mode game.HitRegMode, wallHeight float64) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.HitRegMode = mode
+	settings.Obstacles = []types.Obstacle{
+		{Min: types.Vector3{X: 4, Y: 0, Z: -5}, Max: types.Vector3{X: 5, Y: wallHeight, Z: 5}},
+	}
+	return newShooterAndTarget(t, settings)
+}
+
+func TestWallsBlockShotsWhateverTheClientClaims(t *testing.T) {
+	body := types.Vector3{X: 10, Y: 1, Z: 0}
+
+	sm := newWalledDuel(t, game.HitRegServer, 3)
+	shootAt(t, sm, "shooter", body)
+	if health := targetHealth(t, sm); health != 100 {
+		t.Errorf("Expected the wall to stop the shot, target health %d", health)
+	}
+
+	// A client leaving out that its shot hit the wall doesn't get the hit either
+	sm = newWalledDuel(t, game.HitRegClientAssisted, 3)
+	claimHit(t, sm, "target", body, body)
+	if health := targetHealth(t, sm); health != 100 {
+		t.Errorf("Expected the claimed hit through the wall to be rejected, target health %d", health)
+	}
+
+	// Shots clear a wall lower than the shooter's eyes
+	sm = newWalledDuel(t, game.HitRegServer, 0.5)
+	shootAt(t, sm, "shooter", body)
+	if health := targetHealth(t, sm); health == 100 {
+		t.Error("Expected the shot to clear the low wall")
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestEliminationPlacements(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeElimination
+	settings.StartingWeapons = []string{"SNIPER"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"alpha", "bravo", "charlie", "delta"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	sm.DrainEvents()
+
+	// delta takes out everyone else, last one first
+	killVictim(t, sm, "delta", "charlie")
+	killVictim(t, sm, "delta", "alpha")
+	killVictim(t, sm, "delta", "bravo")
+
+	state := sm.GetState()
+	want := map[string]int{"delta": 1, "bravo": 2, "alpha": 3, "charlie": 4}
+	for id, placement := range want {
+		if got := state.Players[id].Placement; got != placement {
+			t.Errorf("Expected %s to place %d, got %d", id, placement, got)
+		}
+	}
+	if order := state.Eliminations; len(order) != 3 || order[0] != "charlie" || order[1] != "alpha" || order[2] != "bravo" {
+		t.Errorf("Expected elimination order charlie, alpha, bravo, got %v", order)
+	}
+	if state.IsGameActive {
+		t.Error("Expected the match to end with a single survivor")
+	}
+	if state.Winner != "delta" {
+		t.Errorf("Expected the state to name delta the winner, got %q", state.Winner)
+	}
+
+	var matchEnd *types.MatchEndPayload
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeMatchEnd {
+			payload := event.Payload.(types.MatchEndPayload)
+			matchEnd = &payload
+		}
+	}
+	if matchEnd == nil {
+		t.Fatal("Expected a matchEnd event")
+	}
+	if matchEnd.WinnerID != "delta" {
+		t.Errorf("Expected delta to win, got %q", matchEnd.WinnerID)
+	}
+	for i, id := range []string{"delta", "bravo", "alpha", "charlie"} {
+		if standing := matchEnd.Standings[i]; standing.ID != id || standing.Placement != i+1 {
+			t.Errorf("Expected %s in place %d, got %s in place %d", id, i+1, standing.ID, standing.Placement)
+		}
+	}
+
+	// The winner stays on the state until the next match starts
+	startMatch(t, sm)
+	if winner := sm.GetState().Winner; winner != "" {
+		t.Errorf("Expected a new match to clear the winner, got %q", winner)
+	}
+}
+
+func TestLastPlayerStandingWinsWhenTheOthersLeave(t *testing.T) {
+	for _, mode := range []types.GameMode{types.GameModeElimination, types.GameModeDuel} {
+		settings := game.DefaultSettings()
+		settings.GameMode = mode
+		sm := game.NewStateManagerWithSettings(settings)
+
+		players := []string{"stayer", "leaver"}
+		if mode == types.GameModeElimination {
+			players = append(players, "quitter")
+		}
+		for _, id := range players {
+			if err := sm.AddPlayer(id); err != nil {
+				t.Fatalf("Failed to add %s: %v", id, err)
+			}
+		}
+		startMatch(t, sm)
+		sm.DrainEvents()
+
+		// Leaving with others still standing doesn't end the match
+		if mode == types.GameModeElimination {
+			if err := sm.RemovePlayer("quitter"); err != nil {
+				t.Fatalf("Failed to remove quitter: %v", err)
+			}
+			if !sm.GetState().IsGameActive {
+				t.Fatalf("Expected the %s match to go on with two players left", mode)
+			}
+		}
+
+		if err := sm.RemovePlayer("leaver"); err != nil {
+			t.Fatalf("Failed to remove leaver: %v", err)
+		}
+		state := sm.GetState()
+		if state.IsGameActive {
+			t.Errorf("Expected the %s match to end once only one player is left", mode)
+		}
+		if state.Winner != "stayer" || state.Players["stayer"].Placement != 1 {
+			t.Errorf("Expected stayer to win the %s match, got winner %q in place %d",
+				mode, state.Winner, state.Players["stayer"].Placement)
+		}
+
+		winner := ""
+		for _, event := range sm.DrainEvents() {
+			if event.Type == types.MessageTypeMatchEnd {
+				winner = event.Payload.(types.MatchEndPayload).WinnerID
+			}
+		}
+		if winner != "stayer" {
+			t.Errorf("Expected the %s matchEnd to name stayer the winner, got %q", mode, winner)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"encoding/json"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"strings"
+	"testing"
+)
+
+// playerUpdates returns the playerUpdate events waiting in the state manager's queue
+func playerUpdates(sm *game.StateManager) []types.PlayerUpdatePayload {
+	var updates []types.PlayerUpdatePayload
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypePlayerUpdate {
+			updates = append(updates, event.Payload.(types.PlayerUpdatePayload))
+		}
+	}
+	return updates
+}
+
+func TestNameChangeEmitsPlayerUpdate(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("walker"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	if updates := playerUpdates(sm); len(updates) != 1 {
+		t.Fatalf("Expected a playerUpdate when the player joined, got %d", len(updates))
+	}
+
+	if err := sm.UpdatePlayerName("walker", "Johnny"); err != nil {
+		t.Fatalf("Failed to set name: %v", err)
+	}
+	updates := playerUpdates(sm)
+	if len(updates) != 1 || updates[0].PlayerID != "walker" || updates[0].DisplayName != "Johnny" {
+		t.Fatalf("Expected a playerUpdate carrying the new name, got %+v", updates)
+	}
+
+	// Routine movement only shows up in the game state
+	placePlayer(t, sm, "walker", types.Vector3{X: 10, Y: 0, Z: 10})
+	sm.UpdateWithDelta(0.05)
+	if updates := playerUpdates(sm); len(updates) != 0 {
+		t.Errorf("Expected no playerUpdate for movement, got %+v", updates)
+	}
+}
+
+func TestGameStateOmitsPlayerInfo(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("walker"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	data, err := json.Marshal(sm.GetState())
+	if err != nil {
+		t.Fatalf("Failed to marshal state: %v", err)
+	}
+	for _, field := range []string{"displayName", "currentWeapon", "weapons"} {
+		if strings.Contains(string(data), `"`+field+`"`) {
+			t.Errorf("Expected %s to be left out of the game state, got %s", field, data)
+		}
+	}
+}
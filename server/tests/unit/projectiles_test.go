@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+// newRocketMatch starts a match where the shooter has a rocket launcher and the target stands 30 units away
+func newRocketMatch(t *testing.T) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"ROCKET"}
+	settings.ZonePhases = nil
+	sm := newShooterAndTarget(t, settings)
+	placePlayer(t, sm, "target", types.Vector3{X: 30, Y: 0, Z: 0})
+	return sm
+}
+
+func TestProjectileTravelsBeforeHitting(t *testing.T) {
+	sm := newRocketMatch(t)
+	shootAt(t, sm, "shooter", types.Vector3{X: 30, Y: 0.9, Z: 0})
+
+	if health := sm.GetState().Players["target"].Health; health != 100 {
+		t.Fatalf("Expected the projectile not to hit instantly, got health %d", health)
+	}
+	if projectiles := len(sm.GetState().Projectiles); projectiles != 1 {
+		t.Fatalf("Expected one projectile in flight, got %d", projectiles)
+	}
+
+	for i := 0; i < 10; i++ {
+		sm.UpdateWithDelta(0.1)
+	}
+	if health := sm.GetState().Players["target"].Health; health >= 100 {
+		t.Errorf("Expected the projectile to reach the standing target, got health %d", health)
+	}
+	if projectiles := len(sm.GetState().Projectiles); projectiles != 0 {
+		t.Errorf("Expected the projectile to be gone after hitting, got %d", projectiles)
+	}
+}
+
+func TestProjectileMissesTargetThatMovesAway(t *testing.T) {
+	sm := newRocketMatch(t)
+	shootAt(t, sm, "shooter", types.Vector3{X: 30, Y: 0.9, Z: 0})
+
+	// Half a second covers the 30 units, the target steps aside before then
+	sm.UpdateWithDelta(0.1)
+	placePlayer(t, sm, "target", types.Vector3{X: 30, Y: 0, Z: 10})
+	for i := 0; i < 40; i++ {
+		sm.UpdateWithDelta(0.1)
+	}
+
+	if health := sm.GetState().Players["target"].Health; health != 100 {
+		t.Errorf("Expected the target to dodge the projectile, got health %d", health)
+	}
+	if projectiles := len(sm.GetState().Projectiles); projectiles != 0 {
+		t.Errorf("Expected the projectile to expire, got %d", projectiles)
+	}
+}
+
+// throwGrenadeAtFeet has a player throw a grenade at their own feet and waits for it to go off
+func throwGrenadeAtFeet(t *testing.T, selfDamage bool) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"GRENADE"}
+	settings.ZonePhases = nil
+	settings.SelfDamage = selfDamage
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"thrower", "bystander"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "thrower", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "bystander", types.Vector3{X: 100, Y: 0, Z: 0})
+
+	shootAt(t, sm, "thrower", types.Vector3{X: 0, Y: 0, Z: 0})
+	for i := 0; i < 10; i++ {
+		sm.UpdateWithDelta(0.1)
+	}
+	if projectiles := len(sm.GetState().Projectiles); projectiles != 0 {
+		t.Fatalf("Expected the grenade to have exploded, got %d projectiles", projectiles)
+	}
+	return sm
+}
+
+func TestGrenadeDoesNotHurtThrowerWithoutSelfDamage(t *testing.T) {
+	sm := throwGrenadeAtFeet(t, false)
+
+	if health := sm.GetState().Players["thrower"].Health; health != 100 {
+		t.Errorf("Expected the thrower to be unhurt with self-damage off, got health %d", health)
+	}
+}
+
+func TestGrenadeHurtsThrowerWithSelfDamage(t *testing.T) {
+	sm := throwGrenadeAtFeet(t, true)
+
+	thrower := sm.GetState().Players["thrower"]
+	if thrower.Health >= 100 {
+		t.Errorf("Expected the thrower to be hurt with self-damage on, got health %d", thrower.Health)
+	}
+	if health := sm.GetState().Players["bystander"].Health; health != 100 {
+		t.Errorf("Expected the bystander outside the blast to be unhurt, got health %d", health)
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"finalcircle/server/config"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+// newRegenMatch starts a match where the shooter faces the target 10 units away
+func newRegenMatch(t *testing.T, regen bool) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.HealthRegen = regen
+	return newShooterAndTarget(t, settings)
+}
+
+// advance runs the game for a number of seconds in 50ms ticks
+func advance(sm *game.StateManager, seconds float64) {
+	for i := 0; i < int(seconds*20); i++ {
+		sm.UpdateWithDelta(0.05)
+	}
+}
+
+func TestHealthRegeneratesAfterGoingUnhurt(t *testing.T) {
+	sm := newRegenMatch(t, true)
+	shootAt(t, sm, "shooter", bodyOf(types.Vector3{X: 10, Y: 0, Z: 0}))
+	hurt := sm.GetState().Players["target"].Health
+	if hurt >= 100 {
+		t.Fatal("Expected the shot to hurt the target")
+	}
+
+	// Being hit again restarts the wait
+	advance(sm, 4)
+	shootAt(t, sm, "shooter", bodyOf(types.Vector3{X: 10, Y: 0, Z: 0}))
+	hurt = sm.GetState().Players["target"].Health
+	advance(sm, 4)
+	if health := sm.GetState().Players["target"].Health; health != hurt {
+		t.Errorf("Expected no healing within 5 seconds of the last hit, health went %d -> %d", hurt, health)
+	}
+
+	// 10 points a second once the 5 seconds are up, never past full health
+	advance(sm, 2)
+	if health := sm.GetState().Players["target"].Health; health < hurt+9 || health > hurt+11 {
+		t.Errorf("Expected about 10 points healed a second after the wait, health went %d -> %d", hurt, health)
+	}
+	advance(sm, 10)
+	if health := sm.GetState().Players["target"].Health; health != 100 {
+		t.Errorf("Expected the target to heal up to full health, got %d", health)
+	}
+}
+
+func TestHealthRegenIsOptional(t *testing.T) {
+	sm := newRegenMatch(t, false)
+	shootAt(t, sm, "shooter", bodyOf(types.Vector3{X: 10, Y: 0, Z: 0}))
+	hurt := sm.GetState().Players["target"].Health
+
+	advance(sm, 20)
+	if health := sm.GetState().Players["target"].Health; health != hurt {
+		t.Errorf("Expected no healing with regen off, health went %d -> %d", hurt, health)
+	}
+}
+
+func TestHealthRegenDefaultsToDeathmatchOnly(t *testing.T) {
+	for mode, regen := range map[string]bool{"elimination": false, "duel": false, "deathmatch": true} {
+		t.Setenv("GAME_MODE", mode)
+		if cfg := config.LoadConfig(); cfg.HealthRegen != regen {
+			t.Errorf("Expected health regen %v in %s, got %v", regen, mode, cfg.HealthRegen)
+		}
+	}
+
+	t.Setenv("HEALTH_REGEN", "false")
+	if cfg := config.LoadConfig(); cfg.HealthRegen {
+		t.Error("Expected HEALTH_REGEN to turn regen off in deathmatch")
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"math"
+	"testing"
+	"time"
+)
+
+// newDeathmatch creates a deathmatch with a sniper-wielding killer and a victim standing in front of them
+func newDeathmatch(t *testing.T, respawnDelay, spawnProtection time.Duration) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.StartingWeapons = []string{"SNIPER"}
+	settings.RespawnDelay = respawnDelay
+	settings.SpawnProtection = spawnProtection
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "killer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "victim", types.Vector3{X: 10, Y: 0, Z: 0})
+	return sm
+}
+
+func TestRespawnDelayIsConfigurable(t *testing.T) {
+	for _, delay := range []time.Duration{time.Second, 5 * time.Second} {
+		sm := newDeathmatch(t, delay, 0)
+		shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+
+		victim := sm.GetState().Players["victim"]
+		if victim.IsAlive {
+			t.Fatalf("Expected victim to be killed")
+		}
+
+		// Still dead just before the delay runs out
+		sm.UpdateWithDelta(delay.Seconds() - 0.1)
+		victim = sm.GetState().Players["victim"]
+		if victim.IsAlive {
+			t.Errorf("Delay %v: expected victim to still be dead before the delay elapsed", delay)
+		}
+
+		sm.UpdateWithDelta(0.2)
+		victim = sm.GetState().Players["victim"]
+		if !victim.IsAlive {
+			t.Errorf("Delay %v: expected victim to respawn once the delay elapsed", delay)
+		}
+		if victim.Health != 100 {
+			t.Errorf("Delay %v: expected respawned victim at full health, got %d", delay, victim.Health)
+		}
+	}
+}
+
+func TestZeroRespawnDelayRespawnsInstantly(t *testing.T) {
+	sm := newDeathmatch(t, 0, 0)
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+
+	victim := sm.GetState().Players["victim"]
+	if !victim.IsAlive || victim.Deaths != 1 {
+		t.Errorf("Expected victim to die and respawn instantly, got alive=%v deaths=%d", victim.IsAlive, victim.Deaths)
+	}
+}
+
+func TestSpawnProtectionBlocksDamage(t *testing.T) {
+	sm := newDeathmatch(t, 0, 2*time.Second)
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+
+	// Shoot the freshly respawned victim again
+	victimPos := types.Vector3{X: 10, Y: 0, Z: 0}
+	placePlayer(t, sm, "victim", victimPos)
+	shootAt(t, sm, "killer", bodyOf(victimPos))
+
+	victim := sm.GetState().Players["victim"]
+	if !victim.IsAlive || victim.Health != 100 {
+		t.Errorf("Expected spawn protection to block damage, got alive=%v health=%d", victim.IsAlive, victim.Health)
+	}
+
+	// Once protection wears off the victim can be hit again
+	sm.UpdateWithDelta(2.1)
+	shootAt(t, sm, "killer", bodyOf(victimPos))
+	if victim = sm.GetState().Players["victim"]; victim.Deaths != 2 {
+		t.Errorf("Expected victim to be killed after spawn protection ended, got %d deaths", victim.Deaths)
+	}
+}
+
+func TestRepeatedDeathsAtOneSpotPushRespawnAway(t *testing.T) {
+	sm := newDeathmatch(t, 0, 0)
+	campSpot := types.Vector3{X: 500, Y: 0, Z: 0}
+
+	for deaths := 1; deaths <= 4; deaths++ {
+		placePlayer(t, sm, "killer", types.Vector3{X: 490, Y: 0, Z: 0})
+		placePlayer(t, sm, "victim", campSpot)
+		shootAt(t, sm, "killer", bodyOf(campSpot))
+
+		victim := sm.GetState().Players["victim"]
+		if !victim.IsAlive || victim.Deaths != deaths {
+			t.Fatalf("Expected the victim to die and respawn instantly, got alive=%v deaths=%d", victim.IsAlive, victim.Deaths)
+		}
+
+		// Every death at the same spot after the first moves the respawn 100 units farther away
+		minDistance := float64(deaths-1) * 100
+		if distance := math.Hypot(victim.Position.X-campSpot.X, victim.Position.Z-campSpot.Z); distance < minDistance {
+			t.Errorf("Death %d: expected a respawn at least %.0f from the camp spot, got %.2f", deaths, minDistance, distance)
+		}
+	}
+}
+
+func TestRespawnWaveBringsBackPlayersTogether(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.StartingWeapons = []string{"SNIPER"}
+	settings.RespawnWaveInterval = 10 * time.Second
+	settings.SpawnProtection = 0
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "first", "second"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "killer", types.Vector3{X: 0, Y: 0, Z: 0})
+
+	// The two die seconds apart
+	sm.UpdateWithDelta(1)
+	placePlayer(t, sm, "first", types.Vector3{X: 10, Y: 0, Z: 0})
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: 1, Z: 0})
+	sm.UpdateWithDelta(4)
+	placePlayer(t, sm, "second", types.Vector3{X: 0, Y: 0, Z: 10})
+	shootAt(t, sm, "killer", types.Vector3{X: 0, Y: 1, Z: 10})
+
+	state := sm.GetState()
+	first, second := state.Players["first"], state.Players["second"]
+	if first.IsAlive || second.IsAlive {
+		t.Fatalf("Expected both players to be dead, got first=%v second=%v", first.IsAlive, second.IsAlive)
+	}
+	if math.Abs(second.RespawnIn-5) > 1e-9 {
+		t.Errorf("Expected the second player to wait 5s for the wave, got %.2f", second.RespawnIn)
+	}
+
+	// Past the first player's own respawn delay, but before the wave
+	sm.UpdateWithDelta(4.9)
+	state = sm.GetState()
+	first, second = state.Players["first"], state.Players["second"]
+	if first.IsAlive || second.IsAlive {
+		t.Errorf("Expected nobody to respawn before the wave, got first=%v second=%v", first.IsAlive, second.IsAlive)
+	}
+
+	sm.UpdateWithDelta(0.2)
+	state = sm.GetState()
+	first, second = state.Players["first"], state.Players["second"]
+	if !first.IsAlive || !second.IsAlive {
+		t.Errorf("Expected both players to respawn on the wave, got first=%v second=%v", first.IsAlive, second.IsAlive)
+	}
+}
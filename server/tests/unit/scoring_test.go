@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+// killScore returns the score the killer earns for a kill with a shot aimed at the given height
+func killScore(t *testing.T, aimHeight float64) int {
+	t.Helper()
+
+	sm := newDeathmatch(t, 0, 0)
+	shootAt(t, sm, "killer", types.Vector3{X: 10, Y: aimHeight, Z: 0})
+
+	stats, ok := sm.GetPlayerStats("killer")
+	if !ok {
+		t.Fatal("Killer not found")
+	}
+	if stats.Kills != 1 {
+		t.Fatalf("Expected the shot at height %.1f to kill, got %d kills", aimHeight, stats.Kills)
+	}
+	return stats.Score
+}
+
+func TestHeadshotKillScoresMoreThanBodyKill(t *testing.T) {
+	scoring := game.DefaultScoring()
+
+	body := killScore(t, 0.9)
+	if body != scoring.Kill {
+		t.Errorf("Expected a body kill to score %d, got %d", scoring.Kill, body)
+	}
+
+	headshot := killScore(t, 1.7)
+	if headshot != scoring.Kill+scoring.HeadshotBonus {
+		t.Errorf("Expected a headshot kill to score %d, got %d", scoring.Kill+scoring.HeadshotBonus, headshot)
+	}
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"finalcircle/server/config"
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestShieldTakesDamageBeforeHealth(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"RIFLE"}
+	settings.StartingShield = 50
+	sm := newShooterAndTarget(t, settings)
+	target := bodyOf(types.Vector3{X: 10, Y: 0, Z: 0})
+
+	// Two 25 damage rifle shots break the shield without touching health
+	for _, shield := range []int{25, 0} {
+		shootAt(t, sm, "shooter", target)
+		player := sm.GetState().Players["target"]
+		if player.Shield != shield || player.Health != 100 {
+			t.Fatalf("Expected shield %d and full health, got shield %d and health %d", shield, player.Shield, player.Health)
+		}
+	}
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeDeath {
+			t.Fatalf("Expected breaking the shield not to kill, got %+v", event.Payload)
+		}
+	}
+
+	// Then health goes, and the target only dies once it runs out
+	for i := 0; i < 4; i++ {
+		shootAt(t, sm, "shooter", target)
+	}
+	if player := sm.GetState().Players["target"]; player.IsAlive || player.Health != 0 {
+		t.Errorf("Expected the target to die once health ran out, got health %d", player.Health)
+	}
+}
+
+func TestStartingShieldDefaultsPerGameMode(t *testing.T) {
+	for mode, shield := range map[string]int{"elimination": 0, "duel": 50, "deathmatch": 50} {
+		t.Setenv("GAME_MODE", mode)
+		if cfg := config.LoadConfig(); cfg.StartingShield != shield {
+			t.Errorf("Expected a starting shield of %d in %s, got %d", shield, mode, cfg.StartingShield)
+		}
+	}
+
+	t.Setenv("STARTING_SHIELD", "0")
+	if cfg := config.LoadConfig(); cfg.StartingShield != 0 {
+		t.Error("Expected STARTING_SHIELD to turn the starting shield off in deathmatch")
+	}
+}
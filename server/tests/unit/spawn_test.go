@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateSpawnPointsMinDistance(t *testing.T) {
+	const (
+		count       = 20
+		radius      = 800.0
+		minDistance = 150.0
+	)
+
+	for seed := int64(1); seed <= 5; seed++ {
+		points := game.GenerateSpawnPoints(count, radius, minDistance, rand.New(rand.NewSource(seed)))
+
+		if len(points) != count {
+			t.Fatalf("Seed %d: expected %d spawn points, got %d", seed, count, len(points))
+		}
+
+		for i, p := range points {
+			if distance := math.Sqrt(p.X*p.X + p.Z*p.Z); distance > radius {
+				t.Errorf("Seed %d: spawn point %d is outside the circle (distance %.2f)", seed, i, distance)
+			}
+
+			for j := i + 1; j < len(points); j++ {
+				dx := p.X - points[j].X
+				dz := p.Z - points[j].Z
+				if distance := math.Sqrt(dx*dx + dz*dz); distance < minDistance {
+					t.Errorf("Seed %d: spawn points %d and %d are only %.2f apart (min %.2f)",
+						seed, i, j, distance, minDistance)
+				}
+			}
+		}
+	}
+}
+
+func TestTeamSpawnZones(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.TeamCount = 2
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for i := 0; i < 10; i++ {
+		if err := sm.AddPlayer(fmt.Sprintf("player%d", i)); err != nil {
+			t.Fatalf("Failed to add player %d: %v", i, err)
+		}
+	}
+
+	teamSizes := make(map[string]int)
+	for id, player := range sm.GetState().Players {
+		teamSizes[player.Team]++
+
+		// With two teams the circle is split into the upper and lower half
+		switch player.Team {
+		case "team1":
+			if player.Position.Z < 0 {
+				t.Errorf("Player %s on team1 spawned in team2's zone at (%.2f, %.2f)", id, player.Position.X, player.Position.Z)
+			}
+		case "team2":
+			if player.Position.Z > 0 {
+				t.Errorf("Player %s on team2 spawned in team1's zone at (%.2f, %.2f)", id, player.Position.X, player.Position.Z)
+			}
+		default:
+			t.Errorf("Player %s was assigned unexpected team %q", id, player.Team)
+		}
+	}
+
+	if teamSizes["team1"] != 5 || teamSizes["team2"] != 5 {
+		t.Errorf("Expected teams to be balanced, got %v", teamSizes)
+	}
+}
+
+func TestFreeForAllHasNoTeams(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	if team := sm.GetState().Players["player1"].Team; team != "" {
+		t.Errorf("Expected no team in free-for-all, got %q", team)
+	}
+}
+
+func TestWorldRadiusScalesSpawns(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.WorldRadius = 200
+	settings.SpawnMinDistance = 20
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("player-%d", i)
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+
+		pos, _ := sm.GetPlayerPosition(id)
+		distance := math.Sqrt(pos.X*pos.X + pos.Z*pos.Z)
+		if distance > 200*0.95 || distance < 200*0.3 {
+			t.Errorf("Expected %s to spawn within 30%%-95%% of the 200 unit world, got %.2f", id, distance)
+		}
+	}
+
+	// Moves are clamped to the smaller world too
+	placePlayer(t, sm, "player-0", types.Vector3{X: 500, Y: 0, Z: 0})
+	if pos, _ := sm.GetPlayerPosition("player-0"); pos.X > 200 {
+		t.Errorf("Expected the move to be clamped inside the 200 unit world, got %.2f", pos.X)
+	}
+}
+
+func TestSpawnPointsFileMustFitTheWorld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spawns.json")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write spawn points: %v", err)
+		}
+	}
+
+	write(`[{"position": {"x": 100, "y": 0, "z": 0}}, {"position": {"x": 0, "y": 0, "z": -150}, "weight": 2}]`)
+	points, err := game.LoadSpawnPoints(path, 200)
+	if err != nil {
+		t.Fatalf("Expected spawn points inside the world to load, got %v", err)
+	}
+	if len(points) != 2 || points[1].Weight != 2 {
+		t.Errorf("Expected both spawn points with their weights, got %+v", points)
+	}
+
+	// A spawn point past the ring wall would leave players stuck outside the play area
+	write(`[{"position": {"x": 100, "y": 0, "z": 0}}, {"position": {"x": 180, "y": 0, "z": 180}}]`)
+	if _, err := game.LoadSpawnPoints(path, 200); err == nil {
+		t.Error("Expected a spawn point outside the world to be refused")
+	}
+}
+
+// seededSpawns starts a match with the given seed and returns where each player spawned
+func seededSpawns(t *testing.T, seed int64, ids []string) []types.Vector3 {
+	t.Helper()
+
+	sm := game.NewStateManager(10)
+	for _, id := range ids {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGameWithSeed(seed); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	if got := sm.GetState().Seed; got != seed {
+		t.Errorf("Expected the game state to record seed %d, got %d", seed, got)
+	}
+
+	var spawns []types.Vector3
+	for _, id := range ids {
+		pos, _ := sm.GetPlayerPosition(id)
+		spawns = append(spawns, pos)
+	}
+	return spawns
+}
+
+func TestSameSeedGivesSameSpawns(t *testing.T) {
+	ids := []string{"alpha", "bravo", "charlie", "delta", "foxtrot"}
+
+	first := seededSpawns(t, 42, ids)
+	second := seededSpawns(t, 42, ids)
+	for i := range ids {
+		if first[i] != second[i] {
+			t.Errorf("Expected %s to spawn at %+v with the same seed, got %+v", ids[i], first[i], second[i])
+		}
+	}
+
+	other := seededSpawns(t, 7, ids)
+	same := true
+	for i := range ids {
+		if first[i] != other[i] {
+			same = false
+		}
+	}
+	if same {
+		t.Error("Expected a different seed to give different spawns")
+	}
+}
+
+func TestWeightedSpawnPointsArePickedInProportion(t *testing.T) {
+	const spawns = 3000
+	spawnPoints := []types.SpawnPoint{
+		{Position: types.Vector3{X: 100}, Weight: 1},
+		{Position: types.Vector3{X: 200}, Weight: 2},
+		{Position: types.Vector3{X: 300}, Weight: 7},
+	}
+
+	settings := game.DefaultSettings()
+	settings.MaxPlayers = spawns
+	settings.SpawnPoints = spawnPoints
+	sm := game.NewStateManagerWithSettings(settings)
+
+	counts := make(map[float64]int)
+	for i := 0; i < spawns; i++ {
+		id := fmt.Sprintf("player-%d", i)
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	for _, player := range sm.GetState().Players {
+		counts[player.Position.X]++
+	}
+
+	for _, point := range spawnPoints {
+		share := float64(counts[point.Position.X]) / spawns
+		expected := point.Weight / 10
+		if math.Abs(share-expected) > 0.03 {
+			t.Errorf("Expected spawn point at x=%.0f to get %.0f%% of spawns, got %.1f%%",
+				point.Position.X, expected*100, share*100)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestSpectateTargetFiltersState(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeElimination
+	settings.StartingWeapons = []string{"SNIPER"}
+	settings.SpectatorViewRadius = 100
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "victim", "faraway"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "faraway", types.Vector3{X: -500, Y: 0, Z: 0})
+	killVictim(t, sm, "killer", "victim")
+
+	// The eliminated player starts out following their killer
+	view, ok := sm.GetSpectatorState("victim")
+	if !ok {
+		t.Fatal("Expected a spectator view for the eliminated player")
+	}
+	if _, ok := view.Players["killer"]; !ok {
+		t.Error("Expected spectator view around the killer to include the killer")
+	}
+	if _, ok := view.Players["faraway"]; ok {
+		t.Error("Expected spectator view around the killer to exclude the faraway player")
+	}
+
+	spectate := func(targetID string) error {
+		action := types.PlayerAction{Type: "spectateTarget", Data: types.PlayerActionData{TargetID: targetID}}
+		return sm.HandlePlayerAction("victim", action)
+	}
+
+	if err := spectate("faraway"); err != nil {
+		t.Fatalf("Failed to set spectate target: %v", err)
+	}
+	view, _ = sm.GetSpectatorState("victim")
+	if _, ok := view.Players["faraway"]; !ok {
+		t.Error("Expected spectator view to include the new target")
+	}
+	if _, ok := view.Players["killer"]; ok {
+		t.Error("Expected spectator view around the faraway player to exclude the killer")
+	}
+
+	// Invalid targets are rejected
+	if err := spectate("nobody"); err != types.ErrPlayerNotFound {
+		t.Errorf("Expected ErrPlayerNotFound for an unknown target, got %v", err)
+	}
+	if err := spectate("victim"); err != types.ErrInvalidTarget {
+		t.Errorf("Expected ErrInvalidTarget for spectating yourself, got %v", err)
+	}
+
+	// Living players can't pick a spectate target
+	action := types.PlayerAction{Type: "spectateTarget", Data: types.PlayerActionData{TargetID: "faraway"}}
+	if err := sm.HandlePlayerAction("killer", action); err != types.ErrNotSpectator {
+		t.Errorf("Expected ErrNotSpectator for a living player, got %v", err)
+	}
+}
+
+func TestSpectatorAdvancesWhenTargetDies(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeElimination
+	settings.StartingWeapons = []string{"SNIPER"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"alpha", "bravo", "charlie"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	// bravo eliminates alpha, then charlie eliminates bravo: alpha should move on to follow charlie
+	killVictim(t, sm, "bravo", "alpha")
+	killVictim(t, sm, "charlie", "bravo")
+
+	if target := sm.GetState().Players["alpha"].SpectatingID; target != "charlie" {
+		t.Errorf("Expected spectator to advance to the remaining player charlie, got %q", target)
+	}
+}
+
+func TestSpectatorViewSettingControlsFarawayPlayers(t *testing.T) {
+	for _, tc := range []struct {
+		view         game.SpectatorView
+		seesWanderer bool
+	}{
+		{game.SpectatorViewNearby, false},
+		{game.SpectatorViewAll, true},
+	} {
+		settings := game.DefaultSettings()
+		settings.GameMode = types.GameModeElimination
+		settings.StartingWeapons = []string{"SNIPER"}
+		settings.SpectatorViewRadius = 100
+		settings.SpectatorView = tc.view
+		sm := game.NewStateManagerWithSettings(settings)
+
+		for _, id := range []string{"killer", "victim", "wanderer"} {
+			if err := sm.AddPlayer(id); err != nil {
+				t.Fatalf("Failed to add %s: %v", id, err)
+			}
+		}
+		startMatch(t, sm)
+		placePlayer(t, sm, "wanderer", types.Vector3{X: -500, Y: 0, Z: 0})
+		killVictim(t, sm, "killer", "victim")
+
+		view, ok := sm.GetSpectatorState("victim")
+		if !ok {
+			t.Fatal("Expected a spectator view for the eliminated player")
+		}
+		if _, ok := view.Players["wanderer"]; ok != tc.seesWanderer {
+			t.Errorf("View %q: expected the spectator to see the wanderer far away: %v, got %v", tc.view, tc.seesWanderer, ok)
+		}
+
+		// Observers follow the same rule, watching the first living player by ID
+		if _, ok := sm.GetObserverState().Players["wanderer"]; ok != tc.seesWanderer {
+			t.Errorf("View %q: expected observers to see the wanderer far away: %v, got %v", tc.view, tc.seesWanderer, ok)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestEliminatedPlayerBecomesSpectator(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.MaxPlayers = 2
+	settings.GameMode = types.GameModeElimination
+	settings.StartingWeapons = []string{"SNIPER"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	// The server is full until someone is eliminated
+	if err := sm.AddPlayer("latecomer"); err == nil {
+		t.Fatal("Expected server to be full")
+	}
+
+	victimPos := types.Vector3{X: 10, Y: 0, Z: 0}
+	placePlayer(t, sm, "killer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "victim", victimPos)
+	shootAt(t, sm, "killer", bodyOf(victimPos))
+
+	victim := sm.GetState().Players["victim"]
+	if victim.IsAlive {
+		t.Fatal("Expected victim to be killed by the sniper shot")
+	}
+	if !victim.IsSpectator {
+		t.Error("Expected eliminated player to become a spectator")
+	}
+	if victim.SpectatingID != "killer" {
+		t.Errorf("Expected spectator to follow their killer, got %q", victim.SpectatingID)
+	}
+
+	// The spectator stays connected but no longer occupies a player slot
+	if err := sm.AddPlayer("latecomer"); err != nil {
+		t.Errorf("Expected spectator's slot to be free, got %v", err)
+	}
+}
+
+func TestDeathmatchPlayerDoesNotSpectate(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.StartingWeapons = []string{"SNIPER"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	victimPos := types.Vector3{X: 10, Y: 0, Z: 0}
+	placePlayer(t, sm, "killer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "victim", victimPos)
+	shootAt(t, sm, "killer", bodyOf(victimPos))
+
+	if sm.GetState().Players["victim"].IsSpectator {
+		t.Error("Expected players in respawn modes not to become spectators")
+	}
+}
+
+func TestLateJoinerSpectatesUntilTheNextRound(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeElimination
+	settings.LateJoin = game.LateJoinSpectate
+	settings.StartingWeapons = []string{"SNIPER"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	if err := sm.AddPlayer("latecomer"); err != nil {
+		t.Fatalf("Failed to add latecomer: %v", err)
+	}
+	latecomer := sm.GetState().Players["latecomer"]
+	if latecomer.IsAlive || !latecomer.IsSpectator {
+		t.Fatalf("Expected a mid-round joiner to spectate, got alive=%v spectator=%v", latecomer.IsAlive, latecomer.IsSpectator)
+	}
+	if latecomer.SpectatingID != "killer" {
+		t.Errorf("Expected latecomer to follow the first living player, got %q", latecomer.SpectatingID)
+	}
+
+	// The spectating latecomer doesn't keep the round from ending once only one player is left
+	victimPos := types.Vector3{X: 10, Y: 0, Z: 0}
+	placePlayer(t, sm, "killer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "victim", victimPos)
+	shootAt(t, sm, "killer", bodyOf(victimPos))
+	if sm.GetState().IsGameActive {
+		t.Fatal("Expected the round to end with one player left")
+	}
+
+	startMatch(t, sm)
+	latecomer = sm.GetState().Players["latecomer"]
+	if !latecomer.IsAlive || latecomer.IsSpectator {
+		t.Errorf("Expected latecomer to play in the next round, got alive=%v spectator=%v", latecomer.IsAlive, latecomer.IsSpectator)
+	}
+}
+
+func TestLateJoinerSpawnsInDeathmatch(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.LateJoin = game.LateJoinSpectate
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"alpha", "bravo"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	if err := sm.AddPlayer("latecomer"); err != nil {
+		t.Fatalf("Failed to add latecomer: %v", err)
+	}
+	if latecomer := sm.GetState().Players["latecomer"]; !latecomer.IsAlive || latecomer.IsSpectator {
+		t.Errorf("Expected a deathmatch joiner to spawn right away, got alive=%v spectator=%v", latecomer.IsAlive, latecomer.IsSpectator)
+	}
+}
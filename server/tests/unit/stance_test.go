@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+// shootHeadHigh fires a shot that passes a standing player's head 10 units in front of the shooter
+func shootHeadHigh(t *testing.T, sm *game.StateManager) {
+	t.Helper()
+
+	// From eye level at 1.6 the ray climbs to 1.7 by the time it reaches the target
+	direction := types.Vector3{X: 10, Y: 0.1, Z: 0}
+	action := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Direction: &direction}}
+	if err := sm.HandlePlayerAction("shooter", action); err != nil {
+		t.Fatalf("Failed to shoot: %v", err)
+	}
+}
+
+func TestProneTargetDucksUnderHeadshot(t *testing.T) {
+	for _, tc := range []struct {
+		stance types.Stance
+		hit    bool
+	}{
+		{types.StanceStand, true},
+		{types.StanceProne, false},
+	} {
+		sm := newShooterAndTarget(t, game.DefaultSettings())
+
+		action := types.PlayerAction{Type: "setStance", Data: types.PlayerActionData{Stance: tc.stance}}
+		if err := sm.HandlePlayerAction("target", action); err != nil {
+			t.Fatalf("Failed to set stance %s: %v", tc.stance, err)
+		}
+		if stance := sm.GetState().Players["target"].Stance; stance != tc.stance {
+			t.Fatalf("Expected stance %s, got %s", tc.stance, stance)
+		}
+
+		shootHeadHigh(t, sm)
+
+		if hit := sm.GetState().Players["target"].Health < 100; hit != tc.hit {
+			t.Errorf("Expected hit=%v on a %s target, got %v", tc.hit, tc.stance, hit)
+		}
+	}
+}
+
+// takeStance puts a player in a stance
+func takeStance(t *testing.T, sm *game.StateManager, id string, stance types.Stance) {
+	t.Helper()
+
+	action := types.PlayerAction{Type: "setStance", Data: types.PlayerActionData{Stance: stance}}
+	if err := sm.HandlePlayerAction(id, action); err != nil {
+		t.Fatalf("Failed to set stance %s: %v", stance, err)
+	}
+}
+
+func TestCrouchingLowersTheHead(t *testing.T) {
+	for _, tc := range []struct {
+		stance types.Stance
+		damage int
+	}{
+		{types.StanceStand, 25},
+		{types.StanceCrouch, 50},
+	} {
+		sm := newShooterAndTarget(t, game.DefaultSettings())
+		takeStance(t, sm, "target", tc.stance)
+
+		// Chest high on a standing player is the head of a crouching one
+		shootAt(t, sm, "shooter", types.Vector3{X: 10, Y: 1, Z: 0})
+
+		if damage := 100 - sm.GetState().Players["target"].Health; damage != tc.damage {
+			t.Errorf("Expected a %s target to take %d damage, got %d", tc.stance, tc.damage, damage)
+		}
+	}
+}
+
+func TestMoveSpeedLimitFollowsStance(t *testing.T) {
+	for _, tc := range []struct {
+		stance   types.Stance
+		accepted bool
+	}{
+		{types.StanceCrouch, false},
+		{types.StanceStand, false},
+		{types.StanceSprint, true},
+	} {
+		settings := game.DefaultSettings()
+		settings.MaxMoveSpeed = 8
+		sm := game.NewStateManagerWithSettings(settings)
+		if err := sm.AddPlayer("runner"); err != nil {
+			t.Fatalf("Failed to add player: %v", err)
+		}
+		placePlayer(t, sm, "runner", types.Vector3{X: 0, Y: 0, Z: 0})
+		takeStance(t, sm, "runner", tc.stance)
+
+		// 3 units in 100ms, with the jitter allowance a standing player may only cover 2.4
+		time.Sleep(100 * time.Millisecond)
+		movePlayer(t, sm, "runner", types.Vector3{X: 3, Y: 0, Z: 0})
+		if pos, _ := sm.GetPlayerPosition("runner"); (pos.X == 3) != tc.accepted {
+			t.Errorf("Expected accepted=%v for a %s player, ended up at %+v", tc.accepted, tc.stance, pos)
+		}
+	}
+}
+
+func TestInvalidStanceIsRejected(t *testing.T) {
+	sm := game.NewStateManager(10)
+	if err := sm.AddPlayer("sitter"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	action := types.PlayerAction{Type: "setStance", Data: types.PlayerActionData{Stance: "sit"}}
+	if err := sm.HandlePlayerAction("sitter", action); err != types.ErrInvalidStance {
+		t.Errorf("Expected ErrInvalidStance, got %v", err)
+	}
+}
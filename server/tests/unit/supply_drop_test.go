@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+	"time"
+)
+
+func TestSupplyDropLandsAsPickup(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"PISTOL"}
+	settings.SupplyDropInterval = 10 * time.Second
+	settings.SupplyDropDelay = 5 * time.Second
+	settings.SupplyDropLoot = []string{"SNIPER"}
+	settings.SupplyDropShield = 50
+	settings.StartingShield = 70
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"looter", "camper"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+
+	// Nothing is announced before the interval
+	sm.UpdateWithDelta(9)
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeSupplyDrop {
+			t.Fatalf("Expected no supply drop before the interval, got %+v", event.Payload)
+		}
+	}
+
+	sm.UpdateWithDelta(1)
+	var drop types.SupplyDropPayload
+	found := false
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeSupplyDrop {
+			drop = event.Payload.(types.SupplyDropPayload)
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Expected a supplyDrop event once the interval elapsed")
+	}
+	if drop.WeaponID != "SNIPER" || drop.Shield != 50 || drop.LandsIn != 5 {
+		t.Errorf("Expected a SNIPER and 50 shield landing in 5s, got %s and %d in %.1fs", drop.WeaponID, drop.Shield, drop.LandsIn)
+	}
+	if len(sm.GetState().Pickups) != 0 {
+		t.Fatal("Expected the drop not to be collectable before it lands")
+	}
+
+	sm.UpdateWithDelta(5)
+	pickup, ok := sm.GetState().Pickups[drop.ID]
+	if !ok {
+		t.Fatalf("Expected pickup %s after the drop landed", drop.ID)
+	}
+	if pickup.Position != drop.Position {
+		t.Errorf("Expected pickup at the announced position %+v, got %+v", drop.Position, pickup.Position)
+	}
+
+	// Walking over the pickup collects it
+	placePlayer(t, sm, "looter", types.Vector3{X: drop.Position.X + 1, Y: drop.Position.Y, Z: drop.Position.Z})
+	movePlayer(t, sm, "looter", drop.Position)
+	looter := sm.GetState().Players["looter"]
+	if len(looter.Weapons) != 2 || looter.Weapons[1] != "SNIPER" {
+		t.Errorf("Expected the looter to pick up the SNIPER, has %v", looter.Weapons)
+	}
+	if looter.Shield != 100 {
+		t.Errorf("Expected the drop's shield to top the looter up to the 100 maximum, got %d", looter.Shield)
+	}
+	if len(sm.GetState().Pickups) != 0 {
+		t.Error("Expected the pickup to be removed once collected")
+	}
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestTeamReachingTheWinTargetWinsTheMatch(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.TeamCount = 2
+	settings.TeamWinTarget = 2
+	settings.StartingWeapons = []string{"SNIPER"}
+	settings.SpawnProtection = 0
+	sm := game.NewStateManagerWithSettings(settings)
+
+	// Players join alternating between the two teams
+	for _, id := range []string{"alpha1", "bravo1", "alpha2", "bravo2"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "alpha1", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "bravo1", types.Vector3{X: 10, Y: 0, Z: 0})
+	placePlayer(t, sm, "alpha2", types.Vector3{X: 20, Y: 0, Z: 0})
+	placePlayer(t, sm, "bravo2", types.Vector3{X: 0, Y: 0, Z: 10})
+	alphaTeam := sm.GetState().Players["alpha1"].Team
+	bravoTeam := sm.GetState().Players["bravo1"].Team
+	if alphaTeam == bravoTeam || sm.GetState().Players["alpha2"].Team != alphaTeam {
+		t.Fatalf("Expected alphas and bravos on opposing teams, got %q and %q", alphaTeam, bravoTeam)
+	}
+
+	// Both teams score a kill, nobody has reached the target yet
+	shootAt(t, sm, "bravo1", types.Vector3{X: 20, Y: 1, Z: 0})
+	shootAt(t, sm, "alpha1", types.Vector3{X: 10, Y: 1, Z: 0})
+	sm.UpdateWithDelta(0.05)
+	teams := sm.GetState().Teams
+	if teams[alphaTeam].Kills != 1 || teams[bravoTeam].Kills != 1 {
+		t.Fatalf("Expected both teams to have 1 kill, got %+v", teams)
+	}
+	if !sm.GetState().IsGameActive {
+		t.Fatal("Expected the match to go on before a team reached the target")
+	}
+	sm.DrainEvents()
+
+	// The second alpha kill reaches the target
+	shootAt(t, sm, "alpha1", types.Vector3{X: 0, Y: 1, Z: 10})
+	sm.UpdateWithDelta(0.05)
+	if sm.GetState().IsGameActive {
+		t.Fatal("Expected the match to end once a team reached the target")
+	}
+
+	var matchEnd *types.MatchEndPayload
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeMatchEnd {
+			payload := event.Payload.(types.MatchEndPayload)
+			matchEnd = &payload
+		}
+	}
+	if matchEnd == nil {
+		t.Fatal("Expected a matchEnd event")
+	}
+	if matchEnd.WinningTeam != alphaTeam {
+		t.Errorf("Expected team %s to win, got %q", alphaTeam, matchEnd.WinningTeam)
+	}
+	if matchEnd.MVPID != "alpha1" {
+		t.Errorf("Expected alpha1 to be the MVP, got %q", matchEnd.MVPID)
+	}
+	if matchEnd.Teams[alphaTeam].Kills != 2 {
+		t.Errorf("Expected the winning team to have 2 kills, got %+v", matchEnd.Teams)
+	}
+}
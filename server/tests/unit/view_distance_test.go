@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"testing"
+)
+
+func TestPlayersBeyondMaxViewDistanceAreOmitted(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.MaxViewDistance = 100
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"viewer", "nearby", "faraway"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	placePlayer(t, sm, "viewer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "nearby", types.Vector3{X: 60, Y: 0, Z: 60})
+	placePlayer(t, sm, "faraway", types.Vector3{X: 0, Y: 0, Z: -150})
+
+	view, ok := sm.GetPlayerView("viewer", 0)
+	if !ok {
+		t.Fatal("Expected a limited view with a max view distance set")
+	}
+	if _, ok := view.Players["faraway"]; ok {
+		t.Error("Expected a player beyond the max view distance to be omitted")
+	}
+	for _, id := range []string{"viewer", "nearby"} {
+		if _, ok := view.Players[id]; !ok {
+			t.Errorf("Expected %s to be in the viewer's state", id)
+		}
+	}
+
+	// The full state still has everyone
+	if len(sm.GetState().Players) != 3 {
+		t.Errorf("Expected the full state to keep all players, got %d", len(sm.GetState().Players))
+	}
+}
+
+func TestNoViewLimitByDefault(t *testing.T) {
+	sm := game.NewStateManagerWithSettings(game.DefaultSettings())
+	if err := sm.AddPlayer("viewer"); err != nil {
+		t.Fatalf("Failed to add viewer: %v", err)
+	}
+
+	if _, ok := sm.GetPlayerView("viewer", 0); ok {
+		t.Error("Expected players to receive the full state without a max view distance")
+	}
+}
+
+func TestDistantPlayersAreSentLessOften(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.PriorityRadius = 50
+	settings.DistantUpdateEvery = 3
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"viewer", "nearby", "faraway"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	placePlayer(t, sm, "viewer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "nearby", types.Vector3{X: 20, Y: 0, Z: 20})
+	placePlayer(t, sm, "faraway", types.Vector3{X: 0, Y: 0, Z: -300})
+
+	sent := map[string]int{}
+	for broadcast := uint64(0); broadcast < 9; broadcast++ {
+		view, ok := sm.GetPlayerView("viewer", broadcast)
+		if !ok {
+			t.Fatal("Expected a prioritized view with a priority radius set")
+		}
+		for id := range view.Players {
+			sent[id]++
+		}
+		if _, ok := view.Players["faraway"]; !ok && (len(view.Deferred) != 1 || view.Deferred[0] != "faraway") {
+			t.Errorf("Broadcast %d: expected the left out player to be listed as deferred, got %v", broadcast, view.Deferred)
+		}
+	}
+
+	if sent["viewer"] != 9 || sent["nearby"] != 9 {
+		t.Errorf("Expected the viewer and nearby player in every broadcast, got %v", sent)
+	}
+	if sent["faraway"] != 3 {
+		t.Errorf("Expected the distant player in every third broadcast, got %d of 9", sent["faraway"])
+	}
+}
+
+func TestPlayersInCombatAreAlwaysSent(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.PriorityRadius = 50
+	settings.DistantUpdateEvery = 3
+	settings.StartingWeapons = []string{"SNIPER"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"viewer", "sniper"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "viewer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "sniper", types.Vector3{X: 0, Y: 0, Z: -150})
+
+	if view, _ := sm.GetPlayerView("viewer", 1); view.Players["sniper"] != nil {
+		t.Fatal("Expected the distant sniper to be left out before the fight")
+	}
+
+	// Once the sniper hits the viewer, the two are fighting and the sniper is sent every time
+	shootAt(t, sm, "sniper", types.Vector3{X: 0, Y: 0.5, Z: 0})
+	if health := sm.GetState().Players["viewer"].Health; health == 100 {
+		t.Fatal("Expected the sniper to hit the viewer")
+	}
+	if view, _ := sm.GetPlayerView("viewer", 1); view.Players["sniper"] == nil {
+		t.Error("Expected a player fighting the viewer to be sent in every broadcast")
+	}
+}
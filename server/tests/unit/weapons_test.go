@@ -0,0 +1,512 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"math"
+	"testing"
+	"time"
+)
+
+// placePlayer teleports a player to the given position, a move action that far would trip the speed check
+func placePlayer(t *testing.T, sm *game.StateManager, id string, position types.Vector3) {
+	t.Helper()
+
+	if _, err := sm.TeleportPlayer(id, position); err != nil {
+		t.Fatalf("Failed to place player %s: %v", id, err)
+	}
+}
+
+// movePlayer moves a player to the given position through a move action
+func movePlayer(t *testing.T, sm *game.StateManager, id string, position types.Vector3) {
+	t.Helper()
+
+	action := types.PlayerAction{Type: "move", Data: types.PlayerActionData{Position: &position}}
+	if err := sm.HandlePlayerAction(id, action); err != nil {
+		t.Fatalf("Failed to move player %s: %v", id, err)
+	}
+}
+
+// startMatch starts the match so players can fight, everyone respawns so place players afterwards
+func startMatch(t *testing.T, sm *game.StateManager) {
+	t.Helper()
+
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+}
+
+// newShooterAndTarget starts a match with the given settings between a shooter at the origin and a target
+// 10 units away along +X
+func newShooterAndTarget(t *testing.T, settings game.Settings) *game.StateManager {
+	t.Helper()
+
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"shooter", "target"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "shooter", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "target", types.Vector3{X: 10, Y: 0, Z: 0})
+	return sm
+}
+
+// shootAt fires the shooter's current weapon at the target position
+func shootAt(t *testing.T, sm *game.StateManager, shooterId string, target types.Vector3) {
+	t.Helper()
+
+	action := types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &target}}
+	if err := sm.HandlePlayerAction(shooterId, action); err != nil {
+		t.Fatalf("Failed to shoot: %v", err)
+	}
+}
+
+// bodyOf is where to aim to hit a player standing at position in the body, shots at the feet hit the legs
+func bodyOf(position types.Vector3) types.Vector3 {
+	position.Y += 1
+	return position
+}
+
+func TestSwitchWeaponChangesShotDamage(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.WeaponSwitchCooldown = 0
+	sm := newShooterAndTarget(t, settings)
+
+	targetPos := types.Vector3{X: 10, Y: 0, Z: 0}
+
+	rifle, _ := game.LookupWeapon("RIFLE")
+	pistol, _ := game.LookupWeapon("PISTOL")
+
+	// The first starting weapon is selected
+	if current := sm.GetState().Players["shooter"].CurrentWeapon; current != "RIFLE" {
+		t.Fatalf("Expected RIFLE to be selected, got %s", current)
+	}
+
+	shootAt(t, sm, "shooter", bodyOf(targetPos))
+	expectedHealth := 100 - rifle.Damage
+	if health := sm.GetState().Players["target"].Health; health != expectedHealth {
+		t.Errorf("Expected health %d after rifle shot, got %d", expectedHealth, health)
+	}
+
+	switchAction := types.PlayerAction{Type: "switchWeapon", Data: types.PlayerActionData{WeaponID: "PISTOL"}}
+	if err := sm.HandlePlayerAction("shooter", switchAction); err != nil {
+		t.Fatalf("Failed to switch weapon: %v", err)
+	}
+
+	shootAt(t, sm, "shooter", bodyOf(targetPos))
+	expectedHealth -= pistol.Damage
+	if health := sm.GetState().Players["target"].Health; health != expectedHealth {
+		t.Errorf("Expected health %d after pistol shot, got %d", expectedHealth, health)
+	}
+}
+
+func TestSwitchWeaponValidation(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"PISTOL", "KNIFE"}
+	settings.WeaponSwitchCooldown = time.Hour
+	sm := game.NewStateManagerWithSettings(settings)
+
+	if err := sm.AddPlayer("player1"); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+
+	switchTo := func(weaponID string) error {
+		action := types.PlayerAction{Type: "switchWeapon", Data: types.PlayerActionData{WeaponID: weaponID}}
+		return sm.HandlePlayerAction("player1", action)
+	}
+
+	if err := switchTo("SNIPER"); err != types.ErrWeaponNotOwned {
+		t.Errorf("Expected ErrWeaponNotOwned for a weapon not in the inventory, got %v", err)
+	}
+	if err := switchTo("BANANA"); err != types.ErrUnknownWeapon {
+		t.Errorf("Expected ErrUnknownWeapon, got %v", err)
+	}
+
+	// The first switch is immediate, the next one is on cooldown
+	if err := switchTo("KNIFE"); err != nil {
+		t.Errorf("Expected switch to KNIFE to succeed, got %v", err)
+	}
+	if err := switchTo("PISTOL"); err != types.ErrWeaponSwitchTooSoon {
+		t.Errorf("Expected ErrWeaponSwitchTooSoon, got %v", err)
+	}
+
+	// Picking up a weapon adds it to the inventory
+	if err := sm.GiveWeapon("player1", "SNIPER"); err != nil {
+		t.Fatalf("Failed to give weapon: %v", err)
+	}
+	weapons := sm.GetState().Players["player1"].Weapons
+	if len(weapons) != 3 || weapons[2] != "SNIPER" {
+		t.Errorf("Expected SNIPER to be added to the inventory, got %v", weapons)
+	}
+}
+
+func TestAssistCreditedToOtherAttackers(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"PISTOL"}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"killer", "helper", "victim"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "killer", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "helper", types.Vector3{X: 0, Y: 0, Z: 10})
+	placePlayer(t, sm, "victim", types.Vector3{X: 10, Y: 0, Z: 0})
+
+	victimPos := types.Vector3{X: 10, Y: 0, Z: 0}
+	shootAt(t, sm, "helper", bodyOf(victimPos))
+	for i := 0; i < 4; i++ {
+		shootAt(t, sm, "killer", bodyOf(victimPos))
+	}
+
+	killer, _ := sm.GetPlayerStats("killer")
+	helper, _ := sm.GetPlayerStats("helper")
+	victim, _ := sm.GetPlayerStats("victim")
+	if victim.IsAlive {
+		t.Fatalf("Expected the victim to be dead, health %d", victim.Health)
+	}
+	if killer.Kills != 1 || killer.Assists != 0 {
+		t.Errorf("Expected the killer to get the kill and no assist, got %d kills and %d assists", killer.Kills, killer.Assists)
+	}
+	if helper.Kills != 0 || helper.Assists != 1 {
+		t.Errorf("Expected the helper to get an assist, got %d kills and %d assists", helper.Kills, helper.Assists)
+	}
+}
+
+// longRangeHits fires a rifle at a target 90 units away, near the end of its range, letting pause seconds pass between shots,
+// and returns how many shots landed
+func longRangeHits(t *testing.T, shots int, pause float64) int {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.GameMode = types.GameModeDeathmatch
+	settings.StartingWeapons = []string{"RIFLE"}
+	settings.RespawnDelay = 0
+	settings.SpawnProtection = 0
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"shooter", "target"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGameWithSeed(42); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+
+	targetPos := types.Vector3{X: 90, Y: 0, Z: 0}
+	placePlayer(t, sm, "shooter", types.Vector3{X: 0, Y: 0, Z: 0})
+
+	hits := 0
+	for i := 0; i < shots; i++ {
+		placePlayer(t, sm, "target", targetPos)
+		target := sm.GetState().Players["target"]
+		health, deaths := target.Health, target.Deaths
+
+		shootAt(t, sm, "shooter", targetPos)
+		target = sm.GetState().Players["target"]
+		if target.Health < health || target.Deaths > deaths {
+			hits++
+		}
+		if pause > 0 {
+			sm.UpdateWithDelta(pause)
+		}
+	}
+	return hits
+}
+
+func TestSustainedFireIsLessReliableThanTapping(t *testing.T) {
+	tapped := longRangeHits(t, 30, 1)
+	sprayed := longRangeHits(t, 30, 0)
+
+	if tapped != 30 {
+		t.Errorf("Expected every tapped shot to land, got %d/30", tapped)
+	}
+	if sprayed >= tapped*3/4 {
+		t.Errorf("Expected sustained fire to land clearly fewer shots than tapping, got %d vs %d", sprayed, tapped)
+	}
+}
+
+// lineUpTargets puts three players in a row in front of the shooter and fires the shooter's weapon along it,
+// returning the damage each target took, nearest first
+func lineUpTargets(t *testing.T, weapon string) []int {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{weapon}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	targets := []string{"front", "middle", "back"}
+	for _, id := range append([]string{"shooter"}, targets...) {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "shooter", types.Vector3{X: 0, Y: 0, Z: 0})
+	for i, id := range targets {
+		placePlayer(t, sm, id, types.Vector3{X: float64(10 * (i + 1)), Y: 0, Z: 0})
+	}
+	shootAt(t, sm, "shooter", types.Vector3{X: 30, Y: 1, Z: 0})
+
+	damage := make([]int, len(targets))
+	for i, id := range targets {
+		damage[i] = 100 - sm.GetState().Players[id].Health
+	}
+	return damage
+}
+
+func TestPenetratingShotHitsLinedUpPlayers(t *testing.T) {
+	damage := lineUpTargets(t, "RIFLE")
+
+	rifle, _ := game.LookupWeapon("RIFLE")
+	if damage[0] != rifle.Damage {
+		t.Errorf("Expected the first player to take the full %d damage, got %d", rifle.Damage, damage[0])
+	}
+	if !(damage[0] > damage[1] && damage[1] > damage[2] && damage[2] > 0) {
+		t.Errorf("Expected all three players hit with decreasing damage, got %v", damage)
+	}
+}
+
+func TestNonPenetratingShotStopsAtFirstPlayer(t *testing.T) {
+	damage := lineUpTargets(t, "PISTOL")
+
+	pistol, _ := game.LookupWeapon("PISTOL")
+	if damage[0] != pistol.Damage || damage[1] != 0 || damage[2] != 0 {
+		t.Errorf("Expected only the first player to be hit for %d, got %v", pistol.Damage, damage)
+	}
+}
+
+func TestEquidistantVictimsResolveTheSameWayEveryRun(t *testing.T) {
+	// Map iteration order changes between runs, so repeat the shot to catch an unstable choice
+	for run := 0; run < 20; run++ {
+		settings := game.DefaultSettings()
+		settings.StartingWeapons = []string{"SMG"}
+		sm := game.NewStateManagerWithSettings(settings)
+		for _, id := range []string{"shooter", "right", "left"} {
+			if err := sm.AddPlayer(id); err != nil {
+				t.Fatalf("Failed to add %s: %v", id, err)
+			}
+		}
+		startMatch(t, sm)
+		placePlayer(t, sm, "shooter", types.Vector3{X: 0, Y: 0, Z: 0})
+		placePlayer(t, sm, "left", types.Vector3{X: 10, Y: 0, Z: -0.2})
+		placePlayer(t, sm, "right", types.Vector3{X: 10, Y: 0, Z: 0.2})
+		shootAt(t, sm, "shooter", types.Vector3{X: 10, Y: 1, Z: 0})
+
+		players := sm.GetState().Players
+		if players["left"].Health != 85 || players["right"].Health != 100 {
+			t.Fatalf("Run %d: expected the shot to hit left, the lower ID, got left %d and right %d health",
+				run, players["left"].Health, players["right"].Health)
+		}
+	}
+}
+
+// shotDamage fires the weapon once at a target standing the given distance away and returns the damage dealt
+func shotDamage(t *testing.T, weapon string, distance float64) int {
+	t.Helper()
+
+	return aimedShotDamage(t, weapon, distance, 1)
+}
+
+// aimedShotDamage fires the weapon once at the given height on a target standing the given distance away
+// and returns the damage dealt
+func aimedShotDamage(t *testing.T, weapon string, distance, height float64) int {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{weapon}
+	sm := newShooterAndTarget(t, settings)
+	placePlayer(t, sm, "target", types.Vector3{X: distance, Y: 0, Z: 0})
+	shootAt(t, sm, "shooter", types.Vector3{X: distance, Y: height, Z: 0})
+	return 100 - sm.GetState().Players["target"].Health
+}
+
+func TestShotsBeyondWeaponRangeMiss(t *testing.T) {
+	if damage := shotDamage(t, "PISTOL", 35); damage == 0 {
+		t.Error("Expected a pistol to hit within its range")
+	}
+	if damage := shotDamage(t, "PISTOL", 60); damage != 0 {
+		t.Errorf("Expected a pistol shot beyond its 40 unit range to miss, got %d damage", damage)
+	}
+}
+
+func TestDamageFallsOffWithDistance(t *testing.T) {
+	for _, weapon := range []string{"RIFLE", "SMG", "PISTOL", "SHOTGUN"} {
+		stats, _ := game.LookupWeapon(weapon)
+		full := shotDamage(t, weapon, stats.FalloffStart/2)
+		if stats.Pellets > 1 {
+			full /= stats.Pellets
+		}
+		if full != stats.Damage {
+			t.Errorf("Expected a %s to deal full damage %d before its falloff starts, got %d", weapon, stats.Damage, full)
+		}
+	}
+
+	// Damage drops steadily past the falloff start and never below the floor
+	rifle, _ := game.LookupWeapon("RIFLE")
+	mid := shotDamage(t, "RIFLE", 75)
+	far := shotDamage(t, "RIFLE", 99)
+	floor := int(math.Round(float64(rifle.Damage) * rifle.MinDamageFraction))
+	if !(rifle.Damage > mid && mid > far && far >= floor) {
+		t.Errorf("Expected rifle damage to fall from %d toward %d with distance, got %d at 75 and %d at 99",
+			rifle.Damage, floor, mid, far)
+	}
+
+	// The sniper stays lethal at any range it reaches
+	if damage := shotDamage(t, "SNIPER", 180); damage != 100 {
+		t.Errorf("Expected a sniper to deal full damage at long range, got %d", damage)
+	}
+}
+
+func TestHitZonesScaleDamage(t *testing.T) {
+	settings := game.DefaultSettings()
+	rifle, _ := game.LookupWeapon("RIFLE")
+
+	for _, tc := range []struct {
+		zone       string
+		height     float64
+		multiplier float64
+	}{
+		{"head", 1.7, settings.HeadshotMultiplier},
+		{"body", 1.1, 1},
+		{"legs", 0.4, settings.LegshotMultiplier},
+	} {
+		expected := int(math.Round(float64(rifle.Damage) * tc.multiplier))
+		if damage := aimedShotDamage(t, "RIFLE", 10, tc.height); damage != expected {
+			t.Errorf("Expected a %s shot to deal %d damage, got %d", tc.zone, expected, damage)
+		}
+	}
+}
+
+func TestShotgunPelletsAddUpUpClose(t *testing.T) {
+	weapon, _ := game.LookupWeapon("SHOTGUN")
+	// Aimed at the middle of the body so no pellet strays into the head or legs
+	if damage := aimedShotDamage(t, "SHOTGUN", 5, 1.15); damage != weapon.Damage*weapon.Pellets {
+		t.Errorf("Expected every pellet to hit at point blank for %d damage, got %d", weapon.Damage*weapon.Pellets, damage)
+	}
+	if damage := shotDamage(t, "SHOTGUN", 40); damage != 0 {
+		t.Errorf("Expected a shotgun to do nothing beyond its range, got %d damage", damage)
+	}
+}
+
+func TestEmptyMagazineRejectsShotsUntilReloaded(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"PISTOL", "KNIFE"}
+	settings.WeaponSwitchCooldown = 0
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"shooter", "bystander"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+	placePlayer(t, sm, "shooter", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "bystander", types.Vector3{X: 0, Y: 0, Z: 20})
+
+	pistol, _ := game.LookupWeapon("PISTOL")
+	fire := func() error {
+		target := types.Vector3{X: 30, Y: 1.6, Z: 0}
+		return sm.HandlePlayerAction("shooter", types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Target: &target}})
+	}
+	reload := func() error {
+		return sm.HandlePlayerAction("shooter", types.PlayerAction{Type: "reload"})
+	}
+	shooter := sm.GetState().Players["shooter"]
+
+	if err := reload(); err != types.ErrNothingToReload {
+		t.Errorf("Expected ErrNothingToReload with a full magazine, got %v", err)
+	}
+	for i := 0; i < pistol.MagazineSize; i++ {
+		if err := fire(); err != nil {
+			t.Fatalf("Shot %d failed: %v", i+1, err)
+		}
+	}
+	if err := fire(); err != types.ErrOutOfAmmo {
+		t.Errorf("Expected ErrOutOfAmmo once the magazine is empty, got %v", err)
+	}
+
+	if err := reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if err := fire(); err != types.ErrReloading {
+		t.Errorf("Expected ErrReloading for a shot mid-reload, got %v", err)
+	}
+	if err := reload(); err != types.ErrReloading {
+		t.Errorf("Expected ErrReloading for a second reload, got %v", err)
+	}
+
+	// The magazine only fills once the reload time has passed
+	sm.UpdateWithDelta(pistol.ReloadTime / 2)
+	shooter = sm.GetState().Players["shooter"]
+	if ammo := shooter.Ammo["PISTOL"]; ammo != 0 {
+		t.Errorf("Expected an empty magazine halfway through the reload, got %d", ammo)
+	}
+	sm.UpdateWithDelta(pistol.ReloadTime / 2)
+	shooter = sm.GetState().Players["shooter"]
+	if ammo, reserve := shooter.Ammo["PISTOL"], shooter.ReserveAmmo["PISTOL"]; ammo != pistol.MagazineSize || reserve != 2*pistol.MagazineSize {
+		t.Errorf("Expected %d in the magazine and %d in reserve after reloading, got %d and %d",
+			pistol.MagazineSize, 2*pistol.MagazineSize, ammo, reserve)
+	}
+	if err := fire(); err != nil {
+		t.Errorf("Expected a shot after reloading to be accepted, got %v", err)
+	}
+
+	// Switching away abandons a reload, and the knife never runs dry
+	if err := reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if err := sm.HandlePlayerAction("shooter", types.PlayerAction{Type: "switchWeapon", Data: types.PlayerActionData{WeaponID: "KNIFE"}}); err != nil {
+		t.Fatalf("Failed to switch to the knife: %v", err)
+	}
+	if shooter.ReloadingFor != 0 {
+		t.Errorf("Expected switching weapons to cancel the reload, %.2fs left", shooter.ReloadingFor)
+	}
+	for i := 0; i < 20; i++ {
+		if err := fire(); err != nil {
+			t.Fatalf("Knife attack %d failed: %v", i+1, err)
+		}
+	}
+}
+
+func TestFireRateAllowsJitterButNotSpam(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.StartingWeapons = []string{"PISTOL"}
+	settings.EnforceFireRate = true
+	settings.FireRateTolerance = 50 * time.Millisecond
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"shooter", "bystander"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	startMatch(t, sm)
+
+	pistol, _ := game.LookupWeapon("PISTOL")
+	interval := time.Duration(float64(time.Second) / pistol.FireRate)
+	fire := func() error {
+		direction := types.Vector3{X: 1}
+		return sm.HandlePlayerAction("shooter", types.PlayerAction{Type: "shoot", Data: types.PlayerActionData{Direction: &direction}})
+	}
+
+	if err := fire(); err != nil {
+		t.Fatalf("Expected the first shot to be accepted, got %v", err)
+	}
+	if err := fire(); err != types.ErrFiringTooFast {
+		t.Errorf("Expected ErrFiringTooFast for a shot right after another, got %v", err)
+	}
+
+	// A shot arriving a little early, within the tolerance, still counts
+	time.Sleep(interval - 25*time.Millisecond)
+	if err := fire(); err != nil {
+		t.Errorf("Expected a shot within the jitter tolerance to be accepted, got %v", err)
+	}
+	if ammo := sm.GetState().Players["shooter"].Ammo["PISTOL"]; ammo != pistol.MagazineSize-2 {
+		t.Errorf("Expected the rejected shot not to use a round, %d left in the magazine", ammo)
+	}
+}
@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"finalcircle/server/game"
+	"finalcircle/server/types"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newZoneMatch starts a match with a small zone whose damage rises from the first phase to the second
+func newZoneMatch(t *testing.T) *game.StateManager {
+	t.Helper()
+
+	settings := game.DefaultSettings()
+	settings.ZonePhases = []game.ZonePhase{
+		{Duration: 60 * time.Second, RadiusFraction: 0.125, DamagePerSecond: 2},
+		{RadiusFraction: 0.125, DamagePerSecond: 10},
+	}
+	sm := game.NewStateManagerWithSettings(settings)
+
+	for _, id := range []string{"runner", "camper"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	placePlayer(t, sm, "runner", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "camper", types.Vector3{X: 5, Y: 0, Z: 0})
+	return sm
+}
+
+// zoneDamageOverSecond moves the runner out of the zone and returns the damage taken in one second
+func zoneDamageOverSecond(t *testing.T, sm *game.StateManager) int {
+	t.Helper()
+
+	placePlayer(t, sm, "runner", types.Vector3{X: 300, Y: 0, Z: 0})
+	before := sm.GetState().Players["runner"].Health
+	sm.UpdateWithDelta(1)
+	return before - sm.GetState().Players["runner"].Health
+}
+
+func TestZoneDamageIncreasesWithPhase(t *testing.T) {
+	early := newZoneMatch(t)
+	earlyDamage := zoneDamageOverSecond(t, early)
+	if phase := early.GetState().Zone.Phase; phase != 0 {
+		t.Fatalf("Expected the early match to be in phase 0, got %d", phase)
+	}
+
+	late := newZoneMatch(t)
+	late.UpdateWithDelta(61)
+	lateDamage := zoneDamageOverSecond(t, late)
+	if phase := late.GetState().Zone.Phase; phase != 1 {
+		t.Fatalf("Expected the late match to be in phase 1, got %d", phase)
+	}
+
+	if earlyDamage != 2 || lateDamage != 10 {
+		t.Errorf("Expected 2 damage early and 10 late, got %d and %d", earlyDamage, lateDamage)
+	}
+
+	// Players inside the zone are left alone
+	if health := late.GetState().Players["camper"].Health; health != 100 {
+		t.Errorf("Expected the player inside the zone to keep full health, got %d", health)
+	}
+}
+
+func TestZoneDamageKillsPlayer(t *testing.T) {
+	sm := newZoneMatch(t)
+	placePlayer(t, sm, "runner", types.Vector3{X: 300, Y: 0, Z: 0})
+
+	for i := 0; i < 60; i++ {
+		sm.UpdateWithDelta(1)
+	}
+
+	runner := sm.GetState().Players["runner"]
+	if runner.IsAlive {
+		t.Errorf("Expected the player outside the zone to die, health %d", runner.Health)
+	}
+	if runner.Deaths != 1 {
+		t.Errorf("Expected 1 death, got %d", runner.Deaths)
+	}
+}
+
+func TestZoneDeathAfterPlayerDamageCreditsAssist(t *testing.T) {
+	sm := newZoneMatch(t)
+	shootAt(t, sm, "camper", types.Vector3{X: 0, Y: 0, Z: 0})
+	if health := sm.GetState().Players["runner"].Health; health >= 100 {
+		t.Fatalf("Expected the camper to wound the runner, got health %d", health)
+	}
+
+	placePlayer(t, sm, "runner", types.Vector3{X: 300, Y: 0, Z: 0})
+	for i := 0; i < 120 && sm.GetState().Players["runner"].IsAlive; i++ {
+		sm.UpdateWithDelta(1)
+	}
+	if sm.GetState().Players["runner"].IsAlive {
+		t.Fatal("Expected the zone to kill the runner")
+	}
+
+	var death *types.KillEvent
+	for _, event := range sm.DrainEvents() {
+		if event.Type == types.MessageTypeDeath && event.PlayerID == "runner" {
+			kill := event.Payload.(types.KillEvent)
+			death = &kill
+		}
+	}
+	if death == nil {
+		t.Fatal("Expected the runner to get a death message")
+	}
+	if death.Cause != types.DamageCauseZone || death.KillerID != "" {
+		t.Errorf("Expected the zone as the killer, got cause %q and killer %q", death.Cause, death.KillerID)
+	}
+	if death.LastDamagedBy == nil || death.LastDamagedBy.AttackerID != "camper" {
+		t.Errorf("Expected the camper as the last player to damage the runner, got %+v", death.LastDamagedBy)
+	}
+	if len(death.AssistIDs) != 1 || death.AssistIDs[0] != "camper" {
+		t.Errorf("Expected an assist for the camper, got %v", death.AssistIDs)
+	}
+	if assists := sm.GetState().Players["camper"].Assists; assists != 1 {
+		t.Errorf("Expected the camper to have 1 assist, got %d", assists)
+	}
+}
+
+func TestZoneClosesInGraduallyOverItsShrinkTime(t *testing.T) {
+	settings := game.DefaultSettings()
+	settings.ZonePhases = []game.ZonePhase{
+		{Duration: 10 * time.Second, RadiusFraction: 1.0, DamagePerSecond: 1},
+		{RadiusFraction: 0.5, DamagePerSecond: 5, Shrink: 10 * time.Second},
+	}
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"runner", "camper"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+
+	// The first zone covers the whole world, like the spawn points
+	if zone := sm.GetZone(); zone.Radius != game.DefaultWorldRadius {
+		t.Fatalf("Expected the first zone to cover the %.0f unit world, got radius %.0f", game.DefaultWorldRadius, zone.Radius)
+	}
+
+	sm.UpdateWithDelta(15)
+	zone := sm.GetZone()
+	if zone.Phase != 1 || zone.TargetRadius != 400 || zone.ShrinkStart != 10 || zone.ShrinkEnd != 20 {
+		t.Fatalf("Expected phase 1 closing in to 400 between 10s and 20s, got %+v", zone)
+	}
+	if zone.Radius != 600 {
+		t.Errorf("Expected the zone halfway through closing in at radius 600, got %.1f", zone.Radius)
+	}
+
+	sm.UpdateWithDelta(10)
+	if radius := sm.GetZone().Radius; radius != 400 {
+		t.Errorf("Expected the zone to stop at its target radius 400, got %.1f", radius)
+	}
+}
+
+func TestZoneDamageDoesNotDependOnTickRate(t *testing.T) {
+	oneTick := newZoneMatch(t)
+	placePlayer(t, oneTick, "runner", types.Vector3{X: 300, Y: 0, Z: 0})
+	oneTick.UpdateWithDelta(1)
+
+	manyTicks := newZoneMatch(t)
+	placePlayer(t, manyTicks, "runner", types.Vector3{X: 300, Y: 0, Z: 0})
+	for i := 0; i < 20; i++ {
+		manyTicks.UpdateWithDelta(0.05)
+	}
+
+	coarse := oneTick.GetState().Players["runner"].Health
+	fine := manyTicks.GetState().Players["runner"].Health
+	if coarse != fine {
+		t.Errorf("Expected a second outside the zone to hurt the same at any tick rate, got %d and %d", coarse, fine)
+	}
+}
+
+func TestZoneScheduleFromFileDrivesPhasesAndResetsOnRestart(t *testing.T) {
+	// A fast match: a minute in the full circle, then two quick closes
+	path := filepath.Join(t.TempDir(), "zone.json")
+	schedule := `[
+		{"waitSeconds": 60, "radiusFraction": 1, "damagePerSecond": 1},
+		{"shrinkSeconds": 20, "waitSeconds": 40, "radiusFraction": 0.5, "damagePerSecond": 5},
+		{"shrinkSeconds": 20, "radiusFraction": 0.1, "damagePerSecond": 20}
+	]`
+	if err := os.WriteFile(path, []byte(schedule), 0o644); err != nil {
+		t.Fatalf("Failed to write schedule: %v", err)
+	}
+	phases, err := game.LoadZonePhases(path)
+	if err != nil {
+		t.Fatalf("Failed to load schedule: %v", err)
+	}
+	if len(phases) != 3 || phases[1].Duration != time.Minute || phases[1].Shrink != 20*time.Second {
+		t.Fatalf("Expected three phases, the second lasting a minute with a 20s shrink, got %+v", phases)
+	}
+
+	settings := game.DefaultSettings()
+	settings.ZonePhases = phases
+	sm := game.NewStateManagerWithSettings(settings)
+	for _, id := range []string{"runner", "camper"} {
+		if err := sm.AddPlayer(id); err != nil {
+			t.Fatalf("Failed to add %s: %v", id, err)
+		}
+	}
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	placePlayer(t, sm, "runner", types.Vector3{X: 0, Y: 0, Z: 0})
+	placePlayer(t, sm, "camper", types.Vector3{X: 5, Y: 0, Z: 0})
+
+	sm.UpdateWithDelta(90)
+	if zone := sm.GetZone(); zone.Phase != 1 || zone.Radius != 400 {
+		t.Fatalf("Expected the second phase at radius 400 after 90s, got %+v", zone)
+	}
+	sm.UpdateWithDelta(60)
+	if zone := sm.GetZone(); zone.Phase != 2 || zone.Radius != 80 || zone.DamagePerSecond != 20 {
+		t.Fatalf("Expected the last phase at radius 80 after 150s, got %+v", zone)
+	}
+
+	if err := sm.StartGame(); err != nil {
+		t.Fatalf("Failed to restart game: %v", err)
+	}
+	if zone := sm.GetZone(); zone.Phase != 0 || zone.Radius != game.DefaultWorldRadius {
+		t.Errorf("Expected a restart to reset the zone to phase 0, got %+v", zone)
+	}
+}
+
+func TestZoneScheduleRejectsInvalidRadius(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zone.json")
+	if err := os.WriteFile(path, []byte(`[{"waitSeconds": 60, "radiusFraction": 1.5}]`), 0o644); err != nil {
+		t.Fatalf("Failed to write schedule: %v", err)
+	}
+	if _, err := game.LoadZonePhases(path); err == nil {
+		t.Error("Expected a radius fraction above 1 to be rejected")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"time"
+
+	"finalcircle/server/logger"
+)
+
+// throttleRecoverySeconds is how long a room has to keep up comfortably before its broadcast rate goes back up
+const throttleRecoverySeconds = 2
+
+// broadcastThrottle lowers how often a room broadcasts its state while its ticks take longer than
+// the tick interval, down to a floor rate, and raises it again once the room keeps up. The game
+// itself keeps updating every tick, only the broadcasts are thinned out.
+type broadcastThrottle struct {
+	roomID       string
+	tickInterval time.Duration
+	tickRate     int
+
+	// every is how many ticks pass between broadcasts, 1 broadcasts every tick
+	every    int
+	maxEvery int
+	tick     int
+
+	// fastTicks counts the ticks in a row that took less than half the tick interval
+	fastTicks int
+}
+
+// newBroadcastThrottle creates a throttle for a room ticking at tickRate that never broadcasts
+// less often than minBroadcastRate times per second
+func newBroadcastThrottle(roomID string, tickRate, minBroadcastRate int) *broadcastThrottle {
+	maxEvery := 1
+	if minBroadcastRate > 0 && minBroadcastRate < tickRate {
+		maxEvery = tickRate / minBroadcastRate
+	}
+	return &broadcastThrottle{
+		roomID:       roomID,
+		tickInterval: time.Second / time.Duration(tickRate),
+		tickRate:     tickRate,
+		every:        1,
+		maxEvery:     maxEvery,
+	}
+}
+
+// due reports whether the current tick should broadcast
+func (t *broadcastThrottle) due() bool {
+	t.tick++
+	if t.tick < t.every {
+		return false
+	}
+	t.tick = 0
+	return true
+}
+
+// record takes how long a tick's update and broadcast took and adjusts the broadcast rate
+func (t *broadcastThrottle) record(took time.Duration) {
+	switch {
+	case took > t.tickInterval:
+		t.fastTicks = 0
+		if t.every >= t.maxEvery {
+			return
+		}
+		t.every = min(t.every*2, t.maxEvery)
+		logger.WarningLogger.Printf("Room %s can't keep up (tick took %v of %v), throttling broadcasts to every %v",
+			t.roomID, took, t.tickInterval, t.interval())
+
+	case took < t.tickInterval/2:
+		t.fastTicks++
+		if t.every == 1 || t.fastTicks < t.tickRate*throttleRecoverySeconds {
+			return
+		}
+		t.fastTicks = 0
+		t.every = max(t.every/2, 1)
+		if t.every == 1 {
+			logger.InfoLogger.Printf("Room %s recovered, broadcasting every tick again", t.roomID)
+		} else {
+			logger.InfoLogger.Printf("Room %s catching up, broadcasting every %v", t.roomID, t.interval())
+		}
+
+	default:
+		t.fastTicks = 0
+	}
+}
+
+// interval is the current time between two broadcasts
+func (t *broadcastThrottle) interval() time.Duration {
+	return t.tickInterval * time.Duration(t.every)
+}
@@ -0,0 +1,113 @@
+package types
+
+import "math"
+
+// CompactGameState is the short-keyed shape of a GameState sent to clients that negotiated the
+// "compact" feature. Players, which make up most of a state, are compacted further with CompactPlayer;
+// the rarely sent parts keep their usual shape.
+type CompactGameState struct {
+	Players      map[string]CompactPlayer `json:"pl"`
+	GameTime     float64                  `json:"t"`
+	MatchState   MatchState               `json:"ms"`
+	IsGameActive bool                     `json:"a"`
+	MatchID      string                   `json:"m"`
+	Seed         int64                    `json:"sd"`
+	KillFeed     []KillEvent              `json:"kf,omitempty"`
+	Zone         SafeZone                 `json:"z"`
+	Pickups      map[string]*Pickup       `json:"pu,omitempty"`
+	Projectiles  map[string]*Projectile   `json:"pr,omitempty"`
+	Eliminations []string                 `json:"el,omitempty"`
+	Winner       string                   `json:"w,omitempty"`
+	Teams        map[string]TeamScore     `json:"tm,omitempty"`
+	Deferred     []string                 `json:"df,omitempty"`
+}
+
+// CompactPlayer is the short-keyed shape of a Player, keyed by its ID in CompactGameState.Players.
+// Vectors are [x, y, z] arrays rounded to millimetres, and only the current weapon's ammo is sent.
+type CompactPlayer struct {
+	Position        [3]float64     `json:"p"`
+	Rotation        [3]float64     `json:"r"`
+	Health          int            `json:"h"`
+	Shield          int            `json:"sd,omitempty"`
+	IsAlive         bool           `json:"a"`
+	Kills           int            `json:"k"`
+	Deaths          int            `json:"d"`
+	Assists         int            `json:"as"`
+	Score           int            `json:"s"`
+	Stance          Stance         `json:"st"`
+	Killstreak      int            `json:"ks,omitempty"`
+	RespawnIn       float64        `json:"ri,omitempty"`
+	InvulnerableFor float64        `json:"iv,omitempty"`
+	Placement       int            `json:"pc,omitempty"`
+	IsSpectator     bool           `json:"sp,omitempty"`
+	SpectatingID    string         `json:"si,omitempty"`
+	Effects         []StatusEffect `json:"e,omitempty"`
+	Ammo            int            `json:"am,omitempty"`
+	ReserveAmmo     int            `json:"ra,omitempty"`
+	ReloadingFor    float64        `json:"rl,omitempty"`
+	Airborne        bool           `json:"ab,omitempty"`
+	VerticalSpeed   float64        `json:"vy,omitempty"`
+}
+
+// Compact converts a state to its compact shape
+func (state *GameState) Compact() CompactGameState {
+	players := make(map[string]CompactPlayer, len(state.Players))
+	for id, player := range state.Players {
+		players[id] = player.Compact()
+	}
+
+	return CompactGameState{
+		Players:      players,
+		GameTime:     roundCompact(state.GameTime),
+		MatchState:   state.MatchState,
+		IsGameActive: state.IsGameActive,
+		MatchID:      state.MatchID,
+		Seed:         state.Seed,
+		KillFeed:     state.KillFeed,
+		Zone:         state.Zone,
+		Pickups:      state.Pickups,
+		Projectiles:  state.Projectiles,
+		Eliminations: state.Eliminations,
+		Winner:       state.Winner,
+		Teams:        state.Teams,
+		Deferred:     state.Deferred,
+	}
+}
+
+// Compact converts a player to its compact shape
+func (player *Player) Compact() CompactPlayer {
+	return CompactPlayer{
+		Position:        compactVector(player.Position),
+		Rotation:        compactVector(player.Rotation),
+		Health:          player.Health,
+		Shield:          player.Shield,
+		IsAlive:         player.IsAlive,
+		Kills:           player.Kills,
+		Deaths:          player.Deaths,
+		Assists:         player.Assists,
+		Score:           player.Score,
+		Stance:          player.Stance,
+		Killstreak:      player.Killstreak,
+		RespawnIn:       roundCompact(player.RespawnIn),
+		InvulnerableFor: roundCompact(player.InvulnerableFor),
+		Placement:       player.Placement,
+		IsSpectator:     player.IsSpectator,
+		SpectatingID:    player.SpectatingID,
+		Effects:         player.Effects,
+		Ammo:            player.Ammo[player.CurrentWeapon],
+		ReserveAmmo:     player.ReserveAmmo[player.CurrentWeapon],
+		ReloadingFor:    roundCompact(player.ReloadingFor),
+		Airborne:        player.Airborne,
+		VerticalSpeed:   roundCompact(player.VerticalVelocity),
+	}
+}
+
+// compactVector turns a vector into a rounded [x, y, z] array
+func compactVector(v Vector3) [3]float64 {
+	return [3]float64{roundCompact(v.X), roundCompact(v.Y), roundCompact(v.Z)}
+}
+
+// roundCompact rounds to three decimals, which is all the precision clients use
+func roundCompact(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
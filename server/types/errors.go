@@ -1,17 +1,60 @@
 package types
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrInvalidMessageType  = errors.New("invalid message type")
 	ErrInvalidTimestamp    = errors.New("invalid timestamp")
+	ErrStaleTimestamp      = errors.New("timestamp too far in the past")
+	ErrFutureTimestamp     = errors.New("timestamp too far in the future")
 	ErrInvalidPayload      = errors.New("invalid payload")
 	ErrInvalidActionType   = errors.New("invalid action type")
 	ErrInvalidPlayerID     = errors.New("invalid player ID")
 	ErrInvalidPosition     = errors.New("invalid position")
 	ErrInvalidRotation     = errors.New("invalid rotation")
 	ErrGameNotActive       = errors.New("game is not active")
+	ErrNotEnoughPlayers    = errors.New("not enough players")
 	ErrPlayerNotFound      = errors.New("player not found")
 	ErrPlayerAlreadyExists = errors.New("player already exists")
+	ErrServerFull          = errors.New("server is full")
 	ErrPlayerDead          = errors.New("player is dead")
+	ErrCombatDisabled      = errors.New("combat is disabled until the match starts")
+	ErrNotSpectator        = errors.New("player is not a spectator")
+	ErrInvalidTarget       = errors.New("invalid spectate target")
+	ErrUnknownWeapon       = errors.New("unknown weapon")
+	ErrWeaponNotOwned      = errors.New("weapon not in inventory")
+	ErrWeaponSwitchTooSoon = errors.New("weapon switch on cooldown")
+	ErrInvalidStance       = errors.New("invalid stance")
+	ErrAlreadyAirborne     = errors.New("can't jump while in the air")
+	ErrShotMisaligned      = errors.New("shot direction doesn't match where the player is facing")
+	ErrFiringTooFast       = errors.New("firing faster than the weapon allows")
+	ErrOutOfAmmo           = errors.New("out of ammo, reload first")
+	ErrReloading           = errors.New("weapon is reloading")
+	ErrNothingToReload     = errors.New("nothing to reload")
+	ErrInvalidChatMessage  = errors.New("chat message must be 1 to 200 characters")
+	ErrNameTaken           = errors.New("name is already taken")
+	ErrNameChangeTooSoon   = errors.New("name changed too recently")
+	ErrRoomNotFound        = errors.New("room not found")
+	ErrMatchNotFound       = errors.New("match not found")
+	ErrRoomExists          = errors.New("room already exists")
+	ErrInvalidRoomConfig   = errors.New("invalid room configuration")
+	ErrTooManyRooms        = errors.New("room limit reached, try again later")
 )
+
+// NotEnoughPlayersError is returned when a match can't start because too few players are connected.
+// It matches ErrNotEnoughPlayers with errors.Is.
+type NotEnoughPlayersError struct {
+	Current  int
+	Required int
+}
+
+func (e *NotEnoughPlayersError) Error() string {
+	return fmt.Sprintf("not enough players to start: %d of %d required", e.Current, e.Required)
+}
+
+func (e *NotEnoughPlayersError) Unwrap() error {
+	return ErrNotEnoughPlayers
+}
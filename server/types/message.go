@@ -11,26 +11,358 @@ type Vector3 struct {
 	Z float64 `json:"z"`
 }
 
+// Obstacle is an axis-aligned box of solid map geometry
+type Obstacle struct {
+	Min Vector3 `json:"min"`
+	Max Vector3 `json:"max"`
+}
+
+// SpawnPoint is a place players spawn at. Weight biases how often it is picked relative
+// to the other spawn points, 0 counts as 1.
+type SpawnPoint struct {
+	Position Vector3 `json:"position"`
+	Weight   float64 `json:"weight,omitempty"`
+}
+
 // Player represents a player in the game
 type Player struct {
+	ID       string  `json:"id"`
+	Position Vector3 `json:"position"`
+	Rotation Vector3 `json:"rotation"`
+	Health   int     `json:"health"`
+	IsAlive  bool    `json:"isAlive"`
+	Kills    int     `json:"kills"`
+	Deaths   int     `json:"deaths"`
+	Assists  int     `json:"assists"`
+	Score    int     `json:"score"`
+	Stance   Stance  `json:"stance"`
+	// Shield takes damage from attacks before Health does, players only die once Health runs out
+	Shield int `json:"shield,omitempty"`
+
+	// PlayerInfo rarely changes, so it's sent in playerUpdate messages instead of every game state
+	PlayerInfo `json:"-"`
+
+	// Killstreak is the number of kills since the player last died
+	Killstreak int `json:"killstreak"`
+	// Attackers are the IDs of the players who damaged the player since they last spawned, for assists
+	Attackers map[string]bool `json:"-"`
+	// LastKilledBy is the ID of whoever killed the player most recently
+	LastKilledBy string `json:"-"`
+	// LastDamagedBy is the last player who damaged the player since they last spawned
+	LastDamagedBy *DamageRecord `json:"-"`
+	// LastDamageTime is the game time the player last took damage from anything, and RegenProgress
+	// the health regenerated since that doesn't make a whole point yet
+	LastDamageTime float64 `json:"-"`
+	RegenProgress  float64 `json:"-"`
+	// RecentDeaths are where and when the player died lately, to move their respawn away from spawn campers
+	RecentDeaths []DeathRecord `json:"-"`
+	// AchievementTimes holds the game time each achievement was last earned, for cooldowns
+	AchievementTimes map[string]float64 `json:"-"`
+
+	// RespawnIn is the number of seconds until a dead player respawns
+	RespawnIn float64 `json:"respawnIn,omitempty"`
+	// InvulnerableFor is the number of seconds of spawn protection left
+	InvulnerableFor float64 `json:"invulnerableFor,omitempty"`
+
+	// Placement is where the player finished an elimination match, 1 for the winner
+	Placement int `json:"placement,omitempty"`
+
+	// Disconnected players lost their connection in a ranked match and are held in place, out of reach
+	// of damage, until they reconnect or their grace period runs out
+	Disconnected bool `json:"disconnected,omitempty"`
+
+	// IsSpectator is set for eliminated players watching the rest of the match
+	IsSpectator  bool   `json:"isSpectator"`
+	SpectatingID string `json:"spectatingId,omitempty"`
+
+	LastWeaponSwitch time.Time `json:"-"`
+	LastShotTime     time.Time `json:"-"`
+	LastMoveTime     time.Time `json:"-"`
+	LastNameChange   time.Time `json:"-"`
+
+	// Muted players' chat messages are only echoed back to themselves
+	Muted bool `json:"-"`
+	// MutedFor is the number of seconds until a mute expires, 0 mutes until unmuted
+	MutedFor float64 `json:"-"`
+
+	// Spread is how far in radians the player's shots may stray after sustained fire
+	Spread float64 `json:"-"`
+
+	// Ammo is the rounds left in the magazine and ReserveAmmo the spare rounds of each weapon
+	// in the inventory that fires from one, keyed by weapon ID
+	Ammo        map[string]int `json:"ammo,omitempty"`
+	ReserveAmmo map[string]int `json:"reserveAmmo,omitempty"`
+	// ReloadingFor is the number of seconds until the current weapon's reload finishes
+	ReloadingFor float64 `json:"reloadingFor,omitempty"`
+
+	// Effects are the damage-over-time effects currently on the player
+	Effects []StatusEffect `json:"effects,omitempty"`
+
+	// Airborne is set while the player is in the middle of a jump, rising or falling at VerticalVelocity
+	// units per second
+	Airborne         bool    `json:"airborne,omitempty"`
+	VerticalVelocity float64 `json:"verticalVelocity,omitempty"`
+
+	// FallingFrom is the height a player started dropping from, 0 while they aren't falling
+	FallingFrom float64 `json:"-"`
+
+	// ZoneDamage is zone damage taken but not yet removed from Health, which only holds whole points
+	ZoneDamage float64 `json:"-"`
+	// OutsideZone is set while the player is taking zone damage
+	OutsideZone bool `json:"-"`
+}
+
+// StatusEffect is a damage-over-time effect on a player, such as a burn or poison
+type StatusEffect struct {
+	Type string `json:"type"`
+	// SourceID is the player who applied the effect and is credited with its damage, empty for the environment
+	SourceID        string  `json:"sourceId,omitempty"`
+	Remaining       float64 `json:"remaining"`
+	DamagePerSecond float64 `json:"damagePerSecond"`
+
+	// PendingDamage is damage dealt but not yet removed from Health, which only holds whole points
+	PendingDamage float64 `json:"-"`
+}
+
+// PlayerInfo holds the slow-changing fields of a player
+type PlayerInfo struct {
+	DisplayName string `json:"displayName"`
+	Team        string `json:"team,omitempty"`
+
+	// Weapons is the player's inventory of weapon IDs and CurrentWeapon the selected one
+	Weapons       []string `json:"weapons"`
+	CurrentWeapon string   `json:"currentWeapon"`
+}
+
+// PlayerUpdatePayload announces a change to a player's slow-changing fields
+type PlayerUpdatePayload struct {
+	PlayerID string `json:"playerId"`
+	PlayerInfo
+}
+
+// PlayerStats is a snapshot of a player's current stats, for profile widgets
+type PlayerStats struct {
 	ID          string  `json:"id"`
 	DisplayName string  `json:"displayName"`
-	Position    Vector3 `json:"position"`
-	Rotation    Vector3 `json:"rotation"`
-	Health      int     `json:"health"`
-	IsAlive     bool    `json:"isAlive"`
+	Team        string  `json:"team,omitempty"`
 	Kills       int     `json:"kills"`
 	Deaths      int     `json:"deaths"`
+	Assists     int     `json:"assists"`
+	Score       int     `json:"score"`
+	KD          float64 `json:"kd"`
+	Health      int     `json:"health"`
+	IsAlive     bool    `json:"isAlive"`
+	// Placement is where the player finished an elimination match, 1 for the winner, 0 while still in it
+	Placement int `json:"placement,omitempty"`
+}
+
+// MatchEndPayload announces the end of a match with the final standings, best placed first
+type MatchEndPayload struct {
+	MatchID   string        `json:"matchId"`
+	WinnerID  string        `json:"winnerId,omitempty"`
+	Standings []PlayerStats `json:"standings"`
+
+	// WinningTeam is the team that won a team match, empty on a draw, and MVPID its best player
+	WinningTeam string               `json:"winningTeam,omitempty"`
+	MVPID       string               `json:"mvpId,omitempty"`
+	Teams       map[string]TeamScore `json:"teams,omitempty"`
 }
 
+// GameMode represents the rules a match is played with
+type GameMode string
+
+const (
+	// GameModeElimination gives every player a single life per match
+	GameModeElimination GameMode = "elimination"
+	// GameModeDeathmatch respawns players after they die
+	GameModeDeathmatch GameMode = "deathmatch"
+	// GameModeDuel ends the match on the first death, the survivor wins
+	GameModeDuel GameMode = "duel"
+)
+
+// Stance is how a player is standing or moving, it decides the height of their hitbox and how fast
+// they may move
+type Stance string
+
+const (
+	StanceStand  Stance = "stand"
+	StanceCrouch Stance = "crouch"
+	StanceProne  Stance = "prone"
+	StanceSprint Stance = "sprint"
+)
+
+// DamageCause is what dealt damage to a player
+type DamageCause string
+
+const (
+	DamageCauseWeapon    DamageCause = "weapon"
+	DamageCauseHeadshot  DamageCause = "headshot"
+	DamageCauseZone      DamageCause = "zone"
+	DamageCauseEffect    DamageCause = "effect"
+	DamageCauseExplosion DamageCause = "explosion"
+	DamageCauseFall      DamageCause = "fall"
+)
+
+// HitZone is the part of a player's hitbox a shot lands in
+type HitZone string
+
+const (
+	HitZoneHead HitZone = "head"
+	HitZoneBody HitZone = "body"
+	HitZoneLegs HitZone = "legs"
+)
+
+// DamageRecord remembers who last damaged a player and at what game time
+type DamageRecord struct {
+	AttackerID string  `json:"attackerId"`
+	GameTime   float64 `json:"gameTime"`
+}
+
+// DeathRecord is where a player died and at what game time
+type DeathRecord struct {
+	Position Vector3
+	GameTime float64
+}
+
+// KillEvent represents an entry in the kill feed, it is also sent to the victim as their death message
+type KillEvent struct {
+	KillerID   string      `json:"killerId,omitempty"`
+	KillerName string      `json:"killerName,omitempty"`
+	VictimID   string      `json:"victimId"`
+	VictimName string      `json:"victimName"`
+	WeaponID   string      `json:"weaponId,omitempty"`
+	Headshot   bool        `json:"headshot,omitempty"`
+	Cause      DamageCause `json:"cause"`
+	// LastDamagedBy is the last player who hurt the victim, who may not be the killer when the zone finished them
+	LastDamagedBy *DamageRecord `json:"lastDamagedBy,omitempty"`
+	// AssistIDs are the other players who damaged the victim during this life
+	AssistIDs []string `json:"assistIds,omitempty"`
+	GameTime  float64  `json:"gameTime"`
+}
+
+// MatchState is the phase of the match lifecycle a game is in
+type MatchState string
+
+const (
+	// MatchStateLobby waits for players, nobody can fight yet
+	MatchStateLobby MatchState = "lobby"
+	// MatchStateWarmup counts down to an auto-started match once enough players are connected
+	MatchStateWarmup MatchState = "warmup"
+	// MatchStateActive is a match being played
+	MatchStateActive MatchState = "active"
+	// MatchStateEnding is a match that has been decided and is announcing its results
+	MatchStateEnding MatchState = "ending"
+	// MatchStateEnded is the time after a match until the next one starts
+	MatchStateEnded MatchState = "ended"
+)
+
 // GameState represents the current state of the game
 type GameState struct {
-	Players      map[string]*Player `json:"players"`
-	GameTime     float64            `json:"gameTime"`
-	IsGameActive bool               `json:"isGameActive"`
-	MatchID      string             `json:"matchId"`
+	Players    map[string]*Player `json:"players"`
+	GameTime   float64            `json:"gameTime"`
+	MatchState MatchState         `json:"matchState"`
+	// IsGameActive mirrors MatchState == MatchStateActive for clients that predate MatchState
+	IsGameActive bool   `json:"isGameActive"`
+	MatchID      string `json:"matchId"`
+	// Seed is the seed all of the match's randomness derives from
+	Seed     int64              `json:"seed"`
+	KillFeed []KillEvent        `json:"killFeed"`
+	Zone     SafeZone           `json:"zone"`
+	Pickups  map[string]*Pickup `json:"pickups"`
+	// Projectiles are the shots of projectile weapons still in flight
+	Projectiles map[string]*Projectile `json:"projectiles"`
+	// Eliminations are the IDs of eliminated players in the order they went out
+	Eliminations []string `json:"eliminations,omitempty"`
+	// Winner is the ID of the player who won the last match, until the next one starts
+	Winner string `json:"winner,omitempty"`
+	// Teams are the combined kills and score of each team in team modes
+	Teams map[string]TeamScore `json:"teams,omitempty"`
+	// Deferred are players left out of this state to save bandwidth who are still in the game,
+	// clients keep showing them as they last were
+	Deferred []string `json:"deferred,omitempty"`
 }
 
+// TimelineEventType is the kind of a match timeline event
+type TimelineEventType string
+
+const (
+	TimelineMatchStart TimelineEventType = "matchStart"
+	TimelineKill       TimelineEventType = "kill"
+	TimelineZonePhase  TimelineEventType = "zonePhase"
+	TimelineSupplyDrop TimelineEventType = "supplyDrop"
+	TimelineMatchEnd   TimelineEventType = "matchEnd"
+)
+
+// TimelineEvent is a significant moment of a match, kept for post-match review
+type TimelineEvent struct {
+	Type TimelineEventType `json:"type"`
+	// Time is the number of seconds since the match started
+	Time float64 `json:"time"`
+	// Details are the kill event, safe zone, supply drop or match result the event is about
+	Details interface{} `json:"details,omitempty"`
+}
+
+// TeamScore is the combined kills and score of a team's players
+type TeamScore struct {
+	Kills int `json:"kills"`
+	Score int `json:"score"`
+}
+
+// Pickup is an item lying in the world that players collect by walking over it
+type Pickup struct {
+	ID       string  `json:"id"`
+	Position Vector3 `json:"position"`
+	WeaponID string  `json:"weaponId"`
+	// Shield is added to the shield of the player picking it up
+	Shield int `json:"shield,omitempty"`
+}
+
+// Projectile is a slow shot travelling through the world until it hits a player or expires
+type Projectile struct {
+	ID       string  `json:"id"`
+	OwnerID  string  `json:"ownerId"`
+	WeaponID string  `json:"weaponId"`
+	Position Vector3 `json:"position"`
+	Velocity Vector3 `json:"velocity"`
+	Damage   int     `json:"-"`
+	// Remaining is the number of seconds until the projectile expires
+	Remaining float64 `json:"-"`
+	// Sequence orders projectiles by when they were fired
+	Sequence int `json:"-"`
+}
+
+// SafeZone is the circle players have to stay inside to avoid taking damage
+type SafeZone struct {
+	Center          Vector3 `json:"center"`
+	Radius          float64 `json:"radius"`
+	Phase           int     `json:"phase"`
+	DamagePerSecond float64 `json:"damagePerSecond"`
+	// The radius closes in from StartRadius to TargetRadius between the ShrinkStart and ShrinkEnd game times
+	StartRadius  float64 `json:"startRadius"`
+	TargetRadius float64 `json:"targetRadius"`
+	ShrinkStart  float64 `json:"shrinkStart"`
+	ShrinkEnd    float64 `json:"shrinkEnd"`
+}
+
+const (
+	// ProtocolVersion is the wire protocol version the server speaks
+	ProtocolVersion = 1
+	// MinProtocolVersion is the oldest client protocol version the server still accepts
+	MinProtocolVersion = 1
+)
+
+// ProtocolFeatures are the optional wire features the server supports, advertised in the handshake.
+// "batch" means several messages can arrive in one frame, separated by newlines.
+// "compact" sends game states as CompactGameState to clients that list it in their hello's features.
+// "latency" sends clients that list it a latency message with their round trip time after every ping.
+var ProtocolFeatures = []string{"batch", FeatureCompact, FeatureLatency}
+
+// FeatureCompact is the protocol feature for compact game states
+const FeatureCompact = "compact"
+
+// FeatureLatency is the protocol feature for latency reports
+const FeatureLatency = "latency"
+
 // MessageType represents the type of message being sent
 type MessageType string
 
@@ -43,24 +375,86 @@ const (
 	MessageTypeSetName      MessageType = "setName"
 	MessageTypeError        MessageType = "error"
 	MessageTypePlayerID     MessageType = "playerId"
+	MessageTypeHello        MessageType = "hello"
+	MessageTypeMOTD         MessageType = "motd"
+
+	MessageTypePositionCorrection MessageType = "positionCorrection"
+	MessageTypeAchievement        MessageType = "achievement"
+	MessageTypeSupplyDrop         MessageType = "supplyDrop"
+	MessageTypeMatchReset         MessageType = "matchReset"
+	MessageTypeMatchEnd           MessageType = "matchEnd"
+	MessageTypeChat               MessageType = "chat"
+	MessageTypeDeath              MessageType = "death"
+	MessageTypeStartFailed        MessageType = "startFailed"
 )
 
 // PlayerAction represents a player's action in the game
 type PlayerAction struct {
-	Type string `json:"type"`
-	Data struct {
-		Position    *Vector3 `json:"position,omitempty"`
-		Rotation    *Vector3 `json:"rotation,omitempty"`
-		Target      *Vector3 `json:"target,omitempty"`
-		Direction   *Vector3 `json:"direction,omitempty"`
-		WeaponID    string   `json:"weaponId,omitempty"`
-		HitObstacle *bool    `json:"hitObstacle,omitempty"`
-		HitPoint    *Vector3 `json:"hitPoint,omitempty"`
-		HitDistance *float64 `json:"hitDistance,omitempty"`
-		Amount      *int     `json:"amount,omitempty"`    // For healing amount
-		NewHealth   *int     `json:"newHealth,omitempty"` // New health after healing
-		Damage      *int     `json:"damage,omitempty"`    // Damage from weapon used
-	} `json:"data"`
+	Type string           `json:"type"`
+	Data PlayerActionData `json:"data"`
+}
+
+// PlayerActionData holds the optional parameters of a player action
+type PlayerActionData struct {
+	Position    *Vector3 `json:"position,omitempty"`
+	Rotation    *Vector3 `json:"rotation,omitempty"`
+	Target      *Vector3 `json:"target,omitempty"`
+	Direction   *Vector3 `json:"direction,omitempty"`
+	WeaponID    string   `json:"weaponId,omitempty"`
+	TargetID    string   `json:"targetId,omitempty"` // Player to spectate
+	HitObstacle *bool    `json:"hitObstacle,omitempty"`
+	HitPoint    *Vector3 `json:"hitPoint,omitempty"`
+	HitDistance *float64 `json:"hitDistance,omitempty"`
+	Amount      *int     `json:"amount,omitempty"`    // For healing amount
+	NewHealth   *int     `json:"newHealth,omitempty"` // New health after healing
+	Damage      *int     `json:"damage,omitempty"`    // Damage from weapon used
+	Stance      Stance   `json:"stance,omitempty"`    // Stance to take
+}
+
+// GameEvent is a message produced by the game state for the server to deliver
+type GameEvent struct {
+	Type MessageType
+	// PlayerID is the recipient, empty to broadcast to everyone
+	PlayerID string
+	Payload  interface{}
+}
+
+// AchievementPayload announces an achievement earned by a player
+type AchievementPayload struct {
+	PlayerID    string `json:"playerId"`
+	PlayerName  string `json:"playerName"`
+	Achievement string `json:"achievement"`
+	Streak      int    `json:"streak,omitempty"`
+	VictimID    string `json:"victimId,omitempty"`
+}
+
+// PositionCorrectionPayload tells a client its authoritative position after the server adjusted a move
+type PositionCorrectionPayload struct {
+	Position Vector3 `json:"position"`
+}
+
+// StartFailedPayload tells players why a match they were waiting for didn't start
+type StartFailedPayload struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Current  int    `json:"current"`
+	Required int    `json:"required"`
+}
+
+// MatchResetPayload announces a fresh match started with the connected players
+type MatchResetPayload struct {
+	MatchID string `json:"matchId"`
+	Seed    int64  `json:"seed"`
+}
+
+// SupplyDropPayload announces a supply drop before it lands
+type SupplyDropPayload struct {
+	ID       string  `json:"id"`
+	Position Vector3 `json:"position"`
+	WeaponID string  `json:"weaponId"`
+	Shield   int     `json:"shield,omitempty"`
+	// LandsIn is the number of seconds until the drop lands and can be picked up
+	LandsIn float64 `json:"landsIn"`
 }
 
 // GameMessage represents a message sent between client and server
@@ -77,6 +471,49 @@ type ErrorMessage struct {
 	Details interface{} `json:"details,omitempty"`
 }
 
+// HandshakePayload is the first message a client receives, with its player ID and the server's protocol
+type HandshakePayload struct {
+	ID                 string   `json:"id"`
+	ProtocolVersion    int      `json:"protocolVersion"`
+	MinProtocolVersion int      `json:"minProtocolVersion"`
+	Features           []string `json:"features"`
+	// ReconnectToken lets the client reclaim its player after a dropped connection in ranked matches
+	ReconnectToken string `json:"reconnectToken,omitempty"`
+}
+
+// LatencyPayload tells a client the round trip time the server measured with its last ping
+type LatencyPayload struct {
+	RTTMs float64 `json:"rttMs"`
+}
+
+// WhoAmIPayload answers a client's whoAmI with its current identity, so it can resync after missing the handshake
+type WhoAmIPayload struct {
+	PlayerID    string `json:"playerId"`
+	DisplayName string `json:"displayName"`
+	Team        string `json:"team,omitempty"`
+	IsAlive     bool   `json:"isAlive"`
+}
+
+// MOTDPayload carries the server's message of the day
+type MOTDPayload struct {
+	Message string `json:"message"`
+}
+
+// SupportedProtocolVersion reports whether the server can talk to a client speaking version
+func SupportedProtocolVersion(version int) bool {
+	return version >= MinProtocolVersion && version <= ProtocolVersion
+}
+
+// MaxChatLength is the longest chat message in characters a player may send
+const MaxChatLength = 200
+
+// ChatPayload is a chat message sent by a player
+type ChatPayload struct {
+	PlayerID   string `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Text       string `json:"text"`
+}
+
 // SetNamePayload represents a player setting their display name
 type SetNamePayload struct {
 	DisplayName string `json:"displayName"`
@@ -88,8 +525,8 @@ func ValidateMessage(msg *GameMessage) error {
 		return ErrInvalidMessageType
 	}
 
-	if msg.Timestamp.IsZero() {
-		return ErrInvalidTimestamp
+	if err := ValidateTimestamp(msg.Timestamp, time.Now(), 0); err != nil {
+		return err
 	}
 
 	switch msg.Type {
@@ -106,6 +543,27 @@ func ValidateMessage(msg *GameMessage) error {
 	return nil
 }
 
+// ValidateTimestamp validates that a message timestamp is set and within maxSkew of now.
+// A maxSkew of zero only rejects missing timestamps.
+func ValidateTimestamp(timestamp, now time.Time, maxSkew time.Duration) error {
+	if timestamp.IsZero() {
+		return ErrInvalidTimestamp
+	}
+
+	if maxSkew <= 0 {
+		return nil
+	}
+
+	if now.Sub(timestamp) > maxSkew {
+		return ErrStaleTimestamp
+	}
+	if timestamp.Sub(now) > maxSkew {
+		return ErrFutureTimestamp
+	}
+
+	return nil
+}
+
 // validatePlayerAction validates a player action
 func validatePlayerAction(action *PlayerAction) error {
 	if action.Type == "" {
@@ -113,7 +571,7 @@ func validatePlayerAction(action *PlayerAction) error {
 	}
 
 	switch action.Type {
-	case "move", "jump", "shoot", "reload", "heal":
+	case "move", "jump", "shoot", "reload", "heal", "switchWeapon", "spectateTarget", "setStance":
 		// Valid action types
 	default:
 		return ErrInvalidActionType